@@ -54,9 +54,10 @@ echo Built: claudex-windows %%VERSION%%
 exit /b %ERRORLEVEL%
 
 :build_hooks
-echo Building claudex-windows-hooks...
+call :version
+echo Building claudex-windows-hooks %%VERSION%%...
 pushd %SRC_DIR%
-go build -o ..\bin\claudex-windows-hooks.exe .\cmd\claudex-hooks
+go build -ldflags "-X main.Version=%VERSION%" -o ..\bin\claudex-windows-hooks.exe .\cmd\claudex-hooks
 popd
 echo Built: bin\claudex-windows-hooks.exe
 exit /b %ERRORLEVEL%
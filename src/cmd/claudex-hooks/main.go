@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"claudex/internal/doc"
+	"claudex/internal/doc/rangeupdater"
 	"claudex/internal/hooks/notification"
 	"claudex/internal/hooks/posttooluse"
 	"claudex/internal/hooks/pretooluse"
@@ -13,21 +15,38 @@ import (
 	"claudex/internal/hooks/shared"
 	"claudex/internal/hooks/subagent"
 	"claudex/internal/notify"
+	"claudex/internal/services/clock"
 	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/usage"
+	"claudex/internal/services/uuid"
+	"claudex/internal/usecases/createindex"
 
 	"github.com/spf13/afero"
 )
 
+// Version is set at build time via -ldflags; `claudex doctor` compares it
+// with the claudex version
+var Version = "dev"
+
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Fprintf(os.Stderr, "Usage: claudex-hooks <command>\n")
-		fmt.Fprintf(os.Stderr, "Commands: notification, pre-tool-use, post-tool-use, auto-doc, session-end, subagent-stop\n")
+		fmt.Fprintf(os.Stderr, "Commands: notification, pre-tool-use, post-tool-use, auto-doc, session-end, subagent-stop, job-worker, version\n")
 		os.Exit(1)
 	}
 
 	cmd := os.Args[1]
 
+	if cmd == "version" || cmd == "--version" {
+		fmt.Printf("claudex-hooks %s\n", Version)
+		return
+	}
+
 	// Create shared dependencies
 	fs := afero.NewOsFs()
 	environ := env.New()
@@ -57,6 +76,8 @@ func main() {
 		err = handleSessionEnd(fs, cmdr, environ, logger, parser, builder)
 	case "subagent-stop":
 		err = handleSubagentStop(fs, cmdr, environ, logger, parser, builder)
+	case jobs.WorkerCommand:
+		err = handleJobWorker(fs, cmdr, environ, os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
 		os.Exit(1)
@@ -127,8 +148,8 @@ func handleAutoDoc(fs afero.Fs, cmdr commander.Commander, environ env.Environmen
 		return err
 	}
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater backed by the durable job queue
+	updater := newBackgroundUpdater(fs, cmdr, environ, input.CWD)
 
 	// Read frequency from environment (default 5)
 	frequency := 5
@@ -154,8 +175,8 @@ func handleSessionEnd(fs afero.Fs, cmdr commander.Commander, environ env.Environ
 		return err
 	}
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater backed by the durable job queue
+	updater := newBackgroundUpdater(fs, cmdr, environ, input.CWD)
 
 	handler := sessionend.NewHandler(fs, environ, updater, logger)
 	return handler.Handle(input)
@@ -175,8 +196,8 @@ func handleSubagentStop(fs afero.Fs, cmdr commander.Commander, environ env.Envir
 	deps := &commanderAdapter{cmdr: cmdr}
 	notifier := notify.New(notifCfg, deps)
 
-	// Create documentation updater
-	updater := doc.NewUpdater(fs, cmdr, environ)
+	// Create documentation updater backed by the durable job queue
+	updater := newBackgroundUpdater(fs, cmdr, environ, input.CWD)
 
 	handler := subagent.NewHandler(fs, environ, updater, notifier, logger)
 	output, err := handler.Handle(input)
@@ -187,6 +208,34 @@ func handleSubagentStop(fs afero.Fs, cmdr commander.Commander, environ env.Envir
 	return builder.BuildCustom(*output)
 }
 
+// handleJobWorker drains the background job queue in the given directory.
+// It is spawned detached by RunBackground and outlives the hook that queued the job.
+func handleJobWorker(fs afero.Fs, cmdr commander.Commander, environ env.Environment, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: claudex-hooks %s <jobs-dir>", jobs.WorkerCommand)
+	}
+
+	queue := jobs.New(fs, args[0], clock.New(), uuid.New())
+	updater := doc.NewUpdater(fs, cmdr, environ, queue, nil)
+
+	worker := jobs.NewWorker(fs, queue, lock.New(fs), clock.New())
+	worker.Handle(jobs.KindSessionOverview, updater.HandleJob)
+	ledger := usage.New(fs, usage.PathForJobs(args[0]))
+	backend := usage.NewRecorder(llm.FromEnv(fs, cmdr, environ), ledger, clock.New())
+	worker.Handle(jobs.KindIndexUpdate, rangeupdater.IndexJobHandler(backend))
+	worker.Handle(jobs.KindCreateIndex, createindex.JobHandler(backend))
+
+	return worker.Run()
+}
+
+// newBackgroundUpdater creates a doc updater that queues work under the project's .claudex/jobs,
+// resolving the project root from the cwd Claude reports
+func newBackgroundUpdater(fs afero.Fs, cmdr commander.Commander, environ env.Environment, cwd string) *doc.Updater {
+	queue := jobs.New(fs, filepath.Join(paths.FindProjectRoot(fs, cwd), paths.JobsDir), clock.New(), uuid.New())
+	spawner := jobs.NewSpawner(jobs.HooksBinary(environ))
+	return doc.NewUpdater(fs, cmdr, environ, queue, spawner)
+}
+
 // commanderAdapter adapts commander.Commander to notify.Dependencies
 type commanderAdapter struct {
 	cmdr commander.Commander
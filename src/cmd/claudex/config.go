@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/config"
+	"claudex/internal/services/paths"
+)
+
+const configUsage = `Usage: claudex config <command> [options]
+
+Inspect and edit the layered configuration. Each layer overrides the ones
+before it:
+
+  1. built-in defaults
+  2. user      ~/.config/claudex/config.toml ($XDG_CONFIG_HOME honored),
+               shared by every project
+  3. project   .claudex/config.toml
+  4. env       CLAUDEX_* environment variables
+  5. flag      command line flags (--doc, --no-overwrite)
+
+Arguments after -- are passed to Claude on top of [launch] and stored with
+the session; see claudex session --help.
+
+Commands:
+  show                      Print the effective configuration as TOML
+  explain [--json]          Print every key with its effective value and the
+                            layer that supplied it
+  get <key>                 Print the effective value of a key
+  set [--user] <key> <value>...
+                            Write a key to the project config, or with --user
+                            to the user config. List keys (doc) take one
+                            value per item.
+  path [--user]             Print the path of the project (or user) config file
+  validate [--json]         Check every layer for unknown keys, mistyped or
+                            out-of-range values and missing doc paths; exits
+                            with status 1 when a problem is found
+
+Keys are dotted TOML paths: no_overwrite, features.autodoc_frequency,
+llm.backend, models.index_update, models.agents.<agent-name>,
+activation.template, launch.model, launch.add_dirs.
+`
+
+// runConfig implements `claudex config`
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Print(configUsage)
+		return nil
+	}
+
+	deps := app.NewDependencies()
+	projectDir, err := paths.ProjectRoot(deps.FS)
+	if err != nil {
+		return err
+	}
+	sources := config.Sources{
+		UserPath:    config.UserPath(deps.Env),
+		ProjectPath: filepath.Join(projectDir, paths.ConfigFile),
+		Env:         deps.Env,
+	}
+	load := func() (*config.Layered, error) {
+		return config.LoadLayered(deps.FS, sources)
+	}
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "show":
+		layered, err := load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		return layered.Config.Write(os.Stdout)
+
+	case "explain":
+		fs := flag.NewFlagSet("config explain", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print the values as JSON")
+		if _, err := parseArgs(fs, rest); err != nil {
+			return err
+		}
+		layered, err := load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if *asJSON {
+			return config.WriteValuesJSON(os.Stdout, layered.Values())
+		}
+		return config.WriteValues(os.Stdout, layered.Values())
+
+	case "get":
+		if len(rest) != 1 {
+			fmt.Fprint(os.Stderr, configUsage)
+			return fmt.Errorf("config get takes a key")
+		}
+		layered, err := load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		v, err := layered.Get(rest[0])
+		if err != nil {
+			return err
+		}
+		switch value := v.Value.(type) {
+		case []string:
+			for _, item := range value {
+				fmt.Println(item)
+			}
+		default:
+			fmt.Println(value)
+		}
+		return nil
+
+	case "set":
+		fs := flag.NewFlagSet("config set", flag.ContinueOnError)
+		user := fs.Bool("user", false, "write to the user config instead of the project config")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) < 2 {
+			fmt.Fprint(os.Stderr, configUsage)
+			return fmt.Errorf("config set takes a key and a value")
+		}
+		key := positional[0]
+		value, err := config.Parse(key, positional[1:]...)
+		if err != nil {
+			return err
+		}
+		target := sources.ProjectPath
+		if *user {
+			target = sources.UserPath
+		}
+		if err := config.SetValue(deps.FS, target, key, value); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Set %s = %s in %s\n", key, config.FormatValue(value), target)
+
+		// A higher layer still wins; say so rather than leave the user guessing
+		if layered, err := load(); err == nil {
+			if v, err := layered.Get(key); err == nil && v.Origin.Source != target {
+				fmt.Printf("⚠ %s is overridden by %s\n", key, v.Origin)
+			}
+		}
+		return nil
+
+	case "validate":
+		fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print the problems as JSON")
+		if _, err := parseArgs(fs, rest); err != nil {
+			return err
+		}
+		layered, err := load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		problems := layered.Validate(deps.FS, projectDir)
+		if *asJSON {
+			if err := config.WriteProblemsJSON(os.Stdout, problems); err != nil {
+				return err
+			}
+		} else if len(problems) == 0 {
+			fmt.Println("✓ Configuration is valid")
+		} else {
+			config.WriteProblems(os.Stdout, problems)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%d config problem(s) found", len(problems))
+		}
+		return nil
+
+	case "path":
+		fs := flag.NewFlagSet("config path", flag.ContinueOnError)
+		user := fs.Bool("user", false, "print the user config path")
+		if _, err := parseArgs(fs, rest); err != nil {
+			return err
+		}
+		if *user {
+			fmt.Println(sources.UserPath)
+		} else {
+			fmt.Println(sources.ProjectPath)
+		}
+		return nil
+
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return fmt.Errorf("unknown config command: %s", sub)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudex/internal/services/app"
+)
+
+const docsUsage = `Usage: claudex docs <command>
+
+Generate and update index.md documentation with Claude.
+
+Commands:
+  update                  Update index.md files affected by recent commits
+                          (same as --update-docs)
+  create-index <dir>      Create an index.md for a directory
+                          (same as --create-index <dir>)
+`
+
+// runDocs implements `claudex docs`
+func runDocs(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Print(docsUsage)
+		return nil
+	}
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "update":
+		if len(rest) != 0 {
+			return fmt.Errorf("usage: claudex docs update")
+		}
+		return withApp(func(a *app.App) error {
+			return a.UpdateDocs()
+		})
+
+	case "create-index":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: claudex docs create-index <dir>")
+		}
+		// Resolve before Init moves to the project root
+		dir, err := filepath.Abs(rest[0])
+		if err != nil {
+			return fmt.Errorf("invalid directory %s: %w", rest[0], err)
+		}
+		return withApp(func(a *app.App) error {
+			return a.CreateIndex(dir)
+		})
+
+	default:
+		fmt.Fprint(os.Stderr, docsUsage)
+		return fmt.Errorf("unknown docs command: %s", sub)
+	}
+}
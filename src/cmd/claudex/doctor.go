@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/paths"
+	doctoruc "claudex/internal/usecases/doctor"
+)
+
+const doctorUsage = `Usage: claudex doctor [--fix] [--json]
+
+Check the Claudex installation and this project's setup: claude and
+claudex-hooks on PATH with matching versions, the hook commands in
+.claude/settings.local.json, the hook proxies, stale doc_update.lock files,
+the session documenter prompt template, ~/.claude.json and the post-commit
+hook. Every problem comes with a fix.
+
+Options:
+  --fix    Repair the safe problems: reinstall missing hook scripts, mark them
+           executable, replace outdated proxies, remove stale locks and
+           install the post-commit hook
+  --json   Print the checks as JSON
+
+Exits with status 1 when a check fails.
+`
+
+// runDoctor implements `claudex doctor`
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, doctorUsage) }
+	fix := fs.Bool("fix", false, "repair the problems that are safe to fix")
+	asJSON := fs.Bool("json", false, "print the checks as JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument: %s", positional[0])
+	}
+
+	deps := app.NewDependencies()
+	projectDir, err := paths.ProjectRoot(deps.FS)
+	if err != nil {
+		return err
+	}
+	uc := doctoruc.New(deps.FS, deps.Cmd, deps.Env, projectDir, Version)
+
+	checks := uc.Run()
+	if *fix {
+		repaired := false
+		for _, c := range checks {
+			if c.Status == doctoruc.StatusOK || !c.Fixable {
+				continue
+			}
+			if err := uc.Repair(c); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ Could not fix %s: %v\n", c.Name, err)
+				continue
+			}
+			fmt.Printf("✓ Fixed %s\n", c.Name)
+			repaired = true
+		}
+		if repaired {
+			fmt.Println()
+			checks = uc.Run()
+		}
+	}
+
+	if *asJSON {
+		err = doctoruc.WriteJSON(os.Stdout, checks)
+	} else {
+		err = doctoruc.Write(os.Stdout, checks)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed := doctoruc.Failed(checks); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/config"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/paths"
+	managesessionsuc "claudex/internal/usecases/managesessions"
+)
+
+const gcUsage = `Usage: claudex gc [--dry-run] [--json]
+
+Archive the sessions and log files beyond the [retention] limits in the
+config. Nothing is deleted: sessions move to .claudex/archive/ (restore them
+with claudex session unarchive) and log files to .claudex/logs/archive/;
+claudex session purge --all deletes both for good.
+
+Options:
+  --dry-run   List what would be archived without moving anything
+  --json      Print the report as JSON
+
+Sessions unused for more than retention.max_age_days, or beyond the
+retention.max_count most recently used, are archived. Pinned sessions
+(claudex session pin <name>) are always kept, and so are tagged sessions
+unless retention.keep_tagged is false; neither counts towards max_count.
+Sessions with unfinished background jobs are kept until the jobs finish.
+Log files of no active session get the same age and count limits.
+
+  [retention]
+  max_age_days = 30
+  max_count = 50
+  keep_tagged = true
+  gc_on_startup = true   # also run at most once a day when claudex starts
+`
+
+// runGC implements `claudex gc`
+func runGC(args []string) error {
+	fs := flag.NewFlagSet("gc", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, gcUsage) }
+	dryRun := fs.Bool("dry-run", false, "list what would be archived without moving anything")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if len(positional) > 0 {
+		fmt.Fprint(os.Stderr, gcUsage)
+		return fmt.Errorf("unexpected argument: %s", positional[0])
+	}
+
+	deps := app.NewDependencies()
+	projectDir, err := paths.ProjectRoot(deps.FS)
+	if err != nil {
+		return err
+	}
+	layered, err := config.LoadLayered(deps.FS, config.Sources{
+		UserPath:    config.UserPath(deps.Env),
+		ProjectPath: filepath.Join(projectDir, paths.ConfigFile),
+		Env:         deps.Env,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	policy := app.RetentionPolicy(layered.Config.Retention)
+	if !policy.Enabled() {
+		return fmt.Errorf("no retention limits are set; set retention.max_age_days or retention.max_count (see: claudex gc --help)")
+	}
+
+	queue := jobs.New(deps.FS, filepath.Join(projectDir, paths.JobsDir), deps.Clock, deps.UUID)
+	manage := managesessionsuc.New(deps.FS, deps.Clock, queue,
+		filepath.Join(projectDir, paths.SessionsDir), filepath.Join(projectDir, paths.ArchiveDir), filepath.Join(projectDir, paths.LogsDir))
+	report, err := manage.GC(policy, managesessionsuc.GCOptions{DryRun: *dryRun})
+	if report != nil {
+		if werr := managesessionsuc.WriteGC(os.Stdout, report, *asJSON); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/paths"
+	managejobsuc "claudex/internal/usecases/managejobs"
+)
+
+// defaultJobsListLimit is how many jobs `claudex jobs list` shows without --all
+const defaultJobsListLimit = 20
+
+const jobsUsage = `Usage: claudex jobs <command> [options]
+
+Inspect and control background Claude invocations (doc and index updates).
+
+Commands:
+  list [--all]             List recent jobs, newest first
+  show <id>                Show a job's full record
+  logs [--follow] <id>     Print a job's log output
+  cancel <id>              Cancel a queued or running job
+  retry <id>               Re-queue a failed or cancelled job
+
+cancel stops a job's background worker. Jobs recorded by a foreground command
+(create-index) run inside that command; stop it with Ctrl+C instead.
+
+Job IDs may be abbreviated to any unique prefix of the ID or its last segment.
+`
+
+// runJobs implements `claudex jobs`
+func runJobs(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Print(jobsUsage)
+		return nil
+	}
+
+	deps := app.NewDependencies()
+	projectDir, err := paths.ProjectRoot(deps.FS)
+	if err != nil {
+		return err
+	}
+	queue := jobs.New(deps.FS, filepath.Join(projectDir, paths.JobsDir), deps.Clock, deps.UUID)
+	spawner := jobs.NewSpawner(jobs.HooksBinary(deps.Env))
+	uc := managejobsuc.New(deps.FS, queue, spawner, deps.Clock)
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "list", "ls":
+		fs := flag.NewFlagSet("jobs list", flag.ContinueOnError)
+		all := fs.Bool("all", false, "list every recorded job")
+		if err := fs.Parse(rest); err != nil {
+			return err
+		}
+		limit := defaultJobsListLimit
+		if *all {
+			limit = 0
+		}
+		return uc.List(os.Stdout, limit)
+
+	case "show":
+		id, err := jobID(sub, rest)
+		if err != nil {
+			return err
+		}
+		return uc.Show(os.Stdout, id)
+
+	case "logs":
+		fs := flag.NewFlagSet("jobs logs", flag.ContinueOnError)
+		follow := fs.Bool("follow", false, "keep streaming output until the job finishes")
+		fs.BoolVar(follow, "f", false, "shorthand for --follow")
+		if err := fs.Parse(rest); err != nil {
+			return err
+		}
+		id, err := jobID(sub, fs.Args())
+		if err != nil {
+			return err
+		}
+		return uc.Logs(os.Stdout, id, *follow)
+
+	case "cancel":
+		id, err := jobID(sub, rest)
+		if err != nil {
+			return err
+		}
+		job, err := uc.Cancel(id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Cancelled job %s\n", job.ID)
+		return nil
+
+	case "retry":
+		id, err := jobID(sub, rest)
+		if err != nil {
+			return err
+		}
+		job, err := uc.Retry(id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Re-queued job %s\n", job.ID)
+		return nil
+
+	default:
+		fmt.Fprint(os.Stderr, jobsUsage)
+		return fmt.Errorf("unknown jobs command: %s", sub)
+	}
+}
+
+// jobID extracts the single job ID argument of a jobs subcommand
+func jobID(sub string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: claudex jobs %s <id>", sub)
+	}
+	return args[0], nil
+}
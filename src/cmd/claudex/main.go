@@ -7,12 +7,13 @@ import (
 	"strings"
 
 	"claudex/internal/services/app"
+	"claudex/internal/services/terminal"
 )
 
 // Version is set at build time via -ldflags
 var Version = "dev"
 
-// stringSlice implements flag.Value to allow multiple --doc flags
+// stringSlice implements flag.Value to allow repeated flags such as --doc and --tag
 type stringSlice []string
 
 func (s *stringSlice) String() string     { return strings.Join(*s, ":") }
@@ -23,14 +24,60 @@ var showVersion = flag.Bool("version", false, "print version and exit")
 var updateDocs = flag.Bool("update-docs", false, "update index.md files based on git changes")
 var setupMCP = flag.Bool("setup-mcp", false, "configure recommended MCP servers (sequential-thinking, context7)")
 var createIndex = flag.String("create-index", "", "create index.md file at specified directory path")
+var noPrompts bool
 var docPaths stringSlice
 
+// claudeArgs holds the arguments after --, passed through to Claude
+var claudeArgs []string
+
 func init() {
 	flag.Var(&docPaths, "doc", "documentation path for agent context (can be specified multiple times)")
+	flag.BoolVar(&noPrompts, "no-prompts", false, "never prompt: skip optional setup questions, require an explicit session; deletions still need --force")
+	flag.BoolVar(&noPrompts, "yes", false, "alias for --no-prompts")
+}
+
+// subcommands maps a leading positional argument to its handler.
+// Anything else falls through to the interactive launcher and its flags.
+// The --update-docs, --create-index and --setup-mcp flags remain as aliases of
+// `docs update`, `docs create-index` and `mcp setup`.
+var subcommands = map[string]func(args []string) error{
+	"config":  runConfig,
+	"docs":    runDocs,
+	"doctor":  runDoctor,
+	"fork":    sessionShortcut("fork"),
+	"fresh":   sessionShortcut("fresh"),
+	"gc":      runGC,
+	"jobs":    runJobs,
+	"mcp":     runMCP,
+	"resume":  sessionShortcut("resume"),
+	"search":  sessionShortcut("search"),
+	"session": runSession,
+	"usage":   runUsage,
 }
 
 func main() {
-	application := app.New(Version, showVersion, noOverwrite, updateDocs, setupMCP, createIndex, docPaths)
+	// Split off the Claude arguments before any flag set sees them, so
+	// subcommands parse only their own flags
+	var args []string
+	args, claudeArgs = splitClaudeArgs(os.Args[1:])
+	flag.CommandLine.Parse(args)
+
+	// Exported so subcommands and the processes they start skip prompts too
+	if noPrompts {
+		os.Setenv(terminal.EnvNoPrompts, "1")
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		if run, ok := subcommands[args[0]]; ok {
+			if err := run(args[1:]); err != nil && err != flag.ErrHelp {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	application := newApp()
 
 	if err := application.Init(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -43,3 +90,31 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitClaudeArgs splits args at the first --
+func splitClaudeArgs(args []string) (own, claude []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// newApp creates the application from the global flags
+func newApp() *app.App {
+	application := app.New(Version, showVersion, noOverwrite, updateDocs, setupMCP, createIndex, docPaths)
+	application.SetClaudeArgs(claudeArgs)
+	return application
+}
+
+// withApp initializes the application (migration, config, .claude setup,
+// logging) for subcommands that create or launch sessions, then runs fn
+func withApp(fn func(a *app.App) error) error {
+	application := newApp()
+	if err := application.Init(); err != nil {
+		return err
+	}
+	defer application.Close()
+	return fn(application)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/mcpconfig"
+	setupmcpuc "claudex/internal/usecases/setupmcp"
+)
+
+const mcpUsage = `Usage: claudex mcp <command> [options]
+
+Manage the recommended MCP servers (sequential-thinking, context7) in
+~/.claude.json.
+
+Commands:
+  status                  Show whether the recommended MCPs are configured
+  setup [--token <key>]   Configure the recommended MCPs without prompting;
+                          --token sets the optional Context7 API key
+`
+
+// runMCP implements `claudex mcp`
+func runMCP(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Print(mcpUsage)
+		return nil
+	}
+
+	deps := app.NewDependencies()
+	uc := setupmcpuc.New(deps.FS)
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "status":
+		switch uc.ShouldPrompt() {
+		case setupmcpuc.ResultNodeMissing:
+			fmt.Println("Node.js (npx) not found; the recommended MCPs need it to run.")
+		case setupmcpuc.ResultAlreadyConfigured:
+			fmt.Println("✓ Recommended MCPs are configured.")
+		default:
+			fmt.Println("○ Recommended MCPs are not configured. Run: claudex mcp setup")
+		}
+		return nil
+
+	case "setup":
+		fs := flag.NewFlagSet("mcp setup", flag.ContinueOnError)
+		token := fs.String("token", "", "Context7 API key (optional)")
+		if _, err := parseArgs(fs, rest); err != nil {
+			return err
+		}
+		if err := uc.Install(*token); err != nil {
+			return fmt.Errorf("could not configure MCPs: %w", err)
+		}
+		fmt.Println("✓ MCP configuration added to ~/.claude.json")
+		if *token == "" {
+			fmt.Println("  Note: Context7 running in rate-limited mode (60 req/hour)")
+			fmt.Printf("  Generate a token at %s and rerun with --token\n", mcpconfig.Context7TokenURL)
+		}
+		return nil
+
+	default:
+		fmt.Fprint(os.Stderr, mcpUsage)
+		return fmt.Errorf("unknown mcp command: %s", sub)
+	}
+}
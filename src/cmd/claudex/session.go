@@ -0,0 +1,567 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/terminal"
+	managesessionsuc "claudex/internal/usecases/managesessions"
+	sessionbundleuc "claudex/internal/usecases/sessionbundle"
+	sessionstatsuc "claudex/internal/usecases/sessionstats"
+)
+
+const sessionUsage = `Usage: claudex session <command> [options]
+
+Create, launch and manage Claudex sessions without the interactive selector.
+
+Commands:
+  list [--json] [--archived]            List sessions, most recently used first
+  show [--json] <name>                  Show a session's metadata and files
+  tree [--json] [<name>]                Show which sessions were forked from which,
+                                        or only the tree holding <name>
+  new [--no-launch] [--agent <name>] [--template <name>] [--tag <tag>]...
+      <description>                     Create a session and launch Claude in it,
+                                        starting with the given agent (team-lead,
+                                        architect, researcher, or a custom agent
+                                        in .claude/agents) and activation template
+  tag [--rm] <name> [<tag>...]          Add tags to a session, remove them with
+                                        --rm, or print them when none are given
+  search [--json] <query>               Search every session's description, tags
+                                        and markdown documents, best match first
+  pin <name> / unpin <name>             Keep a session out of claudex gc, or
+                                        let the retention limits apply again
+  templates                             List the activation templates
+  resume <name>                         Resume a session's Claude conversation
+  fork [--no-launch] -m <desc> <name>   Copy a session into a new one and launch it
+  fresh [--no-launch] <name>            Restart a session with a new conversation,
+                                        keeping its files
+  rename <name> <new-name>              Change a session's name, keeping its
+                                        Claude session ID; its log file, queued
+                                        jobs and other sessions' parent and
+                                        lineage entries follow. Refused while
+                                        Claude runs in the session: its hooks
+                                        keep the old path until it exits
+  rm [--force] <name>                   Delete a session (--yes is an alias
+                                        of --force)
+  archive <name>                        Move a session to .claudex/archive/
+  unarchive <name>                      Restore an archived session
+  purge [--force] --all | <name>        Permanently delete archived sessions
+                                        (--all also deletes the archived logs)
+  export [--transcript] [--redact-home] [-o <file>] <name>
+                                        Pack a session into a tar.gz bundle
+                                        (default: <name>.tar.gz) to hand it over
+  import <bundle>                       Add a session from a bundle under a new
+                                        Claude session ID
+  stats [--json] <name>                 Token usage, tool calls and estimated cost
+                                        of a session, with per-model, per-subagent
+                                        and hourly breakdowns
+
+rm and purge ask before deleting. Without a terminal, or with --no-prompts or
+CLAUDEX_NO_PROMPTS=1, they fail unless --force is given to the command itself.
+
+Sessions may be named by their full name, a prefix, a substring or any
+characters in order ("arf" for auth-refactor-...); an ambiguous name lists the
+matching sessions. With --no-launch, the new session's name is printed instead
+of starting Claude. The entry agent and template are remembered: fork and
+fresh start the same agent with the same template again. Forks record their
+parent and fresh restarts the session they replaced, so the tree survives
+the original's deletion.
+
+fresh archives the original session instead of deleting it, keeping its
+files and the link to its Claude transcript; set sessions.archive_on_fresh
+to false to delete it. unarchive and purge take archived session names.
+claudex gc archives the sessions beyond the [retention] limits in the config;
+see claudex gc --help.
+
+export bundles the session folder with a manifest of SHA-256 checksums.
+--transcript adds the Claude transcripts of the session and its subagents, so
+the importer can resume the conversation; --redact-home replaces your home
+directory with ~ in every text file. import checks the bundle against its
+manifest and rewrites the exporter's project path, session name and Claude
+session ID to this project's.
+
+Tags are words of letters, digits, '.', '_' and '-' (e.g. auth, bug-123).
+search ranks sessions by where its words appear (description, tags, name,
+then documents) and accepts tag:<tag> and status:active|archived qualifiers:
+  claudex search token refresh tag:auth status:active
+The same qualifiers work in the interactive selector's filter (press /).
+
+Activation templates are Go text/template files named <name>.tmpl in
+.claudex/templates/ or ~/.config/claudex/templates/ (project first). They
+render the prompt that starts new, forked and fresh sessions with {{.Agent}},
+{{.SessionName}}, {{.SessionPath}}, {{.Mode}}, {{.Parent}}, {{.Description}},
+{{.Docs}} and {{.Branch}}. Without --template, activation.template in the
+config applies; "default" is the built-in prompt.
+
+Arguments after -- go to Claude, e.g. --model, --permission-mode, --add-dir,
+--mcp-config or --allowedTools:
+  claudex session new "Spike caching" -- --model opus --add-dir ../api
+Relative --add-dir and --mcp-config paths are resolved against the current
+directory. They are stored with the session: resume, fork and fresh reapply
+them until other arguments are given after --. The [launch] config section
+adds options to every session.
+
+resume, fork, fresh and search are also available as top-level commands:
+  claudex resume <name>, claudex fork <name> -m <desc>, claudex fresh <name>,
+  claudex search <query>
+`
+
+// runSession implements `claudex session`
+func runSession(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Print(sessionUsage)
+		return nil
+	}
+
+	deps := app.NewDependencies()
+	projectDir, err := paths.ProjectRoot(deps.FS)
+	if err != nil {
+		return err
+	}
+	sessionsDir := filepath.Join(projectDir, paths.SessionsDir)
+	archiveDir := filepath.Join(projectDir, paths.ArchiveDir)
+	queue := jobs.New(deps.FS, filepath.Join(projectDir, paths.JobsDir), deps.Clock, deps.UUID)
+	manage := managesessionsuc.New(deps.FS, deps.Clock, queue, sessionsDir, archiveDir, filepath.Join(projectDir, paths.LogsDir))
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "list", "ls":
+		fs := flag.NewFlagSet("session list", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print sessions as JSON")
+		archived := fs.Bool("archived", false, "list the archived sessions instead")
+		if _, err := parseArgs(fs, rest); err != nil {
+			return err
+		}
+		if *archived {
+			return manage.ListArchived(os.Stdout, *asJSON)
+		}
+		return manage.List(os.Stdout, *asJSON)
+
+	case "show":
+		fs := flag.NewFlagSet("session show", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print the session as JSON")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session show [--json] <name>")
+		}
+		return manage.Show(os.Stdout, positional[0], *asJSON)
+
+	case "tree":
+		fs := flag.NewFlagSet("session tree", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print the genealogy as nested JSON")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) > 1 {
+			return fmt.Errorf("usage: claudex session tree [--json] [<name>]")
+		}
+		name := ""
+		if len(positional) == 1 {
+			name = positional[0]
+		}
+		return manage.WriteTree(os.Stdout, name, *asJSON)
+
+	case "new":
+		fs := flag.NewFlagSet("session new", flag.ContinueOnError)
+		noLaunch := fs.Bool("no-launch", false, "create the session without starting Claude")
+		agent := fs.String("agent", "", "agent the session starts with (default: team-lead)")
+		template := fs.String("template", "", "activation template (default: activation.template from the config)")
+		var tags stringSlice
+		fs.Var(&tags, "tag", "tag the session (can be specified multiple times)")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		description := strings.TrimSpace(strings.Join(positional, " "))
+		if description == "" {
+			return fmt.Errorf("usage: claudex session new [--no-launch] [--agent <name>] [--template <name>] [--tag <tag>]... <description>")
+		}
+		return withApp(func(a *app.App) error {
+			si, err := a.NewSession(description, *agent, *template, tags)
+			if err != nil {
+				return err
+			}
+			return launchOrPrint(a, si, *noLaunch)
+		})
+
+	case "templates":
+		return withApp(func(a *app.App) error {
+			for _, name := range a.Templates() {
+				fmt.Println(name)
+			}
+			return nil
+		})
+
+	case "resume":
+		fs := flag.NewFlagSet("session resume", flag.ContinueOnError)
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session resume <name>")
+		}
+		sessionName, err := manage.Find(positional[0])
+		if err != nil {
+			return err
+		}
+		return withApp(func(a *app.App) error {
+			si, err := a.ResumeSession(sessionName)
+			if err != nil {
+				return err
+			}
+			return a.Launch(si)
+		})
+
+	case "fork":
+		fs := flag.NewFlagSet("session fork", flag.ContinueOnError)
+		noLaunch := fs.Bool("no-launch", false, "create the fork without starting Claude")
+		message := fs.String("m", "", "description of the forked session")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 || strings.TrimSpace(*message) == "" {
+			return fmt.Errorf("usage: claudex session fork [--no-launch] -m <description> <name>")
+		}
+		sessionName, err := manage.Find(positional[0])
+		if err != nil {
+			return err
+		}
+		return withApp(func(a *app.App) error {
+			si, err := a.ForkSession(sessionName, *message)
+			if err != nil {
+				return err
+			}
+			return launchOrPrint(a, si, *noLaunch)
+		})
+
+	case "fresh":
+		fs := flag.NewFlagSet("session fresh", flag.ContinueOnError)
+		noLaunch := fs.Bool("no-launch", false, "prepare the session without starting Claude")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session fresh [--no-launch] <name>")
+		}
+		sessionName, err := manage.Find(positional[0])
+		if err != nil {
+			return err
+		}
+		return withApp(func(a *app.App) error {
+			si, err := a.FreshSession(sessionName)
+			if err != nil {
+				return err
+			}
+			return launchOrPrint(a, si, *noLaunch)
+		})
+
+	case "rename", "mv":
+		if len(rest) != 2 {
+			return fmt.Errorf("usage: claudex session rename <name> <new-name>")
+		}
+		newName, err := manage.Rename(rest[0], rest[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Renamed to %s\n", newName)
+		return nil
+
+	case "tag":
+		fs := flag.NewFlagSet("session tag", flag.ContinueOnError)
+		remove := fs.Bool("rm", false, "remove the given tags instead of adding them")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) == 0 {
+			return fmt.Errorf("usage: claudex session tag [--rm] <name> [<tag>...]")
+		}
+		add, drop := positional[1:], []string(nil)
+		if *remove {
+			add, drop = nil, positional[1:]
+		}
+		name, tags, err := manage.Tag(positional[0], add, drop)
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			fmt.Printf("%s: no tags\n", name)
+			return nil
+		}
+		fmt.Printf("%s: %s\n", name, strings.Join(tags, ", "))
+		return nil
+
+	case "search":
+		fs := flag.NewFlagSet("session search", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print the results as JSON")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		query := strings.TrimSpace(strings.Join(positional, " "))
+		if query == "" {
+			return fmt.Errorf("usage: claudex search [--json] <query>")
+		}
+		return manage.WriteSearch(os.Stdout, query, *asJSON)
+
+	case "pin", "unpin":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: claudex session %s <name>", sub)
+		}
+		sessionName, err := manage.Pin(rest[0], sub == "pin")
+		if err != nil {
+			return err
+		}
+		if sub == "pin" {
+			fmt.Printf("Pinned %s (claudex gc keeps it)\n", sessionName)
+		} else {
+			fmt.Printf("Unpinned %s\n", sessionName)
+		}
+		return nil
+
+	case "rm", "remove":
+		fs := flag.NewFlagSet("session rm", flag.ContinueOnError)
+		force := fs.Bool("force", false, "delete without asking for confirmation")
+		fs.BoolVar(force, "f", false, "shorthand for --force")
+		fs.BoolVar(force, "yes", false, "alias for --force")
+		fs.BoolVar(force, "y", false, "alias for --force")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session rm [--force] <name>")
+		}
+		sessionName, err := manage.Find(positional[0])
+		if err != nil {
+			return err
+		}
+		if !*force {
+			ok, err := confirm(terminal.Detect(deps.Env), fmt.Sprintf("Delete session %s?", sessionName))
+			if err != nil {
+				return fmt.Errorf("%w; pass --force to delete %s", err, sessionName)
+			}
+			if !ok {
+				fmt.Println("○ Kept.")
+				return nil
+			}
+		}
+		if _, err := manage.Remove(sessionName); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s\n", sessionName)
+		return nil
+
+	case "archive":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: claudex session archive <name>")
+		}
+		sessionName, err := manage.Archive(rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Archived %s (restore with: claudex session unarchive %s)\n", sessionName, sessionName)
+		return nil
+
+	case "unarchive", "restore":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: claudex session unarchive <name>")
+		}
+		sessionName, err := manage.Unarchive(rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %s\n", sessionName)
+		return nil
+
+	case "purge":
+		fs := flag.NewFlagSet("session purge", flag.ContinueOnError)
+		force := fs.Bool("force", false, "delete without asking for confirmation")
+		fs.BoolVar(force, "f", false, "shorthand for --force")
+		fs.BoolVar(force, "yes", false, "alias for --force")
+		fs.BoolVar(force, "y", false, "alias for --force")
+		all := fs.Bool("all", false, "purge every archived session")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if *all == (len(positional) == 1) || len(positional) > 1 {
+			return fmt.Errorf("usage: claudex session purge [--force] --all | <name>")
+		}
+
+		target := "every archived session and log file"
+		if !*all {
+			if target, err = manage.FindArchived(positional[0]); err != nil {
+				return err
+			}
+		}
+		if !*force {
+			ok, err := confirm(terminal.Detect(deps.Env), fmt.Sprintf("Permanently delete %s?", target))
+			if err != nil {
+				return fmt.Errorf("%w; pass --force to purge %s", err, target)
+			}
+			if !ok {
+				fmt.Println("○ Kept.")
+				return nil
+			}
+		}
+
+		if *all {
+			purged, err := manage.PurgeAll()
+			for _, name := range purged {
+				fmt.Printf("Purged %s\n", name)
+			}
+			if err != nil {
+				return err
+			}
+			logs, err := manage.PurgeLogs()
+			if logs > 0 {
+				fmt.Printf("Purged %d archived log files\n", logs)
+			}
+			return err
+		}
+		if _, err := manage.Purge(target); err != nil {
+			return err
+		}
+		fmt.Printf("Purged %s\n", target)
+		return nil
+
+	case "export":
+		fs := flag.NewFlagSet("session export", flag.ContinueOnError)
+		out := fs.String("o", "", "bundle file to write (default: <name>.tar.gz)")
+		transcript := fs.Bool("transcript", false, "include the Claude transcripts")
+		redactHome := fs.Bool("redact-home", false, "replace your home directory with ~ in every text file")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session export [--transcript] [--redact-home] [-o <file>] <name>")
+		}
+		sessionName, err := manage.Find(positional[0])
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			*out = sessionbundleuc.DefaultOutput(sessionName)
+		}
+
+		uc := sessionbundleuc.New(deps.FS, deps.Env, deps.UUID, deps.Clock, projectDir, sessionsDir)
+		manifest, err := uc.Export(sessionName, *out, sessionbundleuc.ExportOptions{Transcript: *transcript, RedactHome: *redactHome})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Exported %s to %s (%d files)\n", sessionName, *out, len(manifest.Files))
+		return nil
+
+	case "import":
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: claudex session import <bundle>")
+		}
+		uc := sessionbundleuc.New(deps.FS, deps.Env, deps.UUID, deps.Clock, projectDir, sessionsDir)
+		imported, err := uc.Import(rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %s as %s\n", imported.Manifest.Session, imported.Name)
+		if imported.Transcript == "" {
+			fmt.Printf("○ The bundle has no transcript; start a new conversation with: claudex session fresh %s\n", imported.Name)
+		}
+		return nil
+
+	case "stats":
+		fs := flag.NewFlagSet("session stats", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "print the report as JSON")
+		positional, err := parseArgs(fs, rest)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return fmt.Errorf("usage: claudex session stats [--json] <name>")
+		}
+
+		uc := sessionstatsuc.New(deps.FS, deps.Env, projectDir, sessionsDir)
+		report, err := uc.Stats(positional[0])
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return sessionstatsuc.WriteJSON(os.Stdout, report)
+		}
+		return sessionstatsuc.Write(os.Stdout, report)
+
+	default:
+		fmt.Fprint(os.Stderr, sessionUsage)
+		return fmt.Errorf("unknown session command: %s", sub)
+	}
+}
+
+// sessionShortcut runs a `claudex session` command as a top-level command
+// (e.g. `claudex resume auth` for `claudex session resume auth`)
+func sessionShortcut(command string) func(args []string) error {
+	return func(args []string) error {
+		return runSession(append([]string{command}, args...))
+	}
+}
+
+// launchOrPrint starts Claude for a prepared session, or prints the session
+// name for scripts when noLaunch is set
+func launchOrPrint(a *app.App, si app.SessionInfo, noLaunch bool) error {
+	if noLaunch {
+		fmt.Println(si.Name)
+		return nil
+	}
+	return a.Launch(si)
+}
+
+// parseArgs parses flags that may appear before or after positional
+// arguments (e.g. `fork auth -m "desc"`) and returns the positionals
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// confirm asks before a destructive action, defaulting to no. Only the
+// command's own --force/--yes skips it: with --no-prompts or without a
+// terminal it fails instead of guessing.
+func confirm(mode terminal.Mode, question string) (bool, error) {
+	if mode.NoPrompts {
+		return false, fmt.Errorf("cannot ask for confirmation with --no-prompts")
+	}
+	if !mode.Interactive {
+		return false, fmt.Errorf("cannot ask for confirmation without an interactive terminal")
+	}
+
+	fmt.Printf("%s [y/N]: ", question)
+
+	var response string
+	fmt.Scanln(&response)
+
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
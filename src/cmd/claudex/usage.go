@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/app"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/usage"
+	usagereportuc "claudex/internal/usecases/usagereport"
+)
+
+const usageUsage = `Usage: claudex usage [options]
+
+Report token usage and spend of headless Claude invocations (session naming,
+autodoc, index updates) recorded in .claudex/usage.jsonl.
+
+Options:
+  --by <grouping>   Only show one table: feature, day or session
+  --days <n>        Only include the last n days (default: all history)
+`
+
+// runUsage implements `claudex usage`
+func runUsage(args []string) error {
+	fs := flag.NewFlagSet("usage", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usageUsage) }
+	by := fs.String("by", "", "only show one grouping: feature, day or session")
+	days := fs.Int("days", 0, "only include the last n days")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprint(os.Stderr, usageUsage)
+		return fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+
+	deps := app.NewDependencies()
+	projectDir, err := paths.ProjectRoot(deps.FS)
+	if err != nil {
+		return err
+	}
+
+	var groupings []string
+	if *by != "" {
+		groupings = []string{strings.ToLower(*by)}
+	}
+
+	ledger := usage.New(deps.FS, filepath.Join(projectDir, paths.UsageFile))
+	uc := usagereportuc.New(ledger, deps.Clock)
+	return uc.Report(os.Stdout, groupings, *days)
+}
@@ -2,7 +2,7 @@ package doc
 
 // DocumentationUpdater defines the interface for documentation update operations
 type DocumentationUpdater interface {
-	// RunBackground starts doc update in background goroutine
+	// RunBackground queues the doc update for a detached worker
 	// Returns immediately, update happens asynchronously
 	RunBackground(config UpdaterConfig) error
 
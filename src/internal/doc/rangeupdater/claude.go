@@ -1,50 +1,99 @@
 package rangeupdater
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"os/exec"
 
 	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/models"
 )
 
-// InvokeClaudeForIndex invokes Claude to regenerate an index.md file.
+// IndexJob is the payload of an index-update job
+type IndexJob struct {
+	IndexPath string `json:"indexPath"`
+	Prompt    string `json:"prompt"`
+	Model     string `json:"model"`
+	Session   string `json:"session,omitempty"`
+}
+
+// InvokeClaudeForIndex queues a job that invokes Claude to regenerate an
+// index.md file, then starts a detached worker to run it.
 // Claude uses its Edit tool to update the file directly.
 // The recursion guard (CLAUDE_HOOK_INTERNAL=1) prevents infinite loops.
-func InvokeClaudeForIndex(cmdr commander.Commander, env env.Environment, indexPath, listing, modifiedFiles string) error {
+func InvokeClaudeForIndex(cmdr commander.Commander, env env.Environment, queue jobs.Service, spawner jobs.Spawner, indexPath, listing, modifiedFiles string) error {
 	// Recursion guard: check if we're already inside a hook invocation
 	if env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
 		log.Printf("Skipping index update for %s: recursion guard triggered", indexPath)
 		return nil
 	}
 
-	log.Printf("Spawning background process to regenerate %s", indexPath)
+	if queue == nil || spawner == nil {
+		return fmt.Errorf("index updates require a job queue")
+	}
 
 	// Build Claude prompt with context
 	prompt := buildPrompt(indexPath, listing, modifiedFiles)
+	model := models.FromEnv(env).Model(models.TaskIndexUpdate)
 
-	// Create a detached background process using bash
-	// This ensures the process survives even after the calling process exits
-	// Claude will use its Edit tool to update the file directly
-	// Using --model haiku for cost efficiency (index updates are simple tasks)
-	bashScript := fmt.Sprintf(`
-export CLAUDE_HOOK_INTERNAL=1
-claude -p %q --model haiku 2>/dev/null
-`, prompt)
-
-	cmd := exec.Command("bash", "-c", bashScript)
-
-	// Detach the process so it survives after we exit
-	if err := cmd.Start(); err != nil {
-		log.Printf("Failed to start background Claude process for %s: %v", indexPath, err)
-		return fmt.Errorf("failed to start background Claude process: %w", err)
+	job, err := queue.Enqueue(jobs.Request{
+		Kind:   jobs.KindIndexUpdate,
+		Target: indexPath,
+		Model:  model,
+		Payload: IndexJob{
+			IndexPath: indexPath,
+			Prompt:    prompt,
+			Model:     model,
+			Session:   env.Get("CLAUDEX_SESSION"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue index update: %w", err)
 	}
 
-	log.Printf("Background process started (PID: %d) for %s", cmd.Process.Pid, indexPath)
+	// If spawning fails the job stays queued for the next worker
+	if err := spawner.Spawn(queue.Dir()); err != nil {
+		log.Printf("Failed to start worker for %s (job %s): %v", indexPath, job.ID, err)
+		return fmt.Errorf("failed to start background worker: %w", err)
+	}
+
+	log.Printf("Queued index update job %s for %s", job.ID, indexPath)
 	return nil
 }
 
+// IndexJobHandler returns the jobs.Handler that executes queued index-update
+// jobs with the given LLM backend. Backend diagnostics go to the job log.
+func IndexJobHandler(backend llm.Backend) jobs.Handler {
+	return func(job *jobs.Job, stderr io.Writer) error {
+		var payload IndexJob
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid job payload: %w", err)
+		}
+
+		model := payload.Model
+		if model == "" {
+			model = models.Default(models.TaskIndexUpdate)
+		}
+
+		_, err := backend.Complete(llm.Request{
+			Prompt:     payload.Prompt,
+			Model:      model,
+			OutputPath: payload.IndexPath,
+			Stderr:     stderr,
+			Feature:    string(jobs.KindIndexUpdate),
+			Session:    payload.Session,
+		})
+		if err != nil {
+			return fmt.Errorf("%s backend failed for %s: %w", backend.Name(), payload.IndexPath, err)
+		}
+		return nil
+	}
+}
+
 // buildPrompt constructs the Claude prompt for index.md regeneration
 func buildPrompt(indexPath, listing, modifiedFiles string) string {
 	return fmt.Sprintf(`A code change was made. Update the index.md at %s if needed.
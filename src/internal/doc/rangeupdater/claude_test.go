@@ -0,0 +1,81 @@
+package rangeupdater
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"claudex/internal/services/jobs"
+	"claudex/internal/testutil"
+)
+
+type recordingSpawner struct {
+	dirs []string
+}
+
+func (s *recordingSpawner) Spawn(dir string) error {
+	s.dirs = append(s.dirs, dir)
+	return nil
+}
+
+func TestInvokeClaudeForIndex_QueuesJob(t *testing.T) {
+	h := testutil.NewTestHarness()
+	queue := jobs.New(h.FS, "/project/.claudex/jobs", h, h)
+	spawner := &recordingSpawner{}
+	env := &mockEnvironment{vars: make(map[string]string)}
+
+	err := InvokeClaudeForIndex(&mockCommander{}, env, queue, spawner, "/project/src/index.md", "foo.go", "foo.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	queued, err := queue.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queued) != 1 {
+		t.Fatalf("expected 1 queued job, got %d", len(queued))
+	}
+
+	job := queued[0]
+	if job.Kind != jobs.KindIndexUpdate {
+		t.Errorf("expected kind %q, got %q", jobs.KindIndexUpdate, job.Kind)
+	}
+	if job.Target != "/project/src/index.md" {
+		t.Errorf("expected target '/project/src/index.md', got '%s'", job.Target)
+	}
+	if job.Model != "haiku" {
+		t.Errorf("expected model 'haiku', got '%s'", job.Model)
+	}
+
+	var payload IndexJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if !strings.Contains(payload.Prompt, "/project/src/index.md") {
+		t.Errorf("expected prompt to reference the index path, got: %s", payload.Prompt)
+	}
+
+	if len(spawner.dirs) != 1 || spawner.dirs[0] != "/project/.claudex/jobs" {
+		t.Errorf("expected worker spawned for the queue, got %v", spawner.dirs)
+	}
+}
+
+func TestInvokeClaudeForIndex_RecursionGuard(t *testing.T) {
+	h := testutil.NewTestHarness()
+	queue := jobs.New(h.FS, "/project/.claudex/jobs", h, h)
+	spawner := &recordingSpawner{}
+	env := &mockEnvironment{vars: map[string]string{"CLAUDE_HOOK_INTERNAL": "1"}}
+
+	if err := InvokeClaudeForIndex(&mockCommander{}, env, queue, spawner, "/project/src/index.md", "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	queued, _ := queue.List()
+	if len(queued) != 0 {
+		t.Errorf("expected no jobs while inside a hook invocation, got %d", len(queued))
+	}
+	if len(spawner.dirs) != 0 {
+		t.Errorf("expected no worker spawned, got %v", spawner.dirs)
+	}
+}
@@ -151,7 +151,7 @@ func createUpdater(t *testing.T, repoPath string) (*RangeUpdater, string, *mockE
 		LockTimeout:   0,
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, mockEnv)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, mockEnv, nil, nil)
 
 	return updater, sessionPath, mockEnv
 }
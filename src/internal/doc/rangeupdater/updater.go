@@ -13,6 +13,7 @@ import (
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/env"
 	"claudex/internal/services/git"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
@@ -42,9 +43,12 @@ type RangeUpdater struct {
 	cmdr        commander.Commander
 	fs          afero.Fs
 	env         env.Environment
+	queue       jobs.Service
+	spawner     jobs.Spawner
 }
 
-// New creates a new RangeUpdater instance
+// New creates a new RangeUpdater instance.
+// Index updates are queued on queue and run by a worker started via spawner.
 func New(
 	config RangeUpdaterConfig,
 	gitSvc git.GitService,
@@ -53,6 +57,8 @@ func New(
 	cmdr commander.Commander,
 	fs afero.Fs,
 	env env.Environment,
+	queue jobs.Service,
+	spawner jobs.Spawner,
 ) *RangeUpdater {
 	return &RangeUpdater{
 		config:      config,
@@ -62,6 +68,8 @@ func New(
 		cmdr:        cmdr,
 		fs:          fs,
 		env:         env,
+		queue:       queue,
+		spawner:     spawner,
 	}
 }
 
@@ -218,8 +226,8 @@ func (ru *RangeUpdater) updateIndex(indexPath string, changedFiles []string) err
 	// Format changed files for context
 	filesContext := formatChangedFilesContext(changedFiles, indexDir)
 
-	// Invoke Claude to update the index file directly
-	return InvokeClaudeForIndex(ru.cmdr, ru.env, indexPath, listing, filesContext)
+	// Queue a Claude invocation to update the index file directly
+	return InvokeClaudeForIndex(ru.cmdr, ru.env, ru.queue, ru.spawner, indexPath, listing, filesContext)
 }
 
 // getDirectoryListing returns a formatted listing of files in the directory
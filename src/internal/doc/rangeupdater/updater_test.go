@@ -47,6 +47,10 @@ func (m *mockGitService) GetMergeBase(branch string) (string, error) {
 	return m.mergeBase, nil
 }
 
+func (m *mockGitService) GetCurrentBranch() (string, error) {
+	return "main", nil
+}
+
 type mockLockService struct {
 	isLocked     bool
 	acquireFails bool
@@ -135,6 +139,10 @@ func (m *mockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Wr
 	return nil
 }
 
+func (m *mockCommander) StartIn(dir, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return nil
+}
+
 type mockEnvironment struct {
 	vars map[string]string
 }
@@ -170,7 +178,7 @@ func TestRangeUpdater_Run_FirstRun_Initializes(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -208,7 +216,7 @@ func TestRangeUpdater_Run_NoNewCommits_Skips(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -243,7 +251,7 @@ func TestRangeUpdater_Run_Locked_SkipsWithLockStatus(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -279,7 +287,7 @@ func TestRangeUpdater_Run_SkipRules_AllMarkdown(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -323,7 +331,7 @@ func TestRangeUpdater_Run_SkipRules_EnvVar(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -368,7 +376,7 @@ func TestRangeUpdater_Run_UnreachableBase_UsesFallback(t *testing.T) {
 	fs.MkdirAll("/src", 0755)
 	afero.WriteFile(fs, "/src/index.md", []byte("# Index"), 0644)
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	// Should succeed with fallback
@@ -410,7 +418,7 @@ func TestRangeUpdater_Run_NoAffectedIndexes_UpdatesTracking(t *testing.T) {
 		DefaultBranch: "main",
 	}
 
-	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env)
+	updater := New(config, gitSvc, lockSvc, trackingSvc, cmdr, fs, env, nil, nil)
 	result, err := updater.Run()
 
 	if err != nil {
@@ -620,6 +628,10 @@ func (m *mockGitServiceWithCallback) GetMergeBase(branch string) (string, error)
 	return "", fmt.Errorf("not implemented")
 }
 
+func (m *mockGitServiceWithCallback) GetCurrentBranch() (string, error) {
+	return "", fmt.Errorf("not implemented")
+}
+
 func TestHandleUnreachableBase_AllFail_ReturnsError(t *testing.T) {
 	gitSvc := &mockGitService{
 		mergeBaseError: fmt.Errorf("no merge base found"),
@@ -23,16 +23,29 @@ type TranscriptEntry struct {
 
 // rawTranscriptLine represents the raw JSONL structure we're parsing
 type rawTranscriptLine struct {
-	Type      string          `json:"type"`
-	Timestamp string          `json:"timestamp"`
-	Message   *rawMessage     `json:"message,omitempty"`
+	Type          string            `json:"type"`
+	Timestamp     string            `json:"timestamp"`
+	IsSidechain   bool              `json:"isSidechain,omitempty"`
+	AgentID       string            `json:"agentId,omitempty"`
+	Message       *rawMessage       `json:"message,omitempty"`
 	ToolUseResult *rawToolUseResult `json:"toolUseResult,omitempty"`
 }
 
 type rawMessage struct {
+	ID      string       `json:"id,omitempty"`
+	Model   string       `json:"model,omitempty"`
+	Usage   *rawUsage    `json:"usage,omitempty"`
 	Content []rawContent `json:"content"`
 }
 
+// rawUsage is the API usage reported on each assistant message
+type rawUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
 type rawToolUseResult struct {
 	Status  string       `json:"status"`
 	AgentID string       `json:"agentId"`
@@ -40,8 +53,12 @@ type rawToolUseResult struct {
 }
 
 type rawContent struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
 }
 
 // ParseTranscript reads JSONL transcript and extracts relevant entries.
@@ -1,13 +1,18 @@
 package doc
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
+	"io"
+	"path/filepath"
 
+	"claudex/internal/services/clock"
 	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/session"
+	"claudex/internal/services/usage"
 
 	"github.com/spf13/afero"
 )
@@ -25,40 +30,89 @@ type UpdaterConfig struct {
 
 // Updater handles background Claude invocations for doc updates
 type Updater struct {
-	fs  afero.Fs
-	cmd commander.Commander
-	env env.Environment
+	fs      afero.Fs
+	cmd     commander.Commander
+	env     env.Environment
+	backend llm.Backend
+	queue   jobs.Service
+	spawner jobs.Spawner
 }
 
-// NewUpdater creates a new Updater instance
-func NewUpdater(fs afero.Fs, cmd commander.Commander, env env.Environment) *Updater {
+// NewUpdater creates a new Updater instance.
+// The LLM backend is selected from the CLAUDEX_LLM_* environment; with a
+// queue, its calls are recorded in the project's usage ledger.
+// queue and spawner are used by RunBackground; spawner may be nil when the
+// updater only runs synchronously (e.g. inside a job worker).
+func NewUpdater(fs afero.Fs, cmd commander.Commander, env env.Environment, queue jobs.Service, spawner jobs.Spawner) *Updater {
+	backend := llm.FromEnv(fs, cmd, env)
+	if queue != nil {
+		ledger := usage.New(fs, usage.PathForJobs(queue.Dir()))
+		backend = usage.NewRecorder(backend, ledger, clock.New())
+	}
+
 	return &Updater{
-		fs:  fs,
-		cmd: cmd,
-		env: env,
+		fs:      fs,
+		cmd:     cmd,
+		env:     env,
+		backend: backend,
+		queue:   queue,
+		spawner: spawner,
 	}
 }
 
-// RunBackground starts doc update in background goroutine
-// Returns immediately, update happens asynchronously
+// RunBackground enqueues the doc update as a durable job and starts a
+// detached worker to run it. Returns immediately; the update survives the
+// calling hook process exiting.
 func (u *Updater) RunBackground(config UpdaterConfig) error {
 	// Validate configuration
 	if err := u.validateConfig(config); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Launch background goroutine
-	go func() {
-		// Errors in background processing are logged but don't propagate
-		_ = u.Run(config)
-	}()
+	// Hooks fired by our own Claude invocations must not queue more work
+	if u.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
+		return nil
+	}
+
+	if u.queue == nil || u.spawner == nil {
+		return fmt.Errorf("background updates require a job queue")
+	}
+
+	job, err := u.queue.Enqueue(jobs.Request{
+		Kind:    jobs.KindSessionOverview,
+		Target:  filepath.Join(config.SessionPath, config.OutputFile),
+		Model:   config.Model,
+		Payload: config,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue doc update: %w", err)
+	}
+
+	// If spawning fails the job stays queued for the next worker
+	if err := u.spawner.Spawn(u.queue.Dir()); err != nil {
+		return fmt.Errorf("failed to start worker for job %s: %w", job.ID, err)
+	}
 
 	return nil
 }
 
+// HandleJob executes a queued session-overview job (jobs.Handler)
+func (u *Updater) HandleJob(job *jobs.Job, stderr io.Writer) error {
+	var config UpdaterConfig
+	if err := json.Unmarshal(job.Payload, &config); err != nil {
+		return fmt.Errorf("invalid job payload: %w", err)
+	}
+	return u.RunWithStderr(config, stderr)
+}
+
 // Run executes doc update synchronously (for testing)
 // This is the main implementation that does the actual work
 func (u *Updater) Run(config UpdaterConfig) error {
+	return u.RunWithStderr(config, io.Discard)
+}
+
+// RunWithStderr executes doc update synchronously, copying Claude's stderr to w
+func (u *Updater) RunWithStderr(config UpdaterConfig, w io.Writer) error {
 	// Check recursion guard before doing any work
 	if u.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
 		return fmt.Errorf("recursion guard: CLAUDE_HOOK_INTERNAL is set")
@@ -87,14 +141,17 @@ func (u *Updater) Run(config UpdaterConfig) error {
 	// Build final prompt
 	prompt := BuildDocumentationPrompt(template, transcriptContent, config.SessionContext, config.SessionPath)
 
-	// Invoke Claude with recursion guard
-	if err := u.invokeClaude(prompt, config.Model); err != nil {
+	// Invoke the LLM backend to update the output file
+	outputPath := ""
+	if config.OutputFile != "" {
+		outputPath = filepath.Join(config.SessionPath, config.OutputFile)
+	}
+	if err := u.invokeClaude(prompt, config.Model, outputPath, filepath.Base(config.SessionPath), w); err != nil {
 		return fmt.Errorf("failed to invoke Claude: %w", err)
 	}
 
 	// Update last processed line marker
-	lastLineFile := fmt.Sprintf("%s/.last-processed-line-overview", config.SessionPath)
-	if err := afero.WriteFile(u.fs, lastLineFile, []byte(fmt.Sprintf("%d", lastLine)), 0644); err != nil {
+	if err := session.WriteLastProcessedLine(u.fs, config.SessionPath, lastLine); err != nil {
 		return fmt.Errorf("failed to update last processed line: %w", err)
 	}
 
@@ -121,40 +178,19 @@ func (u *Updater) validateConfig(config UpdaterConfig) error {
 	return nil
 }
 
-// invokeClaude calls the claude CLI with the given prompt
-// Sets CLAUDE_HOOK_INTERNAL=1 to prevent recursion
-func (u *Updater) invokeClaude(prompt string, model string) error {
-	// Set recursion guard in environment
-	originalValue := u.env.Get("CLAUDE_HOOK_INTERNAL")
-	u.env.Set("CLAUDE_HOOK_INTERNAL", "1")
-	defer func() {
-		if originalValue == "" {
-			// Restore by setting to empty (best effort, depends on env implementation)
-			u.env.Set("CLAUDE_HOOK_INTERNAL", "")
-		} else {
-			u.env.Set("CLAUDE_HOOK_INTERNAL", originalValue)
-		}
-	}()
-
-	// Create command with recursion guard via actual exec.Command
-	// We need to use exec.Command directly here to set custom environment
-	cmd := exec.Command("claude", "-p", prompt, "--model", model, "--output-format", "stream-json")
-
-	// Set environment with recursion guard
-	cmdEnv := os.Environ()
-	cmdEnv = append(cmdEnv, "CLAUDE_HOOK_INTERNAL=1")
-	cmd.Env = cmdEnv
-
-	// Capture output for potential logging
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Execute command
-	err := cmd.Run()
+// invokeClaude runs the prompt through the configured LLM backend.
+// The Claude CLI backend sets CLAUDE_HOOK_INTERNAL=1 to prevent recursion.
+func (u *Updater) invokeClaude(prompt, model, outputPath, session string, w io.Writer) error {
+	_, err := u.backend.Complete(llm.Request{
+		Prompt:     prompt,
+		Model:      model,
+		OutputPath: outputPath,
+		Stderr:     w,
+		Feature:    string(jobs.KindSessionOverview),
+		Session:    session,
+	})
 	if err != nil {
-		return fmt.Errorf("claude command failed: %w (stderr: %s)", err, stderr.String())
+		return fmt.Errorf("%s backend failed: %w", u.backend.Name(), err)
 	}
-
 	return nil
 }
@@ -1,8 +1,14 @@
 package doc
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
 	"testing"
 
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/session"
 	"claudex/internal/testutil"
 
 	"github.com/spf13/afero"
@@ -13,7 +19,7 @@ import (
 func TestNewUpdater(t *testing.T) {
 	h := testutil.NewTestHarness()
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	assert.NotNil(t, updater)
 	assert.Equal(t, h.FS, updater.fs)
@@ -42,7 +48,7 @@ func TestRun_Success(t *testing.T) {
 	template := "Content: $RELEVANT_CONTENT\nContext: $DOC_CONTEXT"
 	h.WriteFile(templatePath, template)
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    sessionPath,
@@ -58,9 +64,9 @@ func TestRun_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify last processed line was updated
-	lastLineData, err := afero.ReadFile(h.FS, sessionPath+"/.last-processed-line-overview")
+	lastLine, err := session.ReadLastProcessedLine(h.FS, sessionPath)
 	require.NoError(t, err)
-	assert.Equal(t, "1", string(lastLineData))
+	assert.Equal(t, 1, lastLine)
 }
 
 func TestRun_RecursionGuard(t *testing.T) {
@@ -69,7 +75,7 @@ func TestRun_RecursionGuard(t *testing.T) {
 	// Set recursion guard
 	h.Env.Set("CLAUDE_HOOK_INTERNAL", "1")
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -104,7 +110,7 @@ func TestRun_NoNewContent(t *testing.T) {
 	h.WriteFile(transcriptPath, transcript)
 	h.WriteFile(templatePath, "Template")
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    sessionPath,
@@ -124,7 +130,7 @@ func TestRun_NoNewContent(t *testing.T) {
 func TestRun_TranscriptNotFound(t *testing.T) {
 	h := testutil.NewTestHarness()
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -148,7 +154,7 @@ func TestRun_TemplateNotFound(t *testing.T) {
 `
 	h.WriteFile(transcriptPath, transcript)
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -184,7 +190,7 @@ func TestRun_PromptBuilding(t *testing.T) {
 	template := "Transcript:\n$RELEVANT_CONTENT\n\nContext:\n$DOC_CONTEXT"
 	h.WriteFile(templatePath, template)
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    sessionPath,
@@ -199,8 +205,20 @@ func TestRun_PromptBuilding(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// fakeSpawner records worker spawn requests instead of starting processes
+type fakeSpawner struct {
+	dirs []string
+	err  error
+}
+
+func (f *fakeSpawner) Spawn(dir string) error {
+	f.dirs = append(f.dirs, dir)
+	return f.err
+}
+
 func TestRunBackground_Success(t *testing.T) {
 	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"11111111-2222-3333-4444-555555555555"}
 
 	sessionPath := "/test/session"
 	transcriptPath := "/test/transcript.jsonl"
@@ -210,11 +228,14 @@ func TestRunBackground_Success(t *testing.T) {
 	h.WriteFile(transcriptPath, `{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"content":[{"type":"text","text":"Hello"}]}}`)
 	h.WriteFile(templatePath, "Template: $RELEVANT_CONTENT")
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	queue := jobs.New(h.FS, "/test/.claudex/jobs", h, h)
+	spawner := &fakeSpawner{}
+	updater := NewUpdater(h.FS, h.Commander, h.Env, queue, spawner)
 
 	config := UpdaterConfig{
 		SessionPath:    sessionPath,
 		TranscriptPath: transcriptPath,
+		OutputFile:     "session-overview.md",
 		PromptTemplate: templatePath,
 		Model:          "haiku",
 		StartLine:      1,
@@ -222,15 +243,80 @@ func TestRunBackground_Success(t *testing.T) {
 
 	// RunBackground should return immediately
 	err := updater.RunBackground(config)
+	require.NoError(t, err)
+
+	// A durable job is queued and a worker is spawned for the queue
+	queued, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, jobs.KindSessionOverview, queued[0].Kind)
+	assert.Equal(t, jobs.StateQueued, queued[0].State)
+	assert.Equal(t, filepath.Join(sessionPath, "session-overview.md"), queued[0].Target)
+	assert.Equal(t, "haiku", queued[0].Model)
+	assert.Equal(t, []string{"/test/.claudex/jobs"}, spawner.dirs)
+
+	var payload UpdaterConfig
+	require.NoError(t, json.Unmarshal(queued[0].Payload, &payload))
+	assert.Equal(t, config, payload)
+}
+
+func TestRunBackground_RecursionGuardSkipsQueue(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("CLAUDE_HOOK_INTERNAL", "1")
+
+	queue := jobs.New(h.FS, "/test/.claudex/jobs", h, h)
+	spawner := &fakeSpawner{}
+	updater := NewUpdater(h.FS, h.Commander, h.Env, queue, spawner)
 
+	err := updater.RunBackground(UpdaterConfig{
+		SessionPath:    "/test/session",
+		TranscriptPath: "/test/transcript.jsonl",
+		PromptTemplate: "/test/template.md",
+		Model:          "haiku",
+		StartLine:      1,
+	})
 	require.NoError(t, err)
-	// Note: We can't reliably test background execution completion in unit tests
-	// The goroutine may or may not have completed by the time we check
+
+	queued, err := queue.List()
+	require.NoError(t, err)
+	assert.Empty(t, queued)
+	assert.Empty(t, spawner.dirs)
+}
+
+func TestRunBackground_SpawnFailureKeepsJobQueued(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	queue := jobs.New(h.FS, "/test/.claudex/jobs", h, h)
+	spawner := &fakeSpawner{err: errors.New("exec: not found")}
+	updater := NewUpdater(h.FS, h.Commander, h.Env, queue, spawner)
+
+	err := updater.RunBackground(UpdaterConfig{
+		SessionPath:    "/test/session",
+		TranscriptPath: "/test/transcript.jsonl",
+		PromptTemplate: "/test/template.md",
+		Model:          "haiku",
+		StartLine:      1,
+	})
+	require.Error(t, err)
+
+	queued, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, jobs.StateQueued, queued[0].State)
+}
+
+func TestHandleJob_InvalidPayload(t *testing.T) {
+	h := testutil.NewTestHarness()
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
+
+	err := updater.HandleJob(&jobs.Job{Kind: jobs.KindSessionOverview, Payload: []byte("not json")}, io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid job payload")
 }
 
 func TestValidateConfig_AllValid(t *testing.T) {
 	h := testutil.NewTestHarness()
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -247,7 +333,7 @@ func TestValidateConfig_AllValid(t *testing.T) {
 
 func TestValidateConfig_MissingSessionPath(t *testing.T) {
 	h := testutil.NewTestHarness()
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		TranscriptPath: "/test/transcript.jsonl",
@@ -264,7 +350,7 @@ func TestValidateConfig_MissingSessionPath(t *testing.T) {
 
 func TestValidateConfig_MissingTranscriptPath(t *testing.T) {
 	h := testutil.NewTestHarness()
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -281,7 +367,7 @@ func TestValidateConfig_MissingTranscriptPath(t *testing.T) {
 
 func TestValidateConfig_MissingPromptTemplate(t *testing.T) {
 	h := testutil.NewTestHarness()
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -298,7 +384,7 @@ func TestValidateConfig_MissingPromptTemplate(t *testing.T) {
 
 func TestValidateConfig_MissingModel(t *testing.T) {
 	h := testutil.NewTestHarness()
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    "/test/session",
@@ -315,7 +401,7 @@ func TestValidateConfig_MissingModel(t *testing.T) {
 
 func TestValidateConfig_InvalidStartLine(t *testing.T) {
 	h := testutil.NewTestHarness()
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	tests := []struct {
 		name      string
@@ -363,7 +449,7 @@ func TestRun_IncrementalProcessing(t *testing.T) {
 	h.WriteFile(transcriptPath, transcript)
 	h.WriteFile(templatePath, "Template: $RELEVANT_CONTENT")
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	// First run: process from line 1
 	config := UpdaterConfig{
@@ -378,9 +464,9 @@ func TestRun_IncrementalProcessing(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check last processed line
-	lastLineData, err := afero.ReadFile(h.FS, sessionPath+"/.last-processed-line-overview")
+	lastLine, err := session.ReadLastProcessedLine(h.FS, sessionPath)
 	require.NoError(t, err)
-	assert.Equal(t, "3", string(lastLineData))
+	assert.Equal(t, 3, lastLine)
 
 	// Second run: process from line 4 (should have no new content)
 	config.StartLine = 4
@@ -405,7 +491,7 @@ func TestRun_LastProcessedLineUpdate(t *testing.T) {
 	h.WriteFile(transcriptPath, transcript)
 	h.WriteFile(templatePath, "Template")
 
-	updater := NewUpdater(h.FS, h.Commander, h.Env)
+	updater := NewUpdater(h.FS, h.Commander, h.Env, nil, nil)
 
 	config := UpdaterConfig{
 		SessionPath:    sessionPath,
@@ -418,12 +504,12 @@ func TestRun_LastProcessedLineUpdate(t *testing.T) {
 	err := updater.Run(config)
 	require.NoError(t, err)
 
-	// Verify file exists and contains correct line number
-	exists, err := afero.Exists(h.FS, sessionPath+"/.last-processed-line-overview")
+	// Verify session.json exists and contains correct line number
+	exists, err := afero.Exists(h.FS, sessionPath+"/session.json")
 	require.NoError(t, err)
 	assert.True(t, exists)
 
-	content, err := afero.ReadFile(h.FS, sessionPath+"/.last-processed-line-overview")
+	lastLine, err := session.ReadLastProcessedLine(h.FS, sessionPath)
 	require.NoError(t, err)
-	assert.Equal(t, "1", string(content))
+	assert.Equal(t, 1, lastLine)
 }
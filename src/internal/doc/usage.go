@@ -0,0 +1,142 @@
+package doc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// syntheticModel marks messages the CLI generated locally (no API call)
+const syntheticModel = "<synthetic>"
+
+// MessageUsage is the token usage of one assistant API response
+type MessageUsage struct {
+	Timestamp           string         // ISO 8601 timestamp of the first line of the message
+	MessageID           string         // API message ID
+	Model               string         // Model that served the response
+	AgentID             string         // Subagent ID; empty for the main thread
+	InputTokens         int            // Uncached input tokens
+	OutputTokens        int            // Output tokens
+	CacheCreationTokens int            // Input tokens written to the prompt cache
+	CacheReadTokens     int            // Input tokens read from the prompt cache
+	ToolUses            map[string]int // Tool name -> number of calls
+}
+
+// TranscriptUsage is the usage recorded in a transcript
+type TranscriptUsage struct {
+	// Messages holds one entry per API response, in transcript order
+	Messages []MessageUsage
+
+	// AgentTypes maps subagent IDs to the subagent_type they were started with
+	AgentTypes map[string]string
+}
+
+// ParseTranscriptUsage reads a JSONL transcript and extracts the usage of
+// every assistant message. The CLI writes one line per content block of a
+// response, each repeating the response's usage, so lines are merged by
+// message ID: usage is counted once and tool calls are summed.
+func ParseTranscriptUsage(fs afero.Fs, transcriptPath string) (*TranscriptUsage, error) {
+	file, err := fs.Open(transcriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	return parseUsageFromReader(file)
+}
+
+// parseUsageFromReader parses transcript usage from an io.Reader
+func parseUsageFromReader(r io.Reader) (*TranscriptUsage, error) {
+	scanner := bufio.NewScanner(r)
+
+	// Tool inputs and results can make lines large
+	const maxCapacity = 16 * 1024 * 1024
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCapacity)
+
+	usage := &TranscriptUsage{AgentTypes: map[string]string{}}
+	byID := map[string]int{}
+	taskTypes := map[string]string{} // Task tool_use ID -> subagent_type
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var raw rawTranscriptLine
+		if err := json.Unmarshal(line, &raw); err != nil {
+			// Skip lines whose shape we don't model (e.g. string content)
+			continue
+		}
+		if raw.Message == nil {
+			continue
+		}
+
+		switch raw.Type {
+		case "assistant":
+			if raw.Message.Usage == nil || raw.Message.Model == syntheticModel {
+				continue
+			}
+
+			idx, seen := byID[raw.Message.ID]
+			if !seen || raw.Message.ID == "" {
+				u := raw.Message.Usage
+				msg := MessageUsage{
+					Timestamp:           raw.Timestamp,
+					MessageID:           raw.Message.ID,
+					Model:               raw.Message.Model,
+					InputTokens:         u.InputTokens,
+					OutputTokens:        u.OutputTokens,
+					CacheCreationTokens: u.CacheCreationInputTokens,
+					CacheReadTokens:     u.CacheReadInputTokens,
+					ToolUses:            map[string]int{},
+				}
+				if raw.IsSidechain {
+					msg.AgentID = raw.AgentID
+				}
+				usage.Messages = append(usage.Messages, msg)
+				idx = len(usage.Messages) - 1
+				byID[raw.Message.ID] = idx
+			} else if raw.Message.Usage.OutputTokens > usage.Messages[idx].OutputTokens {
+				// Streaming lines may carry partial output counts; keep the final one
+				usage.Messages[idx].OutputTokens = raw.Message.Usage.OutputTokens
+			}
+
+			for _, c := range raw.Message.Content {
+				if c.Type != "tool_use" {
+					continue
+				}
+				usage.Messages[idx].ToolUses[c.Name]++
+				if c.Name == "Task" {
+					var input struct {
+						SubagentType string `json:"subagent_type"`
+					}
+					if json.Unmarshal(c.Input, &input) == nil && input.SubagentType != "" {
+						taskTypes[c.ID] = input.SubagentType
+					}
+				}
+			}
+
+		case "user":
+			// Task results link the tool call to the subagent it started
+			if raw.ToolUseResult == nil || raw.ToolUseResult.AgentID == "" {
+				continue
+			}
+			for _, c := range raw.Message.Content {
+				if agentType, ok := taskTypes[c.ToolUseID]; ok {
+					usage.AgentTypes[raw.ToolUseResult.AgentID] = agentType
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading transcript: %w", err)
+	}
+
+	return usage, nil
+}
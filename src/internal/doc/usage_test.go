@@ -0,0 +1,68 @@
+package doc
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTranscriptUsage_MergesLinesByMessageID(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+
+	// One response split over two lines (text, then tool_use), then a second response
+	content := `{"type":"user","timestamp":"2024-01-15T10:29:00Z","message":{"role":"user","content":"Fix the bug"}}
+{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"Looking."}],"usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":1000,"cache_read_input_tokens":2000}}}
+{"type":"assistant","timestamp":"2024-01-15T10:30:01Z","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","content":[{"type":"tool_use","id":"toolu_1","name":"Read","input":{}}],"usage":{"input_tokens":10,"output_tokens":40,"cache_creation_input_tokens":1000,"cache_read_input_tokens":2000}}}
+{"type":"assistant","timestamp":"2024-01-15T10:31:00Z","message":{"id":"msg_2","model":"claude-sonnet-4-5-20250929","content":[{"type":"tool_use","id":"toolu_2","name":"Bash","input":{}},{"type":"tool_use","id":"toolu_3","name":"Bash","input":{}}],"usage":{"input_tokens":3,"output_tokens":20}}}
+{"type":"assistant","timestamp":"2024-01-15T10:32:00Z","message":{"id":"msg_3","model":"<synthetic>","content":[{"type":"text","text":"No response requested."}],"usage":{"input_tokens":0,"output_tokens":0}}}
+not json
+`
+	require.NoError(t, afero.WriteFile(fs, transcriptPath, []byte(content), 0644))
+
+	usage, err := ParseTranscriptUsage(fs, transcriptPath)
+	require.NoError(t, err)
+	require.Len(t, usage.Messages, 2, "lines of one response count once; synthetic messages are skipped")
+
+	first := usage.Messages[0]
+	assert.Equal(t, "msg_1", first.MessageID)
+	assert.Equal(t, "2024-01-15T10:30:00Z", first.Timestamp)
+	assert.Equal(t, "claude-sonnet-4-5-20250929", first.Model)
+	assert.Equal(t, 10, first.InputTokens)
+	assert.Equal(t, 40, first.OutputTokens, "final output count wins")
+	assert.Equal(t, 1000, first.CacheCreationTokens)
+	assert.Equal(t, 2000, first.CacheReadTokens)
+	assert.Equal(t, map[string]int{"Read": 1}, first.ToolUses)
+	assert.Empty(t, first.AgentID)
+
+	assert.Equal(t, map[string]int{"Bash": 2}, usage.Messages[1].ToolUses)
+}
+
+func TestParseTranscriptUsage_SubagentTypesAndSidechains(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	transcriptPath := "/test/transcript.jsonl"
+
+	content := `{"type":"assistant","timestamp":"2024-01-15T10:30:00Z","message":{"id":"msg_1","model":"claude-sonnet-4-5","content":[{"type":"tool_use","id":"toolu_task","name":"Task","input":{"subagent_type":"researcher","prompt":"look"}}],"usage":{"input_tokens":5,"output_tokens":5}}}
+{"type":"assistant","timestamp":"2024-01-15T10:30:30Z","isSidechain":true,"agentId":"a1b2c3","message":{"id":"msg_2","model":"claude-haiku-4-5","content":[{"type":"tool_use","id":"toolu_grep","name":"Grep","input":{}}],"usage":{"input_tokens":7,"output_tokens":3}}}
+{"type":"user","timestamp":"2024-01-15T10:31:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_task","content":"done"}]},"toolUseResult":{"status":"completed","agentId":"a1b2c3","content":[{"type":"text","text":"done"}]}}
+`
+	require.NoError(t, afero.WriteFile(fs, transcriptPath, []byte(content), 0644))
+
+	usage, err := ParseTranscriptUsage(fs, transcriptPath)
+	require.NoError(t, err)
+	require.Len(t, usage.Messages, 2)
+
+	assert.Equal(t, map[string]int{"Task": 1}, usage.Messages[0].ToolUses)
+	assert.Equal(t, "a1b2c3", usage.Messages[1].AgentID)
+	assert.Equal(t, map[string]string{"a1b2c3": "researcher"}, usage.AgentTypes)
+}
+
+func TestParseTranscriptUsage_MissingFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_, err := ParseTranscriptUsage(fs, "/test/missing.jsonl")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open transcript")
+}
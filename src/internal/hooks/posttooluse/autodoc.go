@@ -8,6 +8,8 @@ import (
 	"claudex/internal/doc"
 	"claudex/internal/hooks/shared"
 	"claudex/internal/services/env"
+	"claudex/internal/services/models"
+	"claudex/internal/services/paths"
 	"claudex/internal/services/session"
 
 	"github.com/spf13/afero"
@@ -72,15 +74,9 @@ func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOut
 		startLine = 0 // Start from beginning if we can't read the marker
 	}
 
-	// Find project root to build absolute template path
-	projectRoot, err := h.findProjectRoot(sessionPath)
-	if err != nil {
-		_ = h.logger.LogError(fmt.Errorf("failed to find project root: %w", err))
-		return h.allowOutput(), nil
-	}
-
-	// Build absolute path to template
-	templatePath := filepath.Join(projectRoot, ".claude", "hooks", "prompts", "session-overview-documenter.md")
+	// Build absolute path to template from the project root
+	projectRoot := paths.FindProjectRoot(h.fs, sessionPath)
+	templatePath := filepath.Join(projectRoot, paths.PromptsDir, "session-overview-documenter.md")
 
 	// Read existing session context
 	sessionContext, err := h.readSessionContext(sessionPath)
@@ -96,7 +92,7 @@ func (h *AutoDocHandler) Handle(input *shared.PostToolUseInput) (*shared.HookOut
 		OutputFile:     "session-overview.md",
 		PromptTemplate: templatePath,
 		SessionContext: sessionContext,
-		Model:          "haiku",
+		Model:          models.FromEnv(h.env).Model(models.TaskOverview),
 		StartLine:      startLine + 1, // Start from next line (1-indexed)
 	}
 
@@ -118,25 +114,6 @@ func (h *AutoDocHandler) allowOutput() *shared.HookOutput {
 	}
 }
 
-// findProjectRoot walks up from sessionPath to find the project root (where .claude directory exists)
-func (h *AutoDocHandler) findProjectRoot(sessionPath string) (string, error) {
-	current := sessionPath
-	for {
-		claudeDir := filepath.Join(current, ".claude")
-		exists, err := afero.DirExists(h.fs, claudeDir)
-		if err == nil && exists {
-			return current, nil
-		}
-
-		parent := filepath.Dir(current)
-		if parent == current {
-			// Reached filesystem root
-			return "", fmt.Errorf("could not find .claude directory in any parent of %s", sessionPath)
-		}
-		current = parent
-	}
-}
-
 // readSessionContext reads existing markdown files from session folder and builds context string
 func (h *AutoDocHandler) readSessionContext(sessionPath string) (string, error) {
 	files, err := afero.ReadDir(h.fs, sessionPath)
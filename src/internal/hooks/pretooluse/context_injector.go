@@ -10,6 +10,7 @@ import (
 
 	"claudex"
 	"claudex/internal/hooks/shared"
+	"claudex/internal/services/paths"
 	"claudex/internal/services/session"
 	"claudex/internal/services/stackdetect"
 
@@ -120,7 +121,7 @@ func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, err
 		}
 
 		// Detect tech stacks
-		stacks := stackdetect.Detect(h.fs, input.CWD)
+		stacks := stackdetect.Detect(h.fs, projectRoot(h.fs, input.CWD))
 
 		planContext := h.buildPlanContext(stacks)
 		modifiedPrompt := fmt.Sprintf("%s\n\n---\n\n## ORIGINAL REQUEST\n\n%s", planContext, originalPrompt)
@@ -148,7 +149,7 @@ func (h *Handler) Handle(input *shared.PreToolUseInput) (*shared.HookOutput, err
 	}
 
 	// Build session context
-	sessionContext, err := h.buildSessionContext(sessionPath, docPaths, input.CWD)
+	sessionContext, err := h.buildSessionContext(sessionPath, docPaths, projectRoot(h.fs, input.CWD))
 	if err != nil {
 		if h.logger != nil {
 			_ = h.logger.LogError(fmt.Errorf("failed to build session context: %w", err))
@@ -281,6 +282,15 @@ func (h *Handler) listSessionFiles(sessionPath string) ([]string, error) {
 	return files, nil
 }
 
+// projectRoot resolves the project root from the cwd Claude reports, which may
+// be a subdirectory the agent changed into
+func projectRoot(fsys afero.Fs, cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	return paths.FindProjectRoot(fsys, cwd)
+}
+
 // hasIndexMdFiles checks if any index.md files exist in the project directory tree
 func (h *Handler) hasIndexMdFiles(projectRoot string) bool {
 	// Empty project root - graceful degradation
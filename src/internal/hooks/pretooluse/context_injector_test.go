@@ -1,6 +1,8 @@
 package pretooluse
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -285,9 +287,11 @@ func TestHandler_PatternMatchSessionFolder(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	env := shared.NewMockEnv()
 
-	// Create session folder with pattern-based name
-	sessionPath := "./.claudex/sessions/golang-hooks-rewrite-abc123"
-	err := fs.MkdirAll(sessionPath, 0755)
+	// Create session folder with pattern-based name in the working directory
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	sessionPath := filepath.Join(wd, ".claudex/sessions/golang-hooks-rewrite-abc123")
+	err = fs.MkdirAll(sessionPath, 0755)
 	require.NoError(t, err)
 
 	// Create a file in the session
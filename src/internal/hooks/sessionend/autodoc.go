@@ -2,10 +2,13 @@ package sessionend
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"claudex/internal/doc"
 	"claudex/internal/hooks/shared"
 	"claudex/internal/services/env"
+	"claudex/internal/services/models"
+	"claudex/internal/services/paths"
 	"claudex/internal/services/session"
 
 	"github.com/spf13/afero"
@@ -51,14 +54,17 @@ func (h *Handler) Handle(input *shared.SessionEndInput) error {
 		startLine = 0 // Start from beginning if we can't read the marker
 	}
 
+	// The template lives in the project's .claude/, found from the session folder
+	templatePath := filepath.Join(paths.FindProjectRoot(h.fs, sessionPath), paths.PromptsDir, "session-overview-documenter.md")
+
 	// Trigger documentation update (background, non-blocking)
 	// This is the final update, so we always run it
 	config := doc.UpdaterConfig{
 		SessionPath:    sessionPath,
 		TranscriptPath: input.TranscriptPath,
 		OutputFile:     "session-overview.md",
-		PromptTemplate: "session-overview-documenter.md",
-		Model:          "haiku",
+		PromptTemplate: templatePath,
+		Model:          models.FromEnv(h.env).Model(models.TaskOverview),
 		StartLine:      startLine + 1, // Start from next line (1-indexed)
 	}
 
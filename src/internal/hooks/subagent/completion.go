@@ -2,11 +2,14 @@ package subagent
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"claudex/internal/doc"
 	"claudex/internal/hooks/shared"
 	"claudex/internal/notify"
 	"claudex/internal/services/env"
+	"claudex/internal/services/models"
+	"claudex/internal/services/paths"
 	"claudex/internal/services/session"
 
 	"github.com/spf13/afero"
@@ -66,13 +69,16 @@ func (h *Handler) Handle(input *shared.SubagentStopInput) (*shared.HookOutput, e
 		startLine = 0 // Start from beginning if we can't read the marker
 	}
 
+	// The template lives in the project's .claude/, found from the session folder
+	templatePath := filepath.Join(paths.FindProjectRoot(h.fs, sessionPath), paths.PromptsDir, "session-overview-documenter.md")
+
 	// Trigger documentation update (background, non-blocking)
 	config := doc.UpdaterConfig{
 		SessionPath:    sessionPath,
 		TranscriptPath: input.TranscriptPath,
 		OutputFile:     "session-overview.md",
-		PromptTemplate: "session-overview-documenter.md",
-		Model:          "haiku",
+		PromptTemplate: templatePath,
+		Model:          models.FromEnv(h.env).Model(models.TaskOverview),
 		StartLine:      startLine + 1, // Start from next line (1-indexed)
 	}
 
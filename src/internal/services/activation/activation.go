@@ -0,0 +1,144 @@
+// Package activation renders the prompt that starts a Claude session: the
+// entry agent activation and the documentation it must read first. Prompts
+// are Go text/template files looked up by name in the project and user
+// template directories, with the original prompt embedded as "default".
+package activation
+
+import (
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"claudex/internal/services/git"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultTemplate is used when neither the session nor the config names one
+const DefaultTemplate = "default"
+
+// Ext is the file extension of template files
+const Ext = ".tmpl"
+
+//go:embed default.tmpl
+var defaultTemplate string
+
+// Data holds the variables available to a template
+type Data struct {
+	Agent       string   // Entry agent, e.g. team-lead
+	SessionName string   // Session folder name
+	SessionPath string   // Session folder relative to the project root
+	Mode        string   // new, fork or fresh
+	Parent      string   // Session forked or refreshed from (fork and fresh only)
+	Description string   // Session description
+	Docs        []string // Absolute paths of the required documentation
+}
+
+// view is what templates execute against; {{.Branch}} only runs git when a
+// template uses it
+type view struct {
+	Data
+	git git.GitService
+}
+
+// Branch returns the current git branch, or "" outside a repository
+func (v view) Branch() string {
+	if v.git == nil {
+		return ""
+	}
+	branch, err := v.git.GetCurrentBranch()
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
+// Service renders activation prompts
+type Service interface {
+	// Render executes the named template. An empty name uses DefaultTemplate.
+	Render(name string, data Data) (string, error)
+
+	// Names lists the available templates
+	Names() []string
+
+	// Exists reports whether a template can be rendered
+	Exists(name string) bool
+}
+
+type service struct {
+	fs     afero.Fs
+	dirs   []string
+	gitSvc git.GitService
+}
+
+// New creates a Service that looks templates up in dirs, first match wins
+func New(fs afero.Fs, gitSvc git.GitService, dirs ...string) Service {
+	return &service{fs: fs, dirs: dirs, gitSvc: gitSvc}
+}
+
+// Render executes the named template against data
+func (s *service) Render(name string, data Data) (string, error) {
+	if name == "" {
+		name = DefaultTemplate
+	}
+	text, path, err := s.load(name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, view{Data: data, git: s.gitSvc}); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// load returns the text of the named template and where it came from
+func (s *service) load(name string) (string, string, error) {
+	for _, dir := range s.dirs {
+		path := filepath.Join(dir, name+Ext)
+		if data, err := afero.ReadFile(s.fs, path); err == nil {
+			return string(data), path, nil
+		}
+	}
+	if name == DefaultTemplate {
+		return defaultTemplate, "embedded default template", nil
+	}
+	return "", "", fmt.Errorf("unknown activation template %q; available: %s", name, strings.Join(s.Names(), ", "))
+}
+
+// Names lists the embedded default and every template in the directories
+func (s *service) Names() []string {
+	set := map[string]bool{DefaultTemplate: true}
+	for _, dir := range s.dirs {
+		entries, err := afero.ReadDir(s.fs, dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), Ext) {
+				set[strings.TrimSuffix(entry.Name(), Ext)] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Exists reports whether name is the default or a template file
+func (s *service) Exists(name string) bool {
+	_, _, err := s.load(name)
+	return err == nil
+}
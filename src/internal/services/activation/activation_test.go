@@ -0,0 +1,121 @@
+package activation
+
+import (
+	"testing"
+
+	"claudex/internal/services/git"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	projectDir = "/project/.claudex/templates"
+	userDir    = "/home/user/.config/claudex/templates"
+)
+
+// TestRender_Default verifies the embedded template reproduces the original
+// activation prompt
+func TestRender_Default(t *testing.T) {
+	h := testutil.NewTestHarness()
+	svc := New(h.FS, nil, projectDir, userDir)
+
+	prompt, err := svc.Render("", Data{Agent: "team-lead", SessionPath: ".claudex/sessions/auth-uuid"})
+	require.NoError(t, err)
+	assert.Equal(t, "/agents:team-lead activate in session .claudex/sessions/auth-uuid", prompt)
+
+	prompt, err = svc.Render(DefaultTemplate, Data{
+		Agent:       "architect",
+		SessionPath: ".claudex/sessions/auth-uuid",
+		Docs:        []string{"/project/docs/index.md", "/project/README.md"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `/agents:architect activate in session .claudex/sessions/auth-uuid
+
+IMPORTANT - Required Documentation:
+Before proceeding, you MUST read these documentation files:
+- /project/docs/index.md
+- /project/README.md`, prompt)
+}
+
+// TestRender_ProjectOverridesUser verifies the project template wins over the
+// user template of the same name
+func TestRender_ProjectOverridesUser(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile(userDir+"/bugfix.tmpl", "user bugfix")
+	h.WriteFile(userDir+"/feature.tmpl", "user feature")
+	h.WriteFile(projectDir+"/bugfix.tmpl", "project bugfix")
+	svc := New(h.FS, nil, projectDir, userDir)
+
+	prompt, err := svc.Render("bugfix", Data{})
+	require.NoError(t, err)
+	assert.Equal(t, "project bugfix", prompt)
+
+	prompt, err = svc.Render("feature", Data{})
+	require.NoError(t, err)
+	assert.Equal(t, "user feature", prompt)
+
+	assert.Equal(t, []string{"bugfix", "default", "feature"}, svc.Names())
+}
+
+// TestRender_Variables verifies every variable reaches the template and the
+// branch comes from git
+func TestRender_Variables(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Commander.OnPattern("git", "rev-parse", "--abbrev-ref", "HEAD").Return([]byte("fix/login\n"), nil)
+	h.WriteFile(projectDir+"/bugfix.tmpl", `/agents:{{.Agent}} activate in session {{.SessionPath}}
+Mode: {{.Mode}}{{if .Parent}} from {{.Parent}}{{end}}
+Branch: {{.Branch}}
+Bug: {{.Description}} ({{.SessionName}})
+{{range .Docs}}Read {{.}}
+{{end}}`)
+	svc := New(h.FS, git.New(h.Commander), projectDir)
+
+	prompt, err := svc.Render("bugfix", Data{
+		Agent:       "researcher",
+		SessionName: "login-fails-uuid",
+		SessionPath: ".claudex/sessions/login-fails-uuid",
+		Mode:        "fork",
+		Parent:      "login-uuid",
+		Description: "Login fails on Safari",
+		Docs:        []string{"/project/docs/auth.md"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `/agents:researcher activate in session .claudex/sessions/login-fails-uuid
+Mode: fork from login-uuid
+Branch: fix/login
+Bug: Login fails on Safari (login-fails-uuid)
+Read /project/docs/auth.md`, prompt)
+}
+
+// TestRender_BranchOnlyRunsGitWhenUsed verifies templates without
+// {{.Branch}} don't run git
+func TestRender_BranchOnlyRunsGitWhenUsed(t *testing.T) {
+	h := testutil.NewTestHarness()
+	svc := New(h.FS, git.New(h.Commander))
+
+	_, err := svc.Render("", Data{Agent: "team-lead"})
+	require.NoError(t, err)
+	assert.Empty(t, h.Commander.Invocations)
+}
+
+func TestRender_Errors(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile(projectDir+"/broken.tmpl", "{{.Agent")
+	h.WriteFile(projectDir+"/typo.tmpl", "{{.Agnet}}")
+	svc := New(h.FS, nil, projectDir)
+
+	_, err := svc.Render("missing", Data{})
+	assert.EqualError(t, err, `unknown activation template "missing"; available: broken, default, typo`)
+	assert.False(t, svc.Exists("missing"))
+	assert.True(t, svc.Exists("typo"))
+
+	_, err = svc.Render("broken", Data{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse "+projectDir+"/broken.tmpl")
+
+	_, err = svc.Render("typo", Data{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to render "+projectDir+"/typo.tmpl")
+}
@@ -8,19 +8,19 @@ import (
 	"path/filepath"
 	"strings"
 
-	"claudex"
 	"claudex/internal/services/config"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/mcpconfig"
+	"claudex/internal/services/models"
 	"claudex/internal/services/paths"
-	"claudex/internal/services/profile"
 	"claudex/internal/services/session"
-	createindexuc "claudex/internal/usecases/createindex"
+	"claudex/internal/services/terminal"
+	"claudex/internal/ui"
 	migrateuc "claudex/internal/usecases/migrate"
 	setupuc "claudex/internal/usecases/setup"
 	setuphookuc "claudex/internal/usecases/setuphook"
 	setupmcpuc "claudex/internal/usecases/setupmcp"
 	updatecheckuc "claudex/internal/usecases/updatecheck"
-	updatedocsuc "claudex/internal/usecases/updatedocs"
 	"github.com/spf13/afero"
 )
 
@@ -50,11 +50,13 @@ type App struct {
 	cfg             *config.Config
 	projectDir      string
 	sessionsDir     string
+	archiveDir      string
 	docPaths        []string
 	noOverwrite     bool
 	updateDocs      bool
 	setupMCP        bool
 	createIndex     string
+	term            terminal.Mode
 	logFile         afero.File
 	logFilePath     string
 	version         string
@@ -64,6 +66,7 @@ type App struct {
 	setupMCPFlag    *bool
 	createIndexFlag *string
 	docPathsFlag    []string
+	claudeArgs      []string // Arguments after --, passed through to Claude
 }
 
 // New creates a new App instance with production dependencies
@@ -80,50 +83,96 @@ func New(version string, showVersion *bool, noOverwrite *bool, updateDocs *bool,
 	}
 }
 
+// SetClaudeArgs sets the arguments given after -- on the command line. They
+// are passed to Claude and stored with the session so resume, fork and fresh
+// reapply them.
+func (a *App) SetClaudeArgs(args []string) {
+	a.claudeArgs = args
+}
+
 // Init initializes the application (parse flags, load config, setup logging)
 func (a *App) Init() error {
+	// Resolve the project root so a subdirectory never gets its own .claudex/
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	projectDir := paths.FindProjectRoot(a.deps.FS, cwd)
+
 	// Run migration to ensure .claudex/ folder exists and migrate legacy artifacts
-	migrator := migrateuc.New(a.deps.FS)
+	migrator := migrateuc.New(a.deps.FS, projectDir)
 	if err := migrator.Run(); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
-	// Load config file from new location (after migration)
-	cfg, err := config.Load(a.deps.FS, paths.ConfigFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
-		cfg = &config.Config{Doc: []string{}, NoOverwrite: false}
+	// main parses the global flags before dispatching subcommands
+	if !flag.Parsed() {
+		flag.Parse()
 	}
-	a.cfg = cfg
-
-	flag.Parse()
+	a.term = terminal.Detect(a.deps.Env)
 
 	if *a.showVersion {
 		fmt.Printf("claudex %s\n", a.version)
 		os.Exit(0)
 	}
 
-	// Apply precedence: CLI flags > config > defaults
-	if !isFlagSet("doc") && len(cfg.Doc) > 0 {
-		a.docPaths = cfg.Doc
-	} else {
-		a.docPaths = a.docPathsFlag
+	// Load config layers: defaults < user < project < env < flags
+	layered, err := config.LoadLayered(a.deps.FS, config.Sources{
+		UserPath:    config.UserPath(a.deps.Env),
+		ProjectPath: filepath.Join(projectDir, paths.ConfigFile),
+		Env:         a.deps.Env,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
+		layered, _ = config.LoadLayered(a.deps.FS, config.Sources{Env: a.deps.Env})
+	}
+	if isFlagSet("doc") {
+		// --doc paths are relative to where the command was typed, config
+		// paths to the project root
+		docPaths := make([]string, len(a.docPathsFlag))
+		for i, docPath := range a.docPathsFlag {
+			if !filepath.IsAbs(docPath) {
+				docPath = filepath.Join(cwd, docPath)
+			}
+			docPaths[i] = docPath
+		}
+		layered.ApplyFlag("doc", "doc", docPaths)
 	}
-	if !isFlagSet("no-overwrite") && cfg.NoOverwrite {
-		a.noOverwrite = cfg.NoOverwrite
-	} else {
-		a.noOverwrite = *a.noOverwriteFlag
+	if isFlagSet("no-overwrite") {
+		layered.ApplyFlag("no_overwrite", "no-overwrite", *a.noOverwriteFlag)
+	}
+	for _, problem := range layered.Validate(a.deps.FS, projectDir) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", problem)
+	}
+	cfg := layered.Config
+	a.cfg = cfg
+	a.docPaths = make([]string, len(cfg.Doc))
+	for i, docPath := range cfg.Doc {
+		if !filepath.IsAbs(docPath) {
+			docPath = filepath.Join(projectDir, docPath)
+		}
+		a.docPaths[i] = docPath
 	}
+	a.noOverwrite = cfg.NoOverwrite
+	a.setLLMEnvironment(cfg)
+	models.New(cfg.Models, a.deps.Env).Export()
+
+	// Arguments after -- are relative to where the command was typed too
+	a.claudeArgs = absClaudeArgs(a.claudeArgs, cwd)
+
 	a.updateDocs = *a.updateDocsFlag
 	a.setupMCP = *a.setupMCPFlag
 	a.createIndex = *a.createIndexFlag
-
-	projectDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
+	if a.createIndex != "" && !filepath.IsAbs(a.createIndex) {
+		a.createIndex = filepath.Join(cwd, a.createIndex)
 	}
+
+	// Claude is launched in the project root (see launchClaude), so its
+	// project settings and --resume find the same conversations from any
+	// subdirectory
 	a.projectDir = projectDir
 	a.sessionsDir = filepath.Join(projectDir, paths.SessionsDir)
+	a.archiveDir = filepath.Join(projectDir, paths.ArchiveDir)
 
 	// Ensure .claude directory is set up using setup usecase
 	setupUC := setupuc.New(a.deps.FS, a.deps.Env)
@@ -165,9 +214,18 @@ func (a *App) Init() error {
 		return fmt.Errorf("failed to create sessions directory: %w", err)
 	}
 
+	if cfg.Retention.GCOnStartup {
+		a.startupGC()
+	}
+
 	return nil
 }
 
+// jobQueue returns the project's background job registry
+func (a *App) jobQueue() jobs.Service {
+	return jobs.New(a.deps.FS, filepath.Join(a.projectDir, paths.JobsDir), a.deps.Clock, a.deps.UUID)
+}
+
 // Close cleans up resources (close log file)
 func (a *App) Close() {
 	if a.logFile != nil {
@@ -234,51 +292,37 @@ func (a *App) renameLogFileForSession(si SessionInfo) {
 
 // Run executes the main application logic
 func (a *App) Run() error {
-	// Check if Claude CLI is installed
-	if !a.isClaudeInstalled() {
-		fmt.Println("\n❌ Claude Code CLI not found")
-		fmt.Println("\nClaudex requires Claude Code CLI to be installed.")
-		fmt.Println("\n⚠️  Note: Claude Code requires a Claude Pro ($20/mo), Max ($100/mo),")
-		fmt.Println("   or Team subscription. The free tier does not include Claude Code.")
-		fmt.Print("\nInstall Claude Code now? [y/n]: ")
-
-		var response string
-		fmt.Scanln(&response)
-
-		switch strings.ToLower(strings.TrimSpace(response)) {
-		case "y", "yes":
-			fmt.Println("\nInstalling Claude Code CLI...")
-			if err := a.deps.Cmd.Start("npm", os.Stdin, os.Stdout, os.Stderr, "install", "-g", "@anthropic-ai/claude-code"); err != nil {
-				fmt.Fprintf(os.Stderr, "\nInstallation failed: %v\n", err)
-				fmt.Println("You can install manually with: npm install -g @anthropic-ai/claude-code")
-				return fmt.Errorf("failed to install claude CLI")
-			}
-			fmt.Println("\n✓ Claude Code CLI installed successfully!")
-		default:
-			fmt.Println("\nYou can install manually with: npm install -g @anthropic-ai/claude-code")
-			fmt.Println("More info: https://docs.anthropic.com/en/docs/claude-code")
-			return fmt.Errorf("claude CLI not installed")
-		}
-	}
-
 	// Early exit for --update-docs mode
 	if a.updateDocs {
-		uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
-		return uc.Execute(a.projectDir)
+		return a.UpdateDocs()
 	}
 
 	// Early exit for --create-index mode
 	if a.createIndex != "" {
-		uc := createindexuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
-		return uc.Execute(a.createIndex)
+		return a.CreateIndex(a.createIndex)
+	}
+
+	if err := a.ensureClaudeInstalled(); err != nil {
+		return err
 	}
 
 	// Early exit for --setup-mcp mode
 	if a.setupMCP {
+		if !a.term.CanPrompt() {
+			return a.installMCPs()
+		}
 		a.promptMCPSetup()
 		return nil
 	}
 
+	// The session selector needs someone at the keyboard
+	if !a.term.CanPrompt() {
+		return fmt.Errorf("no interactive terminal to choose a session; name one explicitly:\n" +
+			"  claudex session new \"<description>\"\n" +
+			"  claudex resume|fork|fresh <name>\n" +
+			"  claudex session list")
+	}
+
 	// Check for updates first (before other prompts)
 	a.promptUpdateCheck()
 
@@ -288,12 +332,6 @@ func (a *App) Run() error {
 	// Check if user wants to configure recommended MCPs
 	a.promptMCPSetup()
 
-	// Load team-lead profile directly (skip profile selection menu)
-	_, err := profile.LoadComposed(claudex.Profiles, "team-lead")
-	if err != nil {
-		return fmt.Errorf("failed to load profile: %w", err)
-	}
-
 	// Show session selector TUI
 	fm, err := a.showSessionSelector()
 	if err != nil {
@@ -314,6 +352,14 @@ func (a *App) Run() error {
 			Path: fm.SessionPath,
 			Mode: LaunchModeEphemeral,
 		}
+	case "archived":
+		// Restore the session, then offer the same choices as for any other
+		fm.SessionPath, err = a.UnarchiveSession(fm.SessionName)
+		if err != nil {
+			return err
+		}
+		ui.ShowSessionRestored(fm.SessionName)
+		fallthrough
 	case "session":
 		// Check if selected session has a Claude session ID (for resume/fork choice)
 		if session.HasClaudeSessionID(fm.SessionName) {
@@ -339,6 +385,12 @@ func (a *App) Run() error {
 		return err
 	}
 
+	return a.start(si)
+}
+
+// start associates the log file with the session, exports the session
+// environment and launches Claude
+func (a *App) start(si SessionInfo) error {
 	// Rename log file to match session (skip for ephemeral)
 	a.renameLogFileForSession(si)
 
@@ -347,8 +399,51 @@ func (a *App) Run() error {
 	return a.launch(si)
 }
 
+// ensureClaudeInstalled checks for the Claude CLI and offers to install it
+func (a *App) ensureClaudeInstalled() error {
+	if a.isClaudeInstalled() {
+		return nil
+	}
+
+	fmt.Println("\n❌ Claude Code CLI not found")
+	fmt.Println("\nClaudex requires Claude Code CLI to be installed.")
+	fmt.Println("\n⚠️  Note: Claude Code requires a Claude Pro ($20/mo), Max ($100/mo),")
+	fmt.Println("   or Team subscription. The free tier does not include Claude Code.")
+
+	if !a.term.CanPrompt() {
+		fmt.Println("\nInstall it with: npm install -g @anthropic-ai/claude-code")
+		return fmt.Errorf("claude CLI not installed")
+	}
+
+	fmt.Print("\nInstall Claude Code now? [y/n]: ")
+
+	var response string
+	fmt.Scanln(&response)
+
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "y", "yes":
+		fmt.Println("\nInstalling Claude Code CLI...")
+		if err := a.deps.Cmd.Start("npm", os.Stdin, os.Stdout, os.Stderr, "install", "-g", "@anthropic-ai/claude-code"); err != nil {
+			fmt.Fprintf(os.Stderr, "\nInstallation failed: %v\n", err)
+			fmt.Println("You can install manually with: npm install -g @anthropic-ai/claude-code")
+			return fmt.Errorf("failed to install claude CLI")
+		}
+		fmt.Println("\n✓ Claude Code CLI installed successfully!")
+	default:
+		fmt.Println("\nYou can install manually with: npm install -g @anthropic-ai/claude-code")
+		fmt.Println("More info: https://docs.anthropic.com/en/docs/claude-code")
+		return fmt.Errorf("claude CLI not installed")
+	}
+	return nil
+}
+
 // promptHookSetup checks if we should offer git hook integration
 func (a *App) promptHookSetup() {
+	// Optional prompts are skipped when nobody can answer them
+	if !a.term.CanPrompt() {
+		return
+	}
+
 	uc := setuphookuc.New(a.deps.FS, a.projectDir, a.deps.Cmd)
 
 	result := uc.ShouldPrompt()
@@ -382,6 +477,10 @@ func (a *App) promptHookSetup() {
 
 // promptMCPSetup checks if we should offer MCP configuration
 func (a *App) promptMCPSetup() {
+	if !a.term.CanPrompt() {
+		return
+	}
+
 	uc := setupmcpuc.New(a.deps.FS)
 
 	result := uc.ShouldPrompt()
@@ -425,8 +524,24 @@ func (a *App) promptMCPSetup() {
 	fmt.Println()
 }
 
+// installMCPs configures the recommended MCPs without prompting, for
+// --setup-mcp outside an interactive terminal
+func (a *App) installMCPs() error {
+	uc := setupmcpuc.New(a.deps.FS)
+	if err := uc.Install(""); err != nil {
+		return fmt.Errorf("could not configure MCPs: %w", err)
+	}
+	fmt.Println("✓ MCP configuration added to ~/.claude.json")
+	fmt.Println("  Note: Context7 running in rate-limited mode; add a token with: claudex mcp setup --token <key>")
+	return nil
+}
+
 // promptUpdateCheck checks if we should offer to update claudex
 func (a *App) promptUpdateCheck() {
+	if !a.term.CanPrompt() {
+		return
+	}
+
 	uc := updatecheckuc.New(a.deps.FS, a.version)
 
 	result := uc.ShouldPrompt()
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -404,6 +405,7 @@ func TestRenameLogFileForSession_ForkMode(t *testing.T) {
 func TestInit_CreatesClaudexDirectory(t *testing.T) {
 	// Setup - fresh filesystem
 	h := testutil.NewTestHarness()
+	projectDir := workingDir(t)
 
 	// Create app with mocked dependencies
 	showVersion := false
@@ -427,22 +429,22 @@ func TestInit_CreatesClaudexDirectory(t *testing.T) {
 	// Mock environment variables
 	h.Env.Set("HOME", "/home/user")
 
-	// Execute Init (migration uses relative paths like .claudex, which work with MemMapFs)
+	// Execute Init
 	err := app.Init()
 	require.NoError(t, err, "Init should succeed on fresh filesystem")
 
 	// Assert: .claudex/ directory created (using paths.ClaudexDir constant)
-	exists, err := afero.DirExists(h.FS, ".claudex")
+	exists, err := afero.DirExists(h.FS, filepath.Join(projectDir, ".claudex"))
 	require.NoError(t, err)
 	assert.True(t, exists, ".claudex/ directory should be created")
 
 	// Assert: config.toml created with defaults
-	exists, err = afero.Exists(h.FS, ".claudex/config.toml")
+	exists, err = afero.Exists(h.FS, filepath.Join(projectDir, ".claudex/config.toml"))
 	require.NoError(t, err)
 	assert.True(t, exists, ".claudex/config.toml should be created")
 
 	// Assert: config content contains defaults
-	content, err := afero.ReadFile(h.FS, ".claudex/config.toml")
+	content, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/config.toml"))
 	require.NoError(t, err)
 	assert.Contains(t, string(content), "autodoc_session_progress", "config should contain default settings")
 
@@ -452,8 +454,8 @@ func TestInit_CreatesClaudexDirectory(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, exists, "sessions directory should be created")
 
-	// Assert: logs directory created (path includes projectDir from os.Getwd())
-	projectDir := app.projectDir
+	// Assert: logs directory created under the project root
+	assert.Equal(t, projectDir, app.projectDir)
 	logsDir := filepath.Join(projectDir, ".claudex/logs")
 	exists, err = afero.DirExists(h.FS, logsDir)
 	require.NoError(t, err)
@@ -473,10 +475,11 @@ func TestInit_CreatesClaudexDirectory(t *testing.T) {
 func TestInit_MigratesLegacySessions(t *testing.T) {
 	// Setup - create legacy sessions/
 	h := testutil.NewTestHarness()
+	projectDir := workingDir(t)
 
 	// Create legacy sessions directory with content
-	h.WriteFile("sessions/session-1/conversation.md", "# Session 1 content")
-	h.WriteFile("sessions/session-2/conversation.md", "# Session 2 content")
+	h.WriteFile(filepath.Join(projectDir, "sessions/session-1/conversation.md"), "# Session 1 content")
+	h.WriteFile(filepath.Join(projectDir, "sessions/session-2/conversation.md"), "# Session 2 content")
 
 	// Create app
 	showVersion := false
@@ -504,21 +507,21 @@ func TestInit_MigratesLegacySessions(t *testing.T) {
 	require.NoError(t, err, "Init should succeed with legacy sessions")
 
 	// Assert: Sessions migrated to new location
-	exists, err := afero.Exists(h.FS, ".claudex/sessions/session-1/conversation.md")
+	exists, err := afero.Exists(h.FS, filepath.Join(projectDir, ".claudex/sessions/session-1/conversation.md"))
 	require.NoError(t, err)
 	assert.True(t, exists, "session-1 should be migrated to .claudex/sessions/")
 
-	exists, err = afero.Exists(h.FS, ".claudex/sessions/session-2/conversation.md")
+	exists, err = afero.Exists(h.FS, filepath.Join(projectDir, ".claudex/sessions/session-2/conversation.md"))
 	require.NoError(t, err)
 	assert.True(t, exists, "session-2 should be migrated to .claudex/sessions/")
 
 	// Assert: Content preserved
-	content, err := afero.ReadFile(h.FS, ".claudex/sessions/session-1/conversation.md")
+	content, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/sessions/session-1/conversation.md"))
 	require.NoError(t, err)
 	assert.Equal(t, "# Session 1 content", string(content), "session content should be preserved")
 
 	// Assert: Old sessions/ directory removed
-	exists, err = afero.DirExists(h.FS, "sessions")
+	exists, err = afero.DirExists(h.FS, filepath.Join(projectDir, "sessions"))
 	require.NoError(t, err)
 	assert.False(t, exists, "legacy sessions/ directory should be removed after migration")
 }
@@ -530,6 +533,7 @@ func TestInit_MigratesLegacySessions(t *testing.T) {
 func TestInit_MigratesLegacyConfig(t *testing.T) {
 	// Setup - create legacy config
 	h := testutil.NewTestHarness()
+	projectDir := workingDir(t)
 
 	// Create legacy config with custom values
 	legacyConfig := `# Legacy config
@@ -540,7 +544,7 @@ autodoc_frequency = 10
 
 doc = ["/custom/path"]`
 
-	h.WriteFile(".claudex.toml", legacyConfig)
+	h.WriteFile(filepath.Join(projectDir, ".claudex.toml"), legacyConfig)
 
 	// Create app
 	showVersion := false
@@ -568,18 +572,18 @@ doc = ["/custom/path"]`
 	require.NoError(t, err, "Init should succeed with legacy config")
 
 	// Assert: Config migrated to new location
-	exists, err := afero.Exists(h.FS, ".claudex/config.toml")
+	exists, err := afero.Exists(h.FS, filepath.Join(projectDir, ".claudex/config.toml"))
 	require.NoError(t, err)
 	assert.True(t, exists, "config should be migrated to .claudex/config.toml")
 
 	// Assert: Custom values preserved
-	content, err := afero.ReadFile(h.FS, ".claudex/config.toml")
+	content, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/config.toml"))
 	require.NoError(t, err)
 	assert.Contains(t, string(content), "autodoc_frequency = 10", "custom config values should be preserved")
 	assert.Contains(t, string(content), "/custom/path", "custom doc paths should be preserved")
 
 	// Assert: Old .claudex.toml removed
-	exists, err = afero.Exists(h.FS, ".claudex.toml")
+	exists, err = afero.Exists(h.FS, filepath.Join(projectDir, ".claudex.toml"))
 	require.NoError(t, err)
 	assert.False(t, exists, "legacy .claudex.toml should be removed after migration")
 
@@ -594,6 +598,7 @@ doc = ["/custom/path"]`
 func TestInit_LoadsConfigFromNewPath(t *testing.T) {
 	// Setup - create .claudex/ with config
 	h := testutil.NewTestHarness()
+	projectDir := workingDir(t)
 
 	// Create config with specific values
 	configContent := `# Test config
@@ -605,7 +610,7 @@ autodoc_session_progress = false
 autodoc_session_end = true
 autodoc_frequency = 20`
 
-	h.WriteFile(".claudex/config.toml", configContent)
+	h.WriteFile(filepath.Join(projectDir, ".claudex/config.toml"), configContent)
 
 	// Create app
 	showVersion := false
@@ -650,10 +655,11 @@ autodoc_frequency = 20`
 func TestInit_MigrationIdempotent(t *testing.T) {
 	// Setup - create .claudex/ with content
 	h := testutil.NewTestHarness()
+	projectDir := workingDir(t)
 
 	// Create existing session
-	h.WriteFile(".claudex/sessions/existing-session/conversation.md", "# Existing content")
-	h.WriteFile(".claudex/config.toml", "# Existing config\n[features]\nautodoc_frequency = 15")
+	h.WriteFile(filepath.Join(projectDir, ".claudex/sessions/existing-session/conversation.md"), "# Existing content")
+	h.WriteFile(filepath.Join(projectDir, ".claudex/config.toml"), "# Existing config\n[features]\nautodoc_frequency = 15")
 
 	// Create app
 	showVersion := false
@@ -681,10 +687,10 @@ func TestInit_MigrationIdempotent(t *testing.T) {
 	require.NoError(t, err, "first Init() should succeed")
 
 	// Read content after first init
-	content1, err := afero.ReadFile(h.FS, ".claudex/sessions/existing-session/conversation.md")
+	content1, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/sessions/existing-session/conversation.md"))
 	require.NoError(t, err)
 
-	config1, err := afero.ReadFile(h.FS, ".claudex/config.toml")
+	config1, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/config.toml"))
 	require.NoError(t, err)
 
 	// Execute Init second time (idempotent)
@@ -692,20 +698,70 @@ func TestInit_MigrationIdempotent(t *testing.T) {
 	require.NoError(t, err, "second Init() should succeed (idempotent)")
 
 	// Assert: Content unchanged
-	content2, err := afero.ReadFile(h.FS, ".claudex/sessions/existing-session/conversation.md")
+	content2, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/sessions/existing-session/conversation.md"))
 	require.NoError(t, err)
 	assert.Equal(t, string(content1), string(content2), "session content should be unchanged")
 
-	config2, err := afero.ReadFile(h.FS, ".claudex/config.toml")
+	config2, err := afero.ReadFile(h.FS, filepath.Join(projectDir, ".claudex/config.toml"))
 	require.NoError(t, err)
 	assert.Equal(t, string(config1), string(config2), "config content should be unchanged")
 
 	// Assert: No duplicate directories or corruption
-	exists, err := afero.DirExists(h.FS, ".claudex")
+	exists, err := afero.DirExists(h.FS, filepath.Join(projectDir, ".claudex"))
 	require.NoError(t, err)
 	assert.True(t, exists, ".claudex should still exist")
 
-	exists, err = afero.DirExists(h.FS, ".claudex/sessions")
+	exists, err = afero.DirExists(h.FS, filepath.Join(projectDir, ".claudex/sessions"))
 	require.NoError(t, err)
 	assert.True(t, exists, ".claudex/sessions should still exist")
 }
+
+// TestInit_UsesProjectRootFromSubdirectory verifies a subdirectory shares the
+// repository's .claudex/ instead of creating its own
+// Given: A .git directory above the working directory
+// When: Init() called
+// Then: .claudex/ is created at the git toplevel, without changing the
+// process working directory
+func TestInit_UsesProjectRootFromSubdirectory(t *testing.T) {
+	h := testutil.NewTestHarness()
+	wd := workingDir(t)
+	root := filepath.Dir(wd)
+	h.CreateDir(filepath.Join(root, ".git"))
+
+	showVersion := false
+	noOverwrite := false
+	updateDocs := false
+	setupMCP := false
+	createIndex := ""
+
+	app := &App{
+		deps:            &Dependencies{FS: h.FS, Cmd: h.Commander, Clock: h, UUID: h, Env: h.Env},
+		version:         "1.0.0",
+		showVersion:     &showVersion,
+		noOverwriteFlag: &noOverwrite,
+		updateDocsFlag:  &updateDocs,
+		setupMCPFlag:    &setupMCP,
+		createIndexFlag: &createIndex,
+		docPathsFlag:    []string{},
+	}
+	h.Env.Set("HOME", "/home/user")
+
+	err := app.Init()
+	require.NoError(t, err)
+
+	assert.Equal(t, root, app.projectDir)
+	assert.Equal(t, filepath.Join(root, ".claudex", "sessions"), app.sessionsDir)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(root, ".claudex"))
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(wd, ".claudex"))
+
+	assert.Equal(t, wd, workingDir(t), "Init should not change the working directory")
+}
+
+// workingDir returns the test's working directory, which is the project root
+// when the in-memory filesystem holds no .claudex/ or .git above it
+func workingDir(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	return wd
+}
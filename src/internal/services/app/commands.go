@@ -0,0 +1,221 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex"
+	"claudex/internal/services/git"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/profile"
+	"claudex/internal/services/session"
+	createindexuc "claudex/internal/usecases/createindex"
+	managesessionsuc "claudex/internal/usecases/managesessions"
+	newuc "claudex/internal/usecases/session/new"
+	forkuc "claudex/internal/usecases/session/resume/fork"
+	freshuc "claudex/internal/usecases/session/resume/fresh"
+	updatedocsuc "claudex/internal/usecases/updatedocs"
+)
+
+// The methods in this file back the scriptable subcommands (`claudex session
+// new|resume|fork|fresh`, `claudex docs ...`). They run the same usecases as
+// the session selector without any TUI; call Init first.
+
+// SessionsDir returns the project's session store
+func (a *App) SessionsDir() string {
+	return a.sessionsDir
+}
+
+// NewSession creates a session from a description. agent selects the entry
+// agent activated on launch; empty uses profile.DefaultAgent. template selects
+// the activation prompt template; empty follows activation.template in the
+// config. tags label the session for search and filters.
+func (a *App) NewSession(description, agent, template string, tags []string) (SessionInfo, error) {
+	if agent != "" && !profile.Exists(claudex.Profiles, agent) {
+		return SessionInfo{}, fmt.Errorf("unknown agent %q; available: %s", agent, strings.Join(a.Agents(), ", "))
+	}
+	if template != "" && !a.activations().Exists(template) {
+		return SessionInfo{}, fmt.Errorf("unknown activation template %q; available: %s", template, strings.Join(a.Templates(), ", "))
+	}
+	tags, err := session.NormalizeTags(tags)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	uc := newuc.New(a.deps.FS, a.llmBackend(), a.namingModel(), a.deps.UUID, a.deps.Clock, a.sessionsDir)
+	sessionName, sessionPath, claudeSessionID, err := uc.Execute(description, newuc.Options{Agent: agent, Template: template, Branch: a.gitBranch(), Tags: tags})
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("failed to create new session: %w", err)
+	}
+
+	return SessionInfo{
+		Name:     sessionName,
+		Path:     sessionPath,
+		ClaudeID: claudeSessionID,
+		Mode:     LaunchModeNew,
+	}, nil
+}
+
+// gitBranch returns the checked out branch, or "" outside a repository
+func (a *App) gitBranch() string {
+	branch, err := git.New(a.deps.Cmd).GetCurrentBranch()
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
+// Agents lists the agents a session can start with: the embedded profiles and
+// the agents in .claude/agents/
+func (a *App) Agents() []string {
+	agents, _ := profile.GetProfiles(claudex.Profiles)
+	return agents
+}
+
+// Templates lists the activation templates a session can start with: the
+// embedded default and the templates in the project and user template folders
+func (a *App) Templates() []string {
+	return a.activations().Names()
+}
+
+// ResumeSession prepares an existing session for resuming. Sessions that were
+// never launched with a Claude session ID start as ephemeral, as in the selector.
+func (a *App) ResumeSession(name string) (SessionInfo, error) {
+	sessionName, err := session.Resolve(a.deps.FS, a.sessionsDir, name)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	sessionPath := filepath.Join(a.sessionsDir, sessionName)
+
+	claudeSessionID := session.ClaudeSessionID(a.deps.FS, sessionPath)
+	if claudeSessionID == "" {
+		return SessionInfo{
+			Name: sessionName,
+			Path: sessionPath,
+			Mode: LaunchModeEphemeral,
+		}, nil
+	}
+
+	return SessionInfo{
+		Name:     sessionName,
+		Path:     sessionPath,
+		ClaudeID: claudeSessionID,
+		Mode:     LaunchModeResume,
+	}, nil
+}
+
+// ForkSession copies an existing session into a new one named from description
+func (a *App) ForkSession(name, description string) (SessionInfo, error) {
+	sessionName, err := session.Resolve(a.deps.FS, a.sessionsDir, name)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	uc := forkuc.New(a.deps.FS, a.llmBackend(), a.namingModel(), a.deps.UUID, a.deps.Clock, a.sessionsDir)
+	newSessionName, newSessionPath, newClaudeSessionID, err := uc.Execute(sessionName, description)
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("failed to fork session: %w", err)
+	}
+
+	return SessionInfo{
+		Name:         newSessionName,
+		Path:         newSessionPath,
+		ClaudeID:     newClaudeSessionID,
+		Mode:         LaunchModeFork,
+		OriginalName: sessionName,
+	}, nil
+}
+
+// FreshSession restarts an existing session with a new Claude conversation,
+// keeping its files
+func (a *App) FreshSession(name string) (SessionInfo, error) {
+	sessionName, err := session.Resolve(a.deps.FS, a.sessionsDir, name)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	archiveDir := ""
+	if a.archivesOnFresh() {
+		archiveDir = a.archiveDir
+	}
+	uc := freshuc.New(a.deps.FS, a.deps.UUID, a.deps.Clock, a.sessionsDir, archiveDir)
+	newSessionName, newSessionPath, newClaudeSessionID, err := uc.Execute(sessionName)
+	if err != nil {
+		return SessionInfo{}, fmt.Errorf("failed to create fresh session: %w", err)
+	}
+
+	return SessionInfo{
+		Name:         newSessionName,
+		Path:         newSessionPath,
+		ClaudeID:     newClaudeSessionID,
+		Mode:         LaunchModeFresh,
+		OriginalName: sessionName,
+	}, nil
+}
+
+// archivesOnFresh reports whether fresh restarts archive the original
+// session (sessions.archive_on_fresh) rather than delete it
+func (a *App) archivesOnFresh() bool {
+	return a.archiveDir != "" && (a.cfg == nil || a.cfg.Sessions.ArchiveOnFresh)
+}
+
+// UnarchiveSession restores an archived session to the session list
+func (a *App) UnarchiveSession(name string) (string, error) {
+	if err := session.Unarchive(a.deps.FS, a.sessionsDir, a.archiveDir, name); err != nil {
+		return "", fmt.Errorf("failed to restore session %s: %w", name, err)
+	}
+	return filepath.Join(a.sessionsDir, name), nil
+}
+
+// RenameSession gives a session a new slug, keeping its Claude session ID,
+// log file and the references other sessions and queued jobs hold to it
+func (a *App) RenameSession(name, slug string) (string, error) {
+	return a.manageSessions().Rename(name, slug)
+}
+
+// ArchiveSession moves a session to the archive and returns its name
+func (a *App) ArchiveSession(name string) (string, error) {
+	return a.manageSessions().Archive(name)
+}
+
+// RemoveSession deletes a session folder and returns its name
+func (a *App) RemoveSession(name string) (string, error) {
+	return a.manageSessions().Remove(name)
+}
+
+// PurgeSession permanently deletes an archived session and returns its name
+func (a *App) PurgeSession(name string) (string, error) {
+	return a.manageSessions().Purge(name)
+}
+
+// manageSessions returns the usecase behind the session management commands
+func (a *App) manageSessions() *managesessionsuc.UseCase {
+	return managesessionsuc.New(a.deps.FS, a.deps.Clock, a.jobQueue(), a.sessionsDir, a.archiveDir, filepath.Join(a.projectDir, paths.LogsDir))
+}
+
+// Launch starts Claude for a prepared session
+func (a *App) Launch(si SessionInfo) error {
+	if err := a.ensureClaudeInstalled(); err != nil {
+		return err
+	}
+	return a.start(si)
+}
+
+// UpdateDocs updates index.md files from the git history (`--update-docs`)
+func (a *App) UpdateDocs() error {
+	if err := a.ensureClaudeInstalled(); err != nil {
+		return err
+	}
+	uc := updatedocsuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env)
+	return uc.Execute(a.projectDir)
+}
+
+// CreateIndex generates an index.md for a directory (`--create-index`)
+func (a *App) CreateIndex(dir string) error {
+	if err := a.ensureClaudeInstalled(); err != nil {
+		return err
+	}
+	uc := createindexuc.New(a.deps.FS, a.deps.Cmd, a.deps.Env, a.jobQueue())
+	return uc.Execute(dir)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/terminal"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCommandTestApp(h *testutil.TestHarness) *App {
+	return &App{
+		deps: &Dependencies{
+			FS:    h.FS,
+			Cmd:   h.Commander,
+			Clock: h,
+			UUID:  h,
+			Env:   h.Env,
+		},
+		projectDir:  "/project",
+		sessionsDir: "/project/.claudex/sessions",
+	}
+}
+
+// TestResumeSession_ByPrefix verifies a session resolves from a prefix
+func TestResumeSession_ByPrefix(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newCommandTestApp(h)
+	h.CreateDir(filepath.Join(app.sessionsDir, "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"))
+
+	si, err := app.ResumeSession("auth")
+	require.NoError(t, err)
+	assert.Equal(t, LaunchModeResume, si.Mode)
+	assert.Equal(t, "aaaabbbb-cccc-dddd-eeee-ffffffffffff", si.ClaudeID)
+	assert.Equal(t, filepath.Join(app.sessionsDir, "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"), si.Path)
+}
+
+// TestResumeSession_WithoutClaudeID verifies sessions never launched start ephemeral
+func TestResumeSession_WithoutClaudeID(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newCommandTestApp(h)
+	h.CreateDir(filepath.Join(app.sessionsDir, "draft"))
+
+	si, err := app.ResumeSession("draft")
+	require.NoError(t, err)
+	assert.Equal(t, LaunchModeEphemeral, si.Mode)
+	assert.Empty(t, si.ClaudeID)
+}
+
+// TestFreshSession_RecordsOriginal verifies fresh mode runs the fresh usecase
+func TestFreshSession_RecordsOriginal(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newCommandTestApp(h)
+	h.CreateSessionWithFiles(filepath.Join(app.sessionsDir, "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"), map[string]string{
+		".description": "Auth refactor",
+	})
+	h.UUIDs = []string{"11112222-3333-4444-5555-666666666666"}
+
+	si, err := app.FreshSession("auth")
+	require.NoError(t, err)
+	assert.Equal(t, LaunchModeFresh, si.Mode)
+	assert.Equal(t, "auth-refactor-11112222-3333-4444-5555-666666666666", si.Name)
+	assert.Equal(t, "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff", si.OriginalName)
+}
+
+// TestResumeSession_Ambiguous verifies ambiguous prefixes are rejected
+func TestResumeSession_Ambiguous(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newCommandTestApp(h)
+	h.CreateDir(filepath.Join(app.sessionsDir, "auth-one"))
+	h.CreateDir(filepath.Join(app.sessionsDir, "auth-two"))
+
+	_, err := app.ResumeSession("auth")
+	assert.ErrorContains(t, err, "ambiguous")
+}
+
+// TestRun_NonInteractiveRequiresExplicitSession verifies the selector is never
+// started without a terminal
+func TestRun_NonInteractiveRequiresExplicitSession(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newCommandTestApp(h)
+	app.term = terminal.Mode{Interactive: false}
+
+	err := app.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "claudex session new")
+	for _, inv := range h.Commander.Invocations {
+		assert.NotEqual(t, "npm", inv.Name, "no update or install should run unattended")
+	}
+}
+
+// TestEnsureClaudeInstalled_NoPromptsFailsWithInstructions verifies the
+// install prompt is not shown when prompts are disabled
+func TestEnsureClaudeInstalled_NoPromptsFailsWithInstructions(t *testing.T) {
+	h := testutil.NewTestHarness()
+	app := newCommandTestApp(h)
+	app.term = terminal.Mode{Interactive: true, NoPrompts: true}
+	h.Commander.OnPattern("claude", "--version").Return(nil, errors.New("not found"))
+
+	err := app.ensureClaudeInstalled()
+	assert.EqualError(t, err, "claude CLI not installed")
+	testutil.AssertCommandInvoked(t, h.Commander, "claude", "--version")
+	assert.Len(t, h.Commander.Invocations, 1)
+}
+
+// TestStartupGC_ArchivesOncePerDay verifies the startup GC applies the
+// retention config and waits a day before running again
+func TestStartupGC_ArchivesOncePerDay(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	app := newCommandTestApp(h)
+	app.archiveDir = "/project/.claudex/archive"
+	app.cfg = &config.Config{Retention: config.Retention{MaxAgeDays: 30, GCOnStartup: true}}
+	stale := filepath.Join(app.sessionsDir, "old-aaaabbbb-cccc-dddd-eeee-ffffffffffff")
+	h.CreateSessionWithFiles(stale, map[string]string{".created": "2024-01-01T10:00:00Z"})
+
+	app.startupGC()
+	testutil.AssertNoDirExists(t, h.FS, stale)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(app.archiveDir, filepath.Base(stale)))
+	testutil.AssertFileContains(t, h.FS, "/project/.claudex/last-gc", "2024-02-20T12:00:00Z")
+
+	// Within a day of the last run nothing is collected
+	h.CreateSessionWithFiles(stale, map[string]string{".created": "2024-01-01T10:00:00Z"})
+	h.FixedTime = h.FixedTime.Add(time.Hour)
+	app.startupGC()
+	testutil.AssertDirExists(t, h.FS, stale)
+}
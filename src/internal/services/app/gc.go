@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claudex/internal/services/config"
+	"claudex/internal/services/paths"
+	managesessionsuc "claudex/internal/usecases/managesessions"
+
+	"github.com/spf13/afero"
+)
+
+// gcInterval is how long the startup GC waits after a run before the next
+const gcInterval = 24 * time.Hour
+
+// RetentionPolicy converts the [retention] config section into the limits
+// the session GC applies
+func RetentionPolicy(r config.Retention) managesessionsuc.Retention {
+	return managesessionsuc.Retention{
+		MaxAge:     time.Duration(r.MaxAgeDays) * 24 * time.Hour,
+		MaxCount:   r.MaxCount,
+		KeepTagged: r.KeepTagged,
+	}
+}
+
+// startupGC archives the sessions and log files beyond the retention limits,
+// at most once a day. Failures are logged and never stop claudex from
+// starting.
+func (a *App) startupGC() {
+	policy := RetentionPolicy(a.cfg.Retention)
+	if !policy.Enabled() {
+		return
+	}
+
+	now := a.deps.Clock.Now()
+	stampPath := filepath.Join(a.projectDir, paths.GCStampFile)
+	if data, err := afero.ReadFile(a.deps.FS, stampPath); err == nil {
+		if last, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data))); err == nil && now.Sub(last) < gcInterval {
+			return
+		}
+	}
+	if err := afero.WriteFile(a.deps.FS, stampPath, []byte(now.Format(time.RFC3339)+"\n"), 0644); err != nil {
+		log.Printf("Warning: Could not record the retention GC run: %v", err)
+	}
+
+	report, err := a.manageSessions().GC(policy, managesessionsuc.GCOptions{KeepLog: a.logFilePath})
+	if err != nil {
+		log.Printf("Warning: Retention GC failed: %v", err)
+		fmt.Fprintf(os.Stderr, "Warning: Retention GC failed: %v\n", err)
+		return
+	}
+	for _, s := range report.Sessions {
+		log.Printf("Retention GC archived session %s (%s)", s.Name, s.Reason)
+	}
+	for _, l := range report.Logs {
+		log.Printf("Retention GC archived log %s (%s)", l.Name, l.Reason)
+	}
+	if len(report.Sessions) > 0 || len(report.Logs) > 0 {
+		// stderr keeps the output of commands such as `session new --no-launch` clean
+		fmt.Fprintf(os.Stderr, "○ Archived %d sessions and %d log files beyond the retention limits (see: claudex session list --archived)\n",
+			len(report.Sessions), len(report.Logs))
+	}
+}
@@ -5,10 +5,19 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"claudex/internal/services/activation"
 	"claudex/internal/services/config"
+	"claudex/internal/services/git"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/models"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/profile"
 	"claudex/internal/services/session"
+	"claudex/internal/services/terminal"
+	"claudex/internal/services/usage"
 )
 
 // setEnvironment sets environment variables needed for Claude session
@@ -19,33 +28,46 @@ func (a *App) setEnvironment(si SessionInfo, cfg *config.Config) {
 		os.Setenv("CLAUDEX_DOC_PATHS", resolveDocPaths(a.docPaths))
 	}
 
-	// Export feature toggles with env var override support
-	// Env vars take precedence over config values
-	sessionProgress := getEnvBool("CLAUDEX_AUTODOC_SESSION_PROGRESS", cfg.Features.AutodocSessionProgress)
-	sessionEnd := getEnvBool("CLAUDEX_AUTODOC_SESSION_END", cfg.Features.AutodocSessionEnd)
-	frequency := getEnvInt("CLAUDEX_AUTODOC_FREQUENCY", cfg.Features.AutodocFrequency)
-
-	os.Setenv("CLAUDEX_AUTODOC_SESSION_PROGRESS", strconv.FormatBool(sessionProgress))
-	os.Setenv("CLAUDEX_AUTODOC_SESSION_END", strconv.FormatBool(sessionEnd))
-	os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", strconv.Itoa(frequency))
+	// Export the feature toggles for the hooks; env overrides are already
+	// merged into cfg by the env layer
+	os.Setenv(config.EnvVar("features.autodoc_session_progress"), strconv.FormatBool(cfg.Features.AutodocSessionProgress))
+	os.Setenv(config.EnvVar("features.autodoc_session_end"), strconv.FormatBool(cfg.Features.AutodocSessionEnd))
+	os.Setenv(config.EnvVar("features.autodoc_frequency"), strconv.Itoa(cfg.Features.AutodocFrequency))
 }
 
-// getEnvBool returns env var value if set, otherwise returns default
-func getEnvBool(key string, defaultVal bool) bool {
-	if val := os.Getenv(key); val != "" {
-		return val == "true"
+// setLLMEnvironment exports the [llm] config so hooks and background job
+// workers select the same backend. Env vars already set take precedence.
+func (a *App) setLLMEnvironment(cfg *config.Config) {
+	values := map[string]string{
+		llm.EnvBackend:   cfg.LLM.Backend,
+		llm.EnvBaseURL:   cfg.LLM.BaseURL,
+		llm.EnvModel:     cfg.LLM.Model,
+		llm.EnvAPIKeyEnv: cfg.LLM.APIKeyEnv,
+	}
+	if cfg.LLM.MaxTokens > 0 {
+		values[llm.EnvMaxTokens] = strconv.Itoa(cfg.LLM.MaxTokens)
+	}
+	if cfg.LLM.TimeoutSeconds > 0 {
+		values[llm.EnvTimeout] = strconv.Itoa(cfg.LLM.TimeoutSeconds)
 	}
-	return defaultVal
-}
 
-// getEnvInt returns env var value if set, otherwise returns default
-func getEnvInt(key string, defaultVal int) int {
-	if val := os.Getenv(key); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			return i
+	for key, value := range values {
+		if value != "" && a.deps.Env.Get(key) == "" {
+			a.deps.Env.Set(key, value)
 		}
 	}
-	return defaultVal
+}
+
+// llmBackend returns the backend for headless model calls, recording usage
+// in the project's ledger
+func (a *App) llmBackend() llm.Backend {
+	ledger := usage.New(a.deps.FS, filepath.Join(a.projectDir, paths.UsageFile))
+	return usage.NewRecorder(llm.FromEnv(a.deps.FS, a.deps.Cmd, a.deps.Env), ledger, a.deps.Clock)
+}
+
+// namingModel returns the model used to generate session names
+func (a *App) namingModel() string {
+	return models.New(a.cfg.Models, a.deps.Env).Model(models.TaskSessionName)
 }
 
 // launch launches Claude based on the session info and mode
@@ -55,12 +77,22 @@ func (a *App) launch(si SessionInfo) error {
 		fmt.Fprintf(os.Stderr, "Warning: Could not update last used timestamp: %v\n", err)
 	}
 
+	// Mark the session as in use so it is not renamed under Claude's hooks
+	if si.Path != "" {
+		if err := session.MarkActive(a.deps.FS, si.Path, os.Getpid()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		defer session.ClearActive(a.deps.FS, si.Path)
+	}
+
 	// Give terminal a moment to settle
 	time.Sleep(100 * time.Millisecond)
 
-	// Clear screen and show launching message
-	fmt.Print("\033[H\033[2J\033[3J") // Clear screen and scrollback
-	fmt.Print("\033[0m")              // Reset all attributes
+	// Clear screen and show launching message (not when output is captured)
+	if terminal.IsTerminal(os.Stdout) {
+		fmt.Print("\033[H\033[2J\033[3J") // Clear screen and scrollback
+		fmt.Print("\033[0m")              // Reset all attributes
+	}
 
 	switch si.Mode {
 	case LaunchModeNew:
@@ -87,23 +119,62 @@ func (a *App) launchNew(si SessionInfo) error {
 	// Small delay before launching
 	time.Sleep(300 * time.Millisecond)
 
-	// Construct relative session path for activation command
-	relativeSessionPath := filepath.Join(".claudex", "sessions", filepath.Base(si.Path))
-	activationPrompt := fmt.Sprintf("/agents:team-lead activate in session %s", relativeSessionPath)
-	if len(a.docPaths) > 0 {
-		activationPrompt += "\n\nIMPORTANT - Required Documentation:\nBefore proceeding, you MUST read these documentation files:"
-		for _, docPath := range a.docPaths {
-			absPath, _ := filepath.Abs(docPath)
-			activationPrompt += fmt.Sprintf("\n- %s", absPath)
-		}
+	// Launch the Claude session with activation command
+	return launchClaude(a.deps, a.projectDir, si.ClaudeID, a.activationPrompt(si), a.sessionClaudeArgs(si))
+}
+
+// activationPrompt renders the session's activation template, falling back
+// to the embedded default when the template is missing or broken
+func (a *App) activationPrompt(si SessionInfo) string {
+	metadata, err := session.ReadMetadata(a.deps.FS, si.Path)
+	if err != nil {
+		metadata = &session.SessionMetadata{}
+	}
+	agent := metadata.Agent
+	if agent == "" {
+		agent = profile.DefaultAgent
+	}
+	template := metadata.Template
+	if template == "" && a.cfg != nil {
+		template = a.cfg.Activation.Template
 	}
 
-	// Launch the Claude session with activation command
-	return launchClaude(a.deps, si.ClaudeID, activationPrompt)
+	var docs []string
+	for _, docPath := range a.docPaths {
+		absPath, _ := filepath.Abs(docPath)
+		docs = append(docs, absPath)
+	}
+
+	data := activation.Data{
+		Agent:       agent,
+		SessionName: si.Name,
+		SessionPath: filepath.Join(".claudex", "sessions", filepath.Base(si.Path)),
+		Mode:        string(si.Mode),
+		Parent:      si.OriginalName,
+		Description: metadata.Description,
+		Docs:        docs,
+	}
+
+	prompt, err := a.activations().Render(template, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the default activation prompt\n", err)
+		prompt, _ = activation.New(a.deps.FS, nil).Render(activation.DefaultTemplate, data)
+	}
+	return prompt
+}
+
+// activations returns the activation templates of the project, then the user
+func (a *App) activations() activation.Service {
+	userDir := filepath.Join(filepath.Dir(config.UserPath(a.deps.Env)), "templates")
+	return activation.New(a.deps.FS, git.New(a.deps.Cmd), filepath.Join(a.projectDir, paths.TemplatesDir), userDir)
 }
 
 // launchResume resumes an existing Claude session
 func (a *App) launchResume(si SessionInfo) error {
+	if !a.conversationStarted(si.ClaudeID) {
+		return a.launchUnstarted(si)
+	}
+
 	fmt.Printf("\n✅ Resuming Claude session\n")
 	fmt.Printf("📦 Session: %s\n", si.Name)
 	fmt.Printf("🔄 Session ID: %s\n\n", si.ClaudeID)
@@ -112,7 +183,32 @@ func (a *App) launchResume(si SessionInfo) error {
 	time.Sleep(300 * time.Millisecond)
 
 	// For resume, continue existing session
-	return resumeClaude(a.deps, si.ClaudeID)
+	return resumeClaude(a.deps, a.projectDir, si.ClaudeID, a.sessionClaudeArgs(si))
+}
+
+// conversationStarted reports whether Claude has written a transcript for a
+// session ID. Sessions created with --no-launch or forked from the selector
+// have none until they are first launched. When the transcript folder can't
+// be located the conversation is assumed to exist.
+func (a *App) conversationStarted(claudeID string) bool {
+	if _, err := session.ClaudeProjectsDir(a.deps.Env); err != nil {
+		return true
+	}
+	_, err := session.FindTranscripts(a.deps.FS, a.deps.Env, a.projectDir, claudeID)
+	return err == nil
+}
+
+// launchUnstarted starts the first conversation of a session that was never
+// launched, with the activation prompt of a new session or of a fork
+func (a *App) launchUnstarted(si SessionInfo) error {
+	si.Mode = LaunchModeNew
+	if metadata, err := session.ReadMetadata(a.deps.FS, si.Path); err == nil && metadata.Parent != "" &&
+		len(metadata.Lineage) > 0 && metadata.Lineage[0].Op == session.OpFork {
+		si.Mode = LaunchModeFork
+		si.OriginalName = metadata.Parent
+		return a.launchFork(si)
+	}
+	return a.launchNew(si)
 }
 
 // launchFork launches a forked Claude session
@@ -125,17 +221,7 @@ func (a *App) launchFork(si SessionInfo) error {
 	time.Sleep(300 * time.Millisecond)
 
 	// For fork, start a new session with activation command
-	relativeSessionPath := filepath.Join(".claudex", "sessions", filepath.Base(si.Path))
-	activationPrompt := fmt.Sprintf("/agents:team-lead activate in session %s", relativeSessionPath)
-	if len(a.docPaths) > 0 {
-		activationPrompt += "\n\nIMPORTANT - Required Documentation:\nBefore proceeding, you MUST read these documentation files:"
-		for _, docPath := range a.docPaths {
-			absPath, _ := filepath.Abs(docPath)
-			activationPrompt += fmt.Sprintf("\n- %s", absPath)
-		}
-	}
-
-	return launchClaude(a.deps, si.ClaudeID, activationPrompt)
+	return launchClaude(a.deps, a.projectDir, si.ClaudeID, a.activationPrompt(si), a.sessionClaudeArgs(si))
 }
 
 // launchFresh launches a fresh memory session
@@ -148,17 +234,7 @@ func (a *App) launchFresh(si SessionInfo) error {
 	time.Sleep(300 * time.Millisecond)
 
 	// For fresh, start a new session with activation command
-	relativeSessionPath := filepath.Join(".claudex", "sessions", filepath.Base(si.Path))
-	activationPrompt := fmt.Sprintf("/agents:team-lead activate in session %s", relativeSessionPath)
-	if len(a.docPaths) > 0 {
-		activationPrompt += "\n\nIMPORTANT - Required Documentation:\nBefore proceeding, you MUST read these documentation files:"
-		for _, docPath := range a.docPaths {
-			absPath, _ := filepath.Abs(docPath)
-			activationPrompt += fmt.Sprintf("\n- %s", absPath)
-		}
-	}
-
-	return launchClaude(a.deps, si.ClaudeID, activationPrompt)
+	return launchClaude(a.deps, a.projectDir, si.ClaudeID, a.activationPrompt(si), a.sessionClaudeArgs(si))
 }
 
 // launchEphemeral launches an ephemeral session
@@ -173,19 +249,97 @@ func (a *App) launchEphemeral(si SessionInfo) error {
 	time.Sleep(500 * time.Millisecond)
 
 	// Launch Claude with NO activation prompt (ephemeral has no session folder)
-	return launchClaude(a.deps, claudeSessionID, "")
+	return launchClaude(a.deps, a.projectDir, claudeSessionID, "", a.sessionClaudeArgs(si))
+}
+
+// sessionClaudeArgs returns the Claude CLI arguments for si: the [launch]
+// options followed by the session's own arguments. Arguments given after --
+// replace the stored ones, so later resumes reapply the same model and
+// directories.
+func (a *App) sessionClaudeArgs(si SessionInfo) []string {
+	var args []string
+	if a.cfg != nil {
+		launch := a.cfg.Launch
+		launch.AddDirs = nil
+		for _, dir := range a.cfg.Launch.AddDirs {
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(a.projectDir, dir)
+			}
+			launch.AddDirs = append(launch.AddDirs, dir)
+		}
+		args = launch.ClaudeArgs()
+	}
+
+	if si.Path == "" {
+		return append(args, a.claudeArgs...)
+	}
+	if len(a.claudeArgs) > 0 {
+		if err := session.WriteClaudeArgs(a.deps.FS, si.Path, a.claudeArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not store Claude arguments: %v\n", err)
+		}
+		return append(args, a.claudeArgs...)
+	}
+	stored, err := session.ReadClaudeArgs(a.deps.FS, si.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not read stored Claude arguments: %v\n", err)
+	}
+	return append(args, stored...)
+}
+
+// pathOptions are the Claude CLI options whose values are paths. Both take
+// several values (--add-dir a b).
+var pathOptions = []string{"--add-dir", "--mcp-config"}
+
+// absClaudeArgs makes the path values of --add-dir and --mcp-config in
+// pass-through arguments absolute against dir, the directory claudex was
+// started from. Claude runs in the project root, and the arguments are
+// stored for later resumes, so relative paths would point elsewhere.
+// Inline JSON given to --mcp-config is left alone.
+func absClaudeArgs(args []string, dir string) []string {
+	abs := func(value string) string {
+		if value == "" || filepath.IsAbs(value) || strings.HasPrefix(strings.TrimSpace(value), "{") {
+			return value
+		}
+		return filepath.Join(dir, value)
+	}
+
+	out := make([]string, 0, len(args))
+	inPathOption := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			inPathOption = false
+			for _, option := range pathOptions {
+				if arg == option {
+					inPathOption = true
+				} else if value, ok := strings.CutPrefix(arg, option+"="); ok {
+					arg = option + "=" + abs(value)
+				}
+			}
+			out = append(out, arg)
+			continue
+		}
+		if inPathOption {
+			arg = abs(arg)
+		}
+		out = append(out, arg)
+	}
+	return out
 }
 
-// launchClaude launches a Claude CLI session with the provided session ID and activation prompt
-func launchClaude(deps *Dependencies, sessionID string, activationPrompt string) error {
+// launchClaude launches a Claude CLI session in dir with the provided session
+// ID and activation prompt. The prompt comes before extraArgs so a variadic
+// option such as --add-dir a b can't take it as a value.
+func launchClaude(deps *Dependencies, dir, sessionID string, activationPrompt string, extraArgs []string) error {
 	args := []string{"--session-id", sessionID}
 	if activationPrompt != "" {
 		args = append(args, activationPrompt)
 	}
-	return deps.Cmd.Start("claude", os.Stdin, os.Stdout, os.Stderr, args...)
+	args = append(args, extraArgs...)
+	return deps.Cmd.StartIn(dir, "claude", os.Stdin, os.Stdout, os.Stderr, args...)
 }
 
-// resumeClaude resumes an existing Claude CLI session
-func resumeClaude(deps *Dependencies, sessionID string) error {
-	return deps.Cmd.Start("claude", os.Stdin, os.Stdout, os.Stderr, "--resume", sessionID)
+// resumeClaude resumes an existing Claude CLI session in dir
+func resumeClaude(deps *Dependencies, dir, sessionID string, extraArgs []string) error {
+	args := append([]string{"--resume", sessionID}, extraArgs...)
+	return deps.Cmd.StartIn(dir, "claude", os.Stdin, os.Stdout, os.Stderr, args...)
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"claudex/internal/services/config"
+	"claudex/internal/services/session"
 	"claudex/internal/testutil"
 
 	"github.com/spf13/afero"
@@ -421,6 +422,7 @@ func TestLaunchEphemeral_CompareWithLaunchNew(t *testing.T) {
 		allArgs := strings.Join(invocation.Args, " ")
 		require.Contains(t, allArgs, "/agents:team-lead")
 		require.Contains(t, allArgs, "activate")
+		require.Equal(t, projectDir, invocation.Dir, "claude runs in the project root")
 	})
 
 	t.Run("Ephemeral session should NOT create directory or send activation", func(t *testing.T) {
@@ -586,14 +588,19 @@ func TestSetEnvironment_EnvVarOverridesConfig(t *testing.T) {
 		os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", origFreq)
 	}()
 
-	// Set env vars that should override config
-	os.Setenv("CLAUDEX_AUTODOC_SESSION_PROGRESS", "false")
-	os.Setenv("CLAUDEX_AUTODOC_SESSION_END", "false")
-	os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", "20")
-
 	h := testutil.NewTestHarness()
 	projectDir := "/project"
 
+	// Config says true, true, 5; env vars should override it
+	h.WriteFile("/project/.claudex/config.toml", `[features]
+autodoc_session_progress = true
+autodoc_session_end = true
+autodoc_frequency = 5
+`)
+	h.Env.Set("CLAUDEX_AUTODOC_SESSION_PROGRESS", "false")
+	h.Env.Set("CLAUDEX_AUTODOC_SESSION_END", "false")
+	h.Env.Set("CLAUDEX_AUTODOC_FREQUENCY", "20")
+
 	app := &App{
 		deps: &Dependencies{
 			FS:    h.FS,
@@ -611,16 +618,11 @@ func TestSetEnvironment_EnvVarOverridesConfig(t *testing.T) {
 		Mode: LaunchModeNew,
 	}
 
-	cfg := &config.Config{
-		Features: config.Features{
-			AutodocSessionProgress: true,  // Config says true
-			AutodocSessionEnd:      true,  // Config says true
-			AutodocFrequency:       5,     // Config says 5
-		},
-	}
+	layered, err := config.LoadLayered(h.FS, config.Sources{ProjectPath: "/project/.claudex/config.toml", Env: h.Env})
+	require.NoError(t, err)
 
 	// Set environment - env vars should override config
-	app.setEnvironment(si, cfg)
+	app.setEnvironment(si, layered.Config)
 
 	// Verify env vars won (overrode config)
 	require.Equal(t, "false", os.Getenv("CLAUDEX_AUTODOC_SESSION_PROGRESS"))
@@ -640,14 +642,15 @@ func TestSetEnvironment_PartialEnvVarOverride(t *testing.T) {
 		os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", origFreq)
 	}()
 
-	// Only override one env var
-	os.Setenv("CLAUDEX_AUTODOC_SESSION_PROGRESS", "false")
-	os.Unsetenv("CLAUDEX_AUTODOC_SESSION_END")     // Not set
-	os.Unsetenv("CLAUDEX_AUTODOC_FREQUENCY")       // Not set
-
 	h := testutil.NewTestHarness()
 	projectDir := "/project"
 
+	h.WriteFile("/project/.claudex/config.toml", `[features]
+autodoc_frequency = 10
+`)
+	// Only override one env var
+	h.Env.Set("CLAUDEX_AUTODOC_SESSION_PROGRESS", "false")
+
 	app := &App{
 		deps: &Dependencies{
 			FS:    h.FS,
@@ -665,20 +668,15 @@ func TestSetEnvironment_PartialEnvVarOverride(t *testing.T) {
 		Mode: LaunchModeNew,
 	}
 
-	cfg := &config.Config{
-		Features: config.Features{
-			AutodocSessionProgress: true,
-			AutodocSessionEnd:      true,
-			AutodocFrequency:       10,
-		},
-	}
+	layered, err := config.LoadLayered(h.FS, config.Sources{ProjectPath: "/project/.claudex/config.toml", Env: h.Env})
+	require.NoError(t, err)
 
 	// Set environment
-	app.setEnvironment(si, cfg)
+	app.setEnvironment(si, layered.Config)
 
-	// Verify: env var wins for progress, config wins for others
+	// Verify: env var wins for progress, config or default for others
 	require.Equal(t, "false", os.Getenv("CLAUDEX_AUTODOC_SESSION_PROGRESS")) // Env var override
-	require.Equal(t, "true", os.Getenv("CLAUDEX_AUTODOC_SESSION_END"))       // Config value
+	require.Equal(t, "true", os.Getenv("CLAUDEX_AUTODOC_SESSION_END"))       // Default
 	require.Equal(t, "10", os.Getenv("CLAUDEX_AUTODOC_FREQUENCY"))           // Config value
 }
 
@@ -692,13 +690,13 @@ func TestSetEnvironment_InvalidEnvVarValues(t *testing.T) {
 		os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", origFreq)
 	}()
 
-	// Set invalid env var values
-	os.Setenv("CLAUDEX_AUTODOC_SESSION_PROGRESS", "not-a-bool")
-	os.Setenv("CLAUDEX_AUTODOC_FREQUENCY", "not-a-number")
-
 	h := testutil.NewTestHarness()
 	projectDir := "/project"
 
+	// Set invalid env var values
+	h.Env.Set("CLAUDEX_AUTODOC_SESSION_PROGRESS", "not-a-bool")
+	h.Env.Set("CLAUDEX_AUTODOC_FREQUENCY", "not-a-number")
+
 	app := &App{
 		deps: &Dependencies{
 			FS:    h.FS,
@@ -716,18 +714,193 @@ func TestSetEnvironment_InvalidEnvVarValues(t *testing.T) {
 		Mode: LaunchModeNew,
 	}
 
-	cfg := &config.Config{
-		Features: config.Features{
-			AutodocSessionProgress: true,
-			AutodocSessionEnd:      true,
-			AutodocFrequency:       5,
+	layered, err := config.LoadLayered(h.FS, config.Sources{Env: h.Env})
+	require.NoError(t, err)
+
+	// Set environment
+	app.setEnvironment(si, layered.Config)
+
+	// Verify: invalid values are ignored and the defaults apply
+	require.Equal(t, "true", os.Getenv("CLAUDEX_AUTODOC_SESSION_PROGRESS"))
+	require.Equal(t, "5", os.Getenv("CLAUDEX_AUTODOC_FREQUENCY"))
+}
+
+// TestLaunchFork_UsesRecordedEntryAgent verifies fork and fresh launches
+// activate the agent the session was created with
+func TestLaunchFork_UsesRecordedEntryAgent(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionsDir := "/project/.claudex/sessions"
+	sessionPath := filepath.Join(sessionsDir, "api-design-fork-uuid")
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		".description": "API design",
+		".agent":       "architect",
+	})
+
+	app := &App{
+		deps: &Dependencies{
+			FS:    h.FS,
+			Cmd:   h.Commander,
+			Clock: h,
+			UUID:  h,
+			Env:   h.Env,
 		},
+		projectDir:  "/project",
+		sessionsDir: sessionsDir,
 	}
 
-	// Set environment
-	app.setEnvironment(si, cfg)
+	for _, launch := range []func(SessionInfo) error{app.launchFork, app.launchFresh} {
+		h.Commander.Invocations = nil
+		_ = launch(SessionInfo{Name: "api-design-fork-uuid", Path: sessionPath, ClaudeID: "fork-uuid"})
+
+		require.NotEmpty(t, h.Commander.Invocations)
+		allArgs := strings.Join(h.Commander.Invocations[0].Args, " ")
+		require.Contains(t, allArgs, "/agents:architect activate in session .claudex/sessions/api-design-fork-uuid")
+		require.NotContains(t, allArgs, "team-lead")
+	}
+}
+
+// TestLaunchNew_RendersSessionTemplate verifies the session's activation
+// template is rendered, and a broken one falls back to the default prompt
+func TestLaunchNew_RendersSessionTemplate(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionsDir := "/project/.claudex/sessions"
+	sessionPath := filepath.Join(sessionsDir, "login-bug-uuid")
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		".description": "Login fails on Safari",
+		".template":    "bugfix",
+	})
+	h.WriteFile("/project/.claudex/templates/bugfix.tmpl", "Reproduce first: {{.Description}} ({{.Mode}})")
+
+	app := &App{
+		deps: &Dependencies{
+			FS:    h.FS,
+			Cmd:   h.Commander,
+			Clock: h,
+			UUID:  h,
+			Env:   h.Env,
+		},
+		cfg:         &config.Config{Activation: config.Activation{Template: "feature"}},
+		projectDir:  "/project",
+		sessionsDir: sessionsDir,
+	}
+	si := SessionInfo{Name: "login-bug-uuid", Path: sessionPath, ClaudeID: "uuid", Mode: LaunchModeNew}
+
+	_ = app.launchNew(si)
+	require.NotEmpty(t, h.Commander.Invocations)
+	args := h.Commander.Invocations[0].Args
+	require.Equal(t, "Reproduce first: Login fails on Safari (new)", args[len(args)-1])
+
+	h.Commander.Invocations = nil
+	h.WriteFile("/project/.claudex/templates/bugfix.tmpl", "{{.Description")
+	_ = app.launchNew(si)
+	require.NotEmpty(t, h.Commander.Invocations)
+	args = h.Commander.Invocations[0].Args
+	require.Equal(t, "/agents:team-lead activate in session .claudex/sessions/login-bug-uuid", args[len(args)-1])
+}
+
+// TestLaunch_ClaudeArgsStoredAndReapplied verifies [launch] options and
+// arguments after -- reach Claude, and that resume reapplies the stored ones
+func TestLaunch_ClaudeArgsStoredAndReapplied(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionsDir := "/project/.claudex/sessions"
+	sessionPath := filepath.Join(sessionsDir, "spike-uuid")
+	h.CreateSessionWithFiles(sessionPath, map[string]string{".description": "Spike"})
+
+	app := &App{
+		deps: &Dependencies{
+			FS:    h.FS,
+			Cmd:   h.Commander,
+			Clock: h,
+			UUID:  h,
+			Env:   h.Env,
+		},
+		cfg:         &config.Config{Launch: config.Launch{PermissionMode: "plan", AddDirs: []string{"../shared"}}},
+		projectDir:  "/project",
+		sessionsDir: sessionsDir,
+		claudeArgs:  []string{"--model", "opus", "--add-dir", "../api"},
+	}
+
+	_ = app.launchNew(SessionInfo{Name: "spike-uuid", Path: sessionPath, ClaudeID: "uuid", Mode: LaunchModeNew})
+	require.Len(t, h.Commander.Invocations, 1)
+	args := h.Commander.Invocations[0].Args
+	require.Equal(t, []string{"--session-id", "uuid"}, args[:2])
+	require.Equal(t, []string{"--permission-mode=plan", "--add-dir=/shared", "--model", "opus", "--add-dir", "../api"}, args[3:])
+	stored, err := session.ReadClaudeArgs(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"--model", "opus", "--add-dir", "../api"}, stored)
+
+	// A later resume without -- reapplies the stored arguments
+	h.Commander.Invocations = nil
+	app.claudeArgs = nil
+	_ = app.launchResume(SessionInfo{Name: "spike-uuid", Path: sessionPath, ClaudeID: "uuid", Mode: LaunchModeResume})
+	require.Len(t, h.Commander.Invocations, 1)
+	require.Equal(t, []string{"--resume", "uuid", "--permission-mode=plan", "--add-dir=/shared", "--model", "opus", "--add-dir", "../api"}, h.Commander.Invocations[0].Args)
+}
+
+// TestAbsClaudeArgs verifies --add-dir and --mcp-config paths after -- are
+// made absolute against the directory claudex was started from
+func TestAbsClaudeArgs(t *testing.T) {
+	root := t.TempDir() // absolute on every platform
+	cwd := filepath.Join(root, "project", "web")
+	lib := filepath.Join(root, "lib")
+	args := absClaudeArgs([]string{
+		"--model", "opus",
+		"--add-dir", "../api", lib, "docs",
+		"--add-dir=../shared",
+		"--mcp-config", "mcp.json", `{"mcpServers":{}}`,
+		"--permission-mode", "plan",
+	}, cwd)
+	require.Equal(t, []string{
+		"--model", "opus",
+		"--add-dir", filepath.Join(root, "project", "api"), lib, filepath.Join(cwd, "docs"),
+		"--add-dir=" + filepath.Join(root, "project", "shared"),
+		"--mcp-config", filepath.Join(cwd, "mcp.json"), `{"mcpServers":{}}`,
+		"--permission-mode", "plan",
+	}, args)
+}
+
+// TestLaunchResume_UnstartedSession verifies that a session whose conversation
+// was never launched (forked from the selector or created with --no-launch)
+// starts with the activation prompt instead of resuming a missing transcript
+func TestLaunchResume_UnstartedSession(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/me")
+
+	projectDir := "/project"
+	sessionsDir := filepath.Join(projectDir, ".claudex", "sessions")
+	claudeID := "aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+	sessionPath := filepath.Join(sessionsDir, "oauth-"+claudeID)
+	require.NoError(t, session.WriteMetadata(h.FS, sessionPath, &session.SessionMetadata{
+		ClaudeSessionID: claudeID,
+		Description:     "Try OAuth",
+		Parent:          "auth-11112222-3333-4444-5555-666666666666",
+		Lineage:         []session.LineageEvent{{Op: session.OpFork, From: "auth-11112222-3333-4444-5555-666666666666"}},
+	}))
+
+	app := &App{
+		deps: &Dependencies{
+			FS:    h.FS,
+			Cmd:   h.Commander,
+			Clock: h,
+			UUID:  h,
+			Env:   h.Env,
+		},
+		projectDir:  projectDir,
+		sessionsDir: sessionsDir,
+	}
+	si := SessionInfo{Name: "oauth-" + claudeID, Path: sessionPath, ClaudeID: claudeID, Mode: LaunchModeResume}
+
+	require.NoError(t, app.launchResume(si))
+	require.Len(t, h.Commander.Invocations, 1)
+	args := h.Commander.Invocations[0].Args
+	require.Equal(t, []string{"--session-id", claudeID}, args[:2])
+	require.Contains(t, strings.Join(args, " "), "/agents:team-lead activate")
 
-	// Verify: invalid bool becomes false, invalid int falls back to config
-	require.Equal(t, "false", os.Getenv("CLAUDEX_AUTODOC_SESSION_PROGRESS")) // "not-a-bool" != "true" = false
-	require.Equal(t, "5", os.Getenv("CLAUDEX_AUTODOC_FREQUENCY"))            // Invalid int, uses config default
+	// Once Claude wrote the transcript, the conversation is resumed
+	h.WriteFile(filepath.Join("/home/me/.claude/projects", session.EncodeProjectPath(projectDir), claudeID+".jsonl"), "{}\n")
+	require.NoError(t, app.launchResume(si))
+	require.Equal(t, []string{"--resume", claudeID}, h.Commander.Invocations[1].Args)
 }
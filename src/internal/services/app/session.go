@@ -2,36 +2,69 @@ package app
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
+	"claudex"
+	"claudex/internal/services/profile"
 	"claudex/internal/services/session"
 	"claudex/internal/ui"
-	newuc "claudex/internal/usecases/session/new"
-	forkuc "claudex/internal/usecases/session/resume/fork"
-	freshuc "claudex/internal/usecases/session/resume/fresh"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// showSessionSelector displays the session selection UI and returns the user's choice
-func (a *App) showSessionSelector() (*ui.Model, error) {
-	// Get sessions
+// sessionItems builds the session selector entries, with the archived
+// sessions after the active ones when showArchived is set
+func (a *App) sessionItems(showArchived bool) ([]list.Item, error) {
 	sessions, err := session.GetSessions(a.deps.FS, a.sessionsDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sessions: %w", err)
 	}
 
-	// Build items
 	items := []list.Item{
 		session.SessionItem{Title: "Create New Session", Description: "Start a fresh working session", ItemType: "new"},
 		session.SessionItem{Title: "Ephemeral", Description: "Work without saving session data", ItemType: "ephemeral"},
 	}
-
 	for _, s := range sessions {
 		items = append(items, s)
 	}
 
+	if showArchived {
+		archived, err := session.GetSessions(a.deps.FS, a.archiveDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get archived sessions: %w", err)
+		}
+		for _, s := range archived {
+			s.ItemType = "archived"
+			s.Status = session.StatusArchived
+			s.Description = "Archived • " + s.Description
+			items = append(items, s)
+		}
+	}
+	return items, nil
+}
+
+// sessionPreview loads the preview pane content of a selector entry; the
+// create and ephemeral entries have none
+func (a *App) sessionPreview(item session.SessionItem) (*session.Preview, error) {
+	switch item.ItemType {
+	case "session":
+		return session.LoadPreview(a.deps.FS, filepath.Join(a.sessionsDir, item.Title))
+	case "archived":
+		return session.LoadPreview(a.deps.FS, filepath.Join(a.archiveDir, item.Title))
+	}
+	return nil, nil
+}
+
+// showSessionSelector displays the session selection UI and returns the user's choice
+func (a *App) showSessionSelector() (*ui.Model, error) {
+	items, err := a.sessionItems(false)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create list
 	delegate := ui.ItemDelegate{}
 	l := list.New(items, delegate, 0, 0)
@@ -39,16 +72,52 @@ func (a *App) showSessionSelector() (*ui.Model, error) {
 	l.Styles.Title = ui.TitleStyle()
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
+	l.Filter = ui.FilterSessions
 	l.SetShowHelp(true)
+	// d and f are session actions here; the other paging keys remain
+	l.KeyMap.NextPage.SetKeys("right", "l", "pgdown")
+
+	editor := a.deps.Env.Get("VISUAL")
+	if editor == "" {
+		editor = a.deps.Env.Get("EDITOR")
+	}
 
 	// Additional keybindings
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{
+		bindings := []key.Binding{
+			key.NewBinding(
+				key.WithKeys("r"),
+				key.WithHelp("r", "rename"),
+			),
+			key.NewBinding(
+				key.WithKeys("f"),
+				key.WithHelp("f", "fork"),
+			),
+			key.NewBinding(
+				key.WithKeys("x"),
+				key.WithHelp("x", "archive/restore"),
+			),
+			key.NewBinding(
+				key.WithKeys("d"),
+				key.WithHelp("d", "delete"),
+			),
+		}
+		if editor != "" {
+			bindings = append(bindings, key.NewBinding(
+				key.WithKeys("e"),
+				key.WithHelp("e", "open folder"),
+			))
+		}
+		return append(bindings,
+			key.NewBinding(
+				key.WithKeys("a"),
+				key.WithHelp("a", "archived"),
+			),
 			key.NewBinding(
 				key.WithKeys("q"),
 				key.WithHelp("q", "quit"),
 			),
-		}
+		)
 	}
 
 	m := ui.Model{
@@ -56,6 +125,26 @@ func (a *App) showSessionSelector() (*ui.Model, error) {
 		Stage:       "session",
 		ProjectDir:  a.projectDir,
 		SessionsDir: a.sessionsDir,
+		ArchiveDir:  a.archiveDir,
+		LoadItems:   a.sessionItems,
+
+		RenameSession: a.RenameSession,
+		ForkSession: func(name, description string) (string, error) {
+			si, err := a.ForkSession(name, description)
+			return si.Name, err
+		},
+		ArchiveSession: a.ArchiveSession,
+		UnarchiveSession: func(name string) (string, error) {
+			_, err := a.UnarchiveSession(name)
+			return name, err
+		},
+		RemoveSession: a.RemoveSession,
+		PurgeSession:  a.PurgeSession,
+		Editor:        editor,
+		LoadPreview:   a.sessionPreview,
+	}
+	if a.cfg != nil {
+		m.AutodocFrequency = a.cfg.Features.AutodocFrequency
 	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
@@ -76,26 +165,110 @@ func (a *App) handleNewSession() (SessionInfo, error) {
 	if err != nil {
 		return SessionInfo{}, err
 	}
+	tags, err := ui.PromptTags()
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	// UI: choose the entry agent
+	agent, err := a.showAgentSelector()
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	// UI: choose the activation template
+	template, err := a.showTemplateSelector()
+	if err != nil {
+		return SessionInfo{}, err
+	}
 
 	// UI: show loading
 	ui.ShowGenerating()
 
 	// Controller: route to usecase
-	newSessionUC := newuc.New(a.deps.FS, a.deps.Cmd, a.deps.UUID, a.deps.Clock, a.sessionsDir)
-	sessionName, sessionPath, claudeSessionID, err := newSessionUC.Execute(description)
+	si, err := a.NewSession(description, agent, template, tags)
 	if err != nil {
-		return SessionInfo{}, fmt.Errorf("failed to create new session: %w", err)
+		return SessionInfo{}, err
 	}
 
 	// UI: show result
-	ui.ShowSessionCreated(sessionName)
-
-	return SessionInfo{
-		Name:     sessionName,
-		Path:     sessionPath,
-		ClaudeID: claudeSessionID,
-		Mode:     LaunchModeNew,
-	}, nil
+	ui.ShowSessionCreated(si.Name)
+
+	return si, nil
+}
+
+// showAgentSelector lets the user pick the agent a new session starts with,
+// defaulting to profile.DefaultAgent. With a single agent there is nothing to
+// choose and no menu is shown.
+func (a *App) showAgentSelector() (string, error) {
+	describe := func(name string) string {
+		return profile.Describe(claudex.Profiles, name)
+	}
+	return a.showOptionSelector("Entry Agent", a.Agents(), profile.DefaultAgent, describe)
+}
+
+// showTemplateSelector lets the user pick the activation template of a new
+// session, defaulting to the configured one. Projects without custom
+// templates see no menu.
+func (a *App) showTemplateSelector() (string, error) {
+	configured := a.cfg.Activation.Template
+	describe := func(name string) string {
+		if name == configured {
+			return "Configured default"
+		}
+		return "Activation template"
+	}
+	choice, err := a.showOptionSelector("Activation Template", a.Templates(), configured, describe)
+	if choice == configured {
+		return "", err // Follow the config rather than pin the current value
+	}
+	return choice, err
+}
+
+// showOptionSelector shows a menu of names with selected preselected and
+// returns the choice, or "" when there is at most one name
+func (a *App) showOptionSelector(title string, names []string, selected string, describe func(string) string) (string, error) {
+	if len(names) <= 1 {
+		return "", nil
+	}
+
+	var items []list.Item
+	index := 0
+	for i, name := range names {
+		if name == selected {
+			index = i
+		}
+		items = append(items, session.SessionItem{Title: name, Description: describe(name), ItemType: "profile"})
+	}
+
+	delegate := ui.ItemDelegate{}
+	optionList := list.New(items, delegate, 0, 0)
+	optionList.Title = title
+	optionList.Styles.Title = ui.TitleStyle()
+	optionList.SetShowStatusBar(false)
+	optionList.SetFilteringEnabled(true)
+	optionList.SetShowHelp(true)
+	optionList.Select(index)
+
+	optionModel := ui.Model{
+		List:        optionList,
+		Stage:       "profile",
+		ProjectDir:  a.projectDir,
+		SessionsDir: a.sessionsDir,
+	}
+
+	optionProgram := tea.NewProgram(optionModel, tea.WithAltScreen())
+	finalOptionModel, err := optionProgram.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s selector: %w", strings.ToLower(title), err)
+	}
+
+	om := finalOptionModel.(ui.Model)
+	if om.Quitting {
+		return "", fmt.Errorf("user quit")
+	}
+
+	return om.Choice, nil
 }
 
 // handleResumeOrFork processes resume/fork/fresh choices for existing sessions
@@ -145,23 +318,16 @@ func (a *App) handleResumeOrFork(fm *ui.Model) (SessionInfo, error) {
 
 		// Handle "Fresh Memory" choice using fresh usecase
 		if submenuChoice == "fresh" {
-			freshUC := freshuc.New(a.deps.FS, a.deps.UUID, a.sessionsDir)
-			newSessionName, newSessionPath, newClaudeSessionID, err := freshUC.Execute(fm.SessionName)
+			si, err := a.FreshSession(fm.SessionName)
 			if err != nil {
-				return SessionInfo{}, fmt.Errorf("failed to create fresh session: %w", err)
+				return SessionInfo{}, err
 			}
-			ui.ShowFreshMemory(fm.SessionName, newSessionName)
-
-			return SessionInfo{
-				Name:         newSessionName,
-				Path:         newSessionPath,
-				ClaudeID:     newClaudeSessionID,
-				Mode:         LaunchModeFresh,
-				OriginalName: fm.SessionName,
-			}, nil
+			ui.ShowFreshMemory(fm.SessionName, si.Name, a.archivesOnFresh())
+
+			return si, nil
 		}
 		// else: submenuChoice == "continue" -> proceed with existing resume logic
-		claudeSessionID := session.ExtractClaudeSessionID(fm.SessionName)
+		claudeSessionID := session.ClaudeSessionID(a.deps.FS, fm.SessionPath)
 		if claudeSessionID == "" {
 			return SessionInfo{}, fmt.Errorf("could not extract session ID for resume")
 		}
@@ -183,22 +349,15 @@ func (a *App) handleResumeOrFork(fm *ui.Model) (SessionInfo, error) {
 		}
 
 		// Controller: route to usecase
-		forkUC := forkuc.New(a.deps.FS, a.deps.Cmd, a.deps.UUID, a.sessionsDir)
-		newSessionName, newSessionPath, newClaudeSessionID, err := forkUC.Execute(fm.SessionName, forkDescription)
+		si, err := a.ForkSession(fm.SessionName, forkDescription)
 		if err != nil {
-			return SessionInfo{}, fmt.Errorf("failed to fork session: %w", err)
+			return SessionInfo{}, err
 		}
 
 		// UI: show result
-		ui.ShowSessionForked(fm.SessionName, newSessionName)
+		ui.ShowSessionForked(fm.SessionName, si.Name)
 
-		return SessionInfo{
-			Name:         newSessionName,
-			Path:         newSessionPath,
-			ClaudeID:     newClaudeSessionID,
-			Mode:         LaunchModeFork,
-			OriginalName: fm.SessionName,
-		}, nil
+		return si, nil
 	}
 
 	return SessionInfo{}, fmt.Errorf("unknown resume/fork choice: %s", resumeOrForkChoice)
@@ -206,9 +365,13 @@ func (a *App) handleResumeOrFork(fm *ui.Model) (SessionInfo, error) {
 
 // showResumeSubmenu shows the Continue vs Fresh Memory submenu
 func (a *App) showResumeSubmenu(sessionName, sessionPath string) (string, error) {
+	freshDescription := "Start fresh, keep files, delete original"
+	if a.archivesOnFresh() {
+		freshDescription = "Start fresh, keep files, archive original"
+	}
 	resumeSubmenuItems := []list.Item{
 		session.SessionItem{Title: "Continue with context", Description: "Resume with full conversation history", ItemType: "continue"},
-		session.SessionItem{Title: "Fresh memory", Description: "Start fresh, keep files, delete original", ItemType: "fresh"},
+		session.SessionItem{Title: "Fresh memory", Description: freshDescription, ItemType: "fresh"},
 	}
 
 	delegate := ui.ItemDelegate{}
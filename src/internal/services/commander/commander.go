@@ -11,6 +11,8 @@ type Commander interface {
 	Run(name string, args ...string) ([]byte, error)
 	// Start launches interactive command with stdio attached
 	Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error
+	// StartIn is Start with dir as the command's working directory
+	StartIn(dir, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error
 }
 
 // OsCommander is the production implementation of Commander
@@ -21,7 +23,12 @@ func (c *OsCommander) Run(name string, args ...string) ([]byte, error) {
 }
 
 func (c *OsCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return c.StartIn("", name, stdin, stdout, stderr, args...)
+}
+
+func (c *OsCommander) StartIn(dir, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
 	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
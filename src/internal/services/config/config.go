@@ -1,9 +1,12 @@
 // Package config provides configuration file loading and parsing for Claudex.
-// It supports loading .claudex.toml files with options for documentation paths
-// and file overwrite behavior.
+// Values are layered: built-in defaults, the user-global
+// ~/.config/claudex/config.toml, the project .claudex/config.toml, CLAUDEX_*
+// environment variables and command line flags, each overriding the last.
 package config
 
 import (
+	"io"
+
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/afero"
 )
@@ -15,15 +18,72 @@ type Features struct {
 	AutodocFrequency       int  `toml:"autodoc_frequency"`
 }
 
+// LLM selects the backend used for headless model calls (naming, doc updates)
+type LLM struct {
+	Backend        string `toml:"backend"`         // claude-cli (default), anthropic or openai
+	BaseURL        string `toml:"base_url"`        // API endpoint override
+	Model          string `toml:"model"`           // Forces one model for every call
+	APIKeyEnv      string `toml:"api_key_env"`     // Env var holding the API key
+	MaxTokens      int    `toml:"max_tokens"`      // Reply cap for API backends
+	TimeoutSeconds int    `toml:"timeout_seconds"` // Per-request timeout for API backends
+}
+
+// Models routes headless tasks and generated agents to models
+type Models struct {
+	SessionName string            `toml:"session_name"` // Session name generation (default: backend default)
+	Overview    string            `toml:"overview"`     // Session overview autodoc (default: haiku)
+	IndexUpdate string            `toml:"index_update"` // index.md updates after commits (default: haiku)
+	IndexCreate string            `toml:"index_create"` // index.md creation (default: haiku)
+	Agents      map[string]string `toml:"agents"`       // Per-agent overrides keyed by agent name
+}
+
+// Activation selects the prompt template that starts new, forked and fresh
+// sessions
+type Activation struct {
+	Template string `toml:"template"` // Template name (default: the embedded default)
+}
+
+// Launch holds Claude CLI options added to every interactive session. Args
+// passed after -- on the command line are stored per session and applied on
+// top.
+type Launch struct {
+	Model          string   `toml:"model"`           // --model
+	PermissionMode string   `toml:"permission_mode"` // --permission-mode
+	AddDirs        []string `toml:"add_dirs"`        // --add-dir, one per entry
+	MCPConfig      string   `toml:"mcp_config"`      // --mcp-config
+	AllowedTools   []string `toml:"allowed_tools"`   // --allowedTools, one per entry
+	Args           []string `toml:"args"`            // Any other Claude CLI arguments, verbatim
+}
+
+// Sessions controls the session store
+type Sessions struct {
+	ArchiveOnFresh bool `toml:"archive_on_fresh"` // Fresh restarts archive the original instead of deleting it
+}
+
+// Retention limits how many sessions and log files stay active. `claudex gc`
+// moves the rest to the archive; nothing is deleted.
+type Retention struct {
+	MaxAgeDays  int  `toml:"max_age_days"`  // Archive sessions unused for longer (0: no limit)
+	MaxCount    int  `toml:"max_count"`     // Keep this many sessions, most recent first (0: no limit)
+	KeepTagged  bool `toml:"keep_tagged"`   // Never archive tagged sessions
+	GCOnStartup bool `toml:"gc_on_startup"` // Run the GC at most once a day when claudex starts
+}
+
 type Config struct {
-	Doc         []string `toml:"doc"`
-	NoOverwrite bool     `toml:"no_overwrite"`
-	Features    Features `toml:"features"`
+	Doc         []string   `toml:"doc"`
+	NoOverwrite bool       `toml:"no_overwrite"`
+	Features    Features   `toml:"features"`
+	LLM         LLM        `toml:"llm"`
+	Models      Models     `toml:"models"`
+	Activation  Activation `toml:"activation"`
+	Launch      Launch     `toml:"launch"`
+	Sessions    Sessions   `toml:"sessions"`
+	Retention   Retention  `toml:"retention"`
 }
 
-// Load loads configuration from the specified path using the provided filesystem
-func Load(fs afero.Fs, path string) (*Config, error) {
-	config := &Config{
+// defaults returns the built-in configuration
+func defaults() *Config {
+	return &Config{
 		Doc:         []string{},
 		NoOverwrite: false,
 		Features: Features{
@@ -31,7 +91,27 @@ func Load(fs afero.Fs, path string) (*Config, error) {
 			AutodocSessionEnd:      true,
 			AutodocFrequency:       5,
 		},
+		Activation: Activation{
+			Template: "default",
+		},
+		Launch: Launch{
+			AddDirs:      []string{},
+			AllowedTools: []string{},
+			Args:         []string{},
+		},
+		Sessions: Sessions{
+			ArchiveOnFresh: true,
+		},
+		Retention: Retention{
+			KeepTagged: true,
+		},
 	}
+}
+
+// Load loads a single configuration file over the defaults. LoadLayered
+// merges every layer.
+func Load(fs afero.Fs, path string) (*Config, error) {
+	config := defaults()
 
 	if _, err := fs.Stat(path); err == nil {
 		data, err := afero.ReadFile(fs, path)
@@ -44,3 +124,31 @@ func Load(fs afero.Fs, path string) (*Config, error) {
 	}
 	return config, nil
 }
+
+// ClaudeArgs returns the Claude CLI arguments for the [launch] options.
+// Options are written as --name=value so variadic flags such as --add-dir
+// can't swallow the arguments that follow.
+func (l Launch) ClaudeArgs() []string {
+	var args []string
+	if l.Model != "" {
+		args = append(args, "--model="+l.Model)
+	}
+	if l.PermissionMode != "" {
+		args = append(args, "--permission-mode="+l.PermissionMode)
+	}
+	for _, dir := range l.AddDirs {
+		args = append(args, "--add-dir="+dir)
+	}
+	if l.MCPConfig != "" {
+		args = append(args, "--mcp-config="+l.MCPConfig)
+	}
+	for _, tool := range l.AllowedTools {
+		args = append(args, "--allowedTools="+tool)
+	}
+	return append(args, l.Args...)
+}
+
+// Write encodes the configuration as TOML
+func (c *Config) Write(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(c)
+}
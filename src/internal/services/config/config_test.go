@@ -24,6 +24,8 @@ func TestLoad_EmptyConfig_ReturnsDefaults(t *testing.T) {
 	require.True(t, cfg.Features.AutodocSessionProgress, "AutodocSessionProgress should default to true")
 	require.True(t, cfg.Features.AutodocSessionEnd, "AutodocSessionEnd should default to true")
 	require.Equal(t, 5, cfg.Features.AutodocFrequency, "AutodocFrequency should default to 5")
+	require.True(t, cfg.Sessions.ArchiveOnFresh, "ArchiveOnFresh should default to true")
+	require.Equal(t, Retention{KeepTagged: true}, cfg.Retention, "Retention should default to no limits")
 }
 
 // TestLoad_NoConfigFile_ReturnsDefaults verifies that missing config file returns defaults
@@ -248,6 +250,62 @@ autodoc_frequency = 15
 	require.Equal(t, 15, cfg.Features.AutodocFrequency)
 }
 
+// TestLoad_ModelsSection verifies [models] and per-agent overrides are parsed
+func TestLoad_ModelsSection(t *testing.T) {
+	content := `[models]
+overview = "sonnet"
+index_update = "claude-haiku-4-5"
+
+[models.agents]
+architect = "sonnet"
+principal-engineer-go = "opus"`
+
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+
+	err := afero.WriteFile(fs, configPath, []byte(content), 0644)
+	require.NoError(t, err)
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, "sonnet", cfg.Models.Overview)
+	require.Equal(t, "claude-haiku-4-5", cfg.Models.IndexUpdate)
+	require.Empty(t, cfg.Models.SessionName)
+	require.Equal(t, map[string]string{"architect": "sonnet", "principal-engineer-go": "opus"}, cfg.Models.Agents)
+}
+
+// TestLoad_LaunchSection verifies [launch] options become Claude CLI arguments
+func TestLoad_LaunchSection(t *testing.T) {
+	content := `[launch]
+model = "opus"
+permission_mode = "acceptEdits"
+add_dirs = ["../api", "/shared/docs"]
+mcp_config = ".mcp.json"
+allowed_tools = ["Bash(git:*)", "Edit"]
+args = ["--verbose"]`
+
+	fs := afero.NewMemMapFs()
+	configPath := "/test/.claudex/config.toml"
+
+	err := afero.WriteFile(fs, configPath, []byte(content), 0644)
+	require.NoError(t, err)
+
+	cfg, err := Load(fs, configPath)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"--model=opus",
+		"--permission-mode=acceptEdits",
+		"--add-dir=../api",
+		"--add-dir=/shared/docs",
+		"--mcp-config=.mcp.json",
+		"--allowedTools=Bash(git:*)",
+		"--allowedTools=Edit",
+		"--verbose",
+	}, cfg.Launch.ClaudeArgs())
+}
+
 // TestLoad_MalformedTOML_ReturnsError verifies malformed TOML returns an error
 func TestLoad_MalformedTOML_ReturnsError(t *testing.T) {
 	content := `[features
@@ -0,0 +1,480 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"claudex/internal/services/env"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+// Layer names a configuration source. Each layer overrides the ones before it.
+type Layer string
+
+const (
+	LayerDefault Layer = "default" // Built into claudex
+	LayerUser    Layer = "user"    // ~/.config/claudex/config.toml
+	LayerProject Layer = "project" // <project>/.claudex/config.toml
+	LayerEnv     Layer = "env"     // CLAUDEX_* environment variables
+	LayerFlag    Layer = "flag"    // Command line flags
+)
+
+// Origin records which layer supplied a value
+type Origin struct {
+	Layer  Layer  `json:"layer"`
+	Source string `json:"source,omitempty"` // File path, variable name or flag
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return string(o.Layer)
+	}
+	return fmt.Sprintf("%s (%s)", o.Layer, o.Source)
+}
+
+// Value is one key of the effective configuration
+type Value struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Env    string      `json:"env,omitempty"`
+	Origin Origin      `json:"origin"`
+}
+
+// agentsPrefix prefixes the per-agent model keys (models.agents.<name>)
+const agentsPrefix = "models.agents."
+
+// modelEnvPrefix prefixes every model override variable
+const modelEnvPrefix = "CLAUDEX_MODEL_"
+
+var nonAlnum = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// envVars names the variable that overrides each key. The llm names match
+// the llm.Env* constants; keys without a variable (doc) can only be set in a
+// config file or with a flag.
+var envVars = map[string]string{
+	"no_overwrite":                      "CLAUDEX_NO_OVERWRITE",
+	"features.autodoc_session_progress": "CLAUDEX_AUTODOC_SESSION_PROGRESS",
+	"features.autodoc_session_end":      "CLAUDEX_AUTODOC_SESSION_END",
+	"features.autodoc_frequency":        "CLAUDEX_AUTODOC_FREQUENCY",
+	"llm.backend":                       "CLAUDEX_LLM_BACKEND",
+	"llm.base_url":                      "CLAUDEX_LLM_BASE_URL",
+	"llm.model":                         "CLAUDEX_LLM_MODEL",
+	"llm.api_key_env":                   "CLAUDEX_LLM_API_KEY_ENV",
+	"llm.max_tokens":                    "CLAUDEX_LLM_MAX_TOKENS",
+	"llm.timeout_seconds":               "CLAUDEX_LLM_TIMEOUT",
+	"activation.template":               "CLAUDEX_ACTIVATION_TEMPLATE",
+	"launch.model":                      "CLAUDEX_LAUNCH_MODEL",
+	"launch.permission_mode":            "CLAUDEX_LAUNCH_PERMISSION_MODE",
+	"launch.mcp_config":                 "CLAUDEX_LAUNCH_MCP_CONFIG",
+	"sessions.archive_on_fresh":         "CLAUDEX_SESSIONS_ARCHIVE_ON_FRESH",
+	"retention.max_age_days":            "CLAUDEX_RETENTION_MAX_AGE_DAYS",
+	"retention.max_count":               "CLAUDEX_RETENTION_MAX_COUNT",
+	"retention.keep_tagged":             "CLAUDEX_RETENTION_KEEP_TAGGED",
+	"retention.gc_on_startup":           "CLAUDEX_RETENTION_GC_ON_STARTUP",
+}
+
+// ModelEnvVar returns the override variable for a [models] key
+// (e.g. CLAUDEX_MODEL_INDEX_UPDATE)
+func ModelEnvVar(key string) string {
+	return modelEnvPrefix + envKey(key)
+}
+
+// AgentEnvVar returns the override variable for a generated agent
+// (e.g. CLAUDEX_MODEL_AGENT_PRINCIPAL_ENGINEER_GO)
+func AgentEnvVar(name string) string {
+	return modelEnvPrefix + "AGENT_" + envKey(name)
+}
+
+func envKey(name string) string {
+	return strings.Trim(nonAlnum.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+// EnvVar returns the variable that overrides key, or "" when it has none
+func EnvVar(key string) string {
+	if name := strings.TrimPrefix(key, agentsPrefix); name != key {
+		return AgentEnvVar(name)
+	}
+	if task := strings.TrimPrefix(key, "models."); task != key {
+		return ModelEnvVar(task)
+	}
+	return envVars[key]
+}
+
+// UserPath returns the user-global config file:
+// $XDG_CONFIG_HOME/claudex/config.toml, else ~/.config/claudex/config.toml
+func UserPath(environment env.Environment) string {
+	configDir := environment.Get("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home := environment.Get("HOME")
+		if home == "" {
+			home, _ = os.UserHomeDir()
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "claudex", "config.toml")
+}
+
+// Keys lists every fixed key in file order. Per-agent keys
+// (models.agents.<name>) are open-ended and not included.
+func Keys() []string {
+	var keys []string
+	walkFields(reflect.TypeOf(Config{}), "", func(key string) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+func walkFields(t reflect.Type, prefix string, fn func(key string)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("toml")
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			walkFields(field.Type, key+".", fn)
+		case reflect.Map:
+			// models.agents is keyed by agent name
+		default:
+			fn(key)
+		}
+	}
+}
+
+// field returns the settable struct field for a fixed key
+func field(cfg *Config, key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(cfg).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).Tag.Get("toml") == part {
+				v = v.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	if v.Kind() == reflect.Struct || v.Kind() == reflect.Map {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// Parse converts the text form of a value for key (as typed on the command
+// line or read from an env var) to its type. List keys take one item per
+// element of raw.
+func Parse(key string, raw ...string) (interface{}, error) {
+	if strings.HasPrefix(key, agentsPrefix) {
+		if len(raw) != 1 {
+			return nil, fmt.Errorf("%s takes a single value", key)
+		}
+		return raw[0], nil
+	}
+	v, ok := field(&Config{}, key)
+	if !ok {
+		return nil, fmt.Errorf("unknown config key: %s", key)
+	}
+	if v.Kind() == reflect.Slice {
+		return append([]string{}, raw...), nil
+	}
+	if len(raw) != 1 {
+		return nil, fmt.Errorf("%s takes a single value", key)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", key, raw[0])
+		}
+		return b, nil
+	case reflect.Int:
+		n, err := strconv.Atoi(raw[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number, got %q", key, raw[0])
+		}
+		return n, nil
+	}
+	return raw[0], nil
+}
+
+// Layered is the effective configuration merged from every layer, with the
+// origin of each value
+type Layered struct {
+	Config   *Config
+	origins  map[string]Origin
+	problems []Problem
+}
+
+// Sources locates the layers above the defaults. An empty path or a nil Env
+// skips that layer.
+type Sources struct {
+	UserPath    string
+	ProjectPath string
+	Env         env.Environment
+}
+
+// LoadLayered merges the built-in defaults, the user and project config files
+// and the CLAUDEX_* environment variables, lowest precedence first. Flags are
+// applied afterwards by the caller with ApplyFlag. Unknown keys, mistyped
+// values and env values that don't parse are skipped and reported by Validate.
+func LoadLayered(fs afero.Fs, src Sources) (*Layered, error) {
+	l := &Layered{Config: defaults(), origins: map[string]Origin{}}
+
+	if err := l.loadFile(fs, LayerUser, src.UserPath); err != nil {
+		return nil, err
+	}
+	if err := l.loadFile(fs, LayerProject, src.ProjectPath); err != nil {
+		return nil, err
+	}
+	if src.Env != nil {
+		l.loadEnv(src.Env)
+	}
+	return l, nil
+}
+
+// loadFile decodes one config file over the merged values, so keys the file
+// leaves out keep their lower-layer value
+func (l *Layered) loadFile(fs afero.Fs, layer Layer, path string) error {
+	if path == "" {
+		return nil
+	}
+	if _, err := fs.Stat(path); err != nil {
+		return nil
+	}
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+	md, problems, err := decodeFile(string(data), path, l.Config)
+	if err != nil {
+		return err
+	}
+	l.problems = append(l.problems, problems...)
+	for _, key := range md.Keys() {
+		l.origins[key.String()] = Origin{Layer: layer, Source: path}
+	}
+	return nil
+}
+
+func (l *Layered) loadEnv(environment env.Environment) {
+	for _, key := range l.keys() {
+		name := EnvVar(key)
+		if name == "" {
+			continue
+		}
+		raw := environment.Get(name)
+		if raw == "" {
+			continue
+		}
+		value, err := Parse(key, raw)
+		if err != nil {
+			message := strings.TrimPrefix(err.Error(), key+" ")
+			l.problems = append(l.problems, Problem{Source: name, Key: key, Message: message})
+			continue
+		}
+		l.set(key, value)
+		l.origins[key] = Origin{Layer: LayerEnv, Source: name}
+	}
+}
+
+// ApplyFlag overrides key with a value given on the command line
+func (l *Layered) ApplyFlag(key, flagName string, value interface{}) error {
+	if err := l.set(key, value); err != nil {
+		return err
+	}
+	l.origins[key] = Origin{Layer: LayerFlag, Source: "--" + flagName}
+	return nil
+}
+
+func (l *Layered) set(key string, value interface{}) error {
+	if name := strings.TrimPrefix(key, agentsPrefix); name != key {
+		if l.Config.Models.Agents == nil {
+			l.Config.Models.Agents = map[string]string{}
+		}
+		l.Config.Models.Agents[name] = fmt.Sprint(value)
+		return nil
+	}
+	v, ok := field(l.Config, key)
+	if !ok {
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+	rv := reflect.ValueOf(value)
+	if !rv.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("%s: cannot use %T as %s", key, value, v.Type())
+	}
+	v.Set(rv)
+	return nil
+}
+
+// keys lists the fixed keys followed by every configured agent
+func (l *Layered) keys() []string {
+	keys := Keys()
+	var agents []string
+	for name := range l.Config.Models.Agents {
+		agents = append(agents, agentsPrefix+name)
+	}
+	sort.Strings(agents)
+	return append(keys, agents...)
+}
+
+// Get returns the effective value of key and the layer that supplied it
+func (l *Layered) Get(key string) (Value, error) {
+	var value interface{}
+	if name := strings.TrimPrefix(key, agentsPrefix); name != key {
+		model, ok := l.Config.Models.Agents[name]
+		if !ok {
+			return Value{}, fmt.Errorf("no model configured for agent %s", name)
+		}
+		value = model
+	} else {
+		v, ok := field(l.Config, key)
+		if !ok {
+			return Value{}, fmt.Errorf("unknown config key: %s", key)
+		}
+		value = v.Interface()
+	}
+
+	origin, ok := l.origins[key]
+	if !ok {
+		origin = Origin{Layer: LayerDefault}
+	}
+	return Value{Key: key, Value: value, Env: EnvVar(key), Origin: origin}, nil
+}
+
+// Values returns every key of the effective configuration in file order
+func (l *Layered) Values() []Value {
+	var values []Value
+	for _, key := range l.keys() {
+		if v, err := l.Get(key); err == nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// WriteValues writes a table of keys with their effective value and origin
+func WriteValues(w io.Writer, values []Value) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tORIGIN")
+	for _, v := range values {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Key, FormatValue(v.Value), v.Origin)
+	}
+	return tw.Flush()
+}
+
+// WriteValuesJSON writes the values as a JSON array
+func WriteValuesJSON(w io.Writer, values []Value) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+// FormatValue renders a value as a TOML literal ("haiku", 5, ["docs"])
+func FormatValue(value interface{}) string {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]interface{}{"v": value}); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(buf.String(), "v = "))
+}
+
+// SetValue writes key = value into the config file at path, creating the
+// file and its table when needed. Other keys, comments and layout are kept.
+func SetValue(fs afero.Fs, path, key string, value interface{}) error {
+	table, name := "", key
+	if strings.HasPrefix(key, agentsPrefix) {
+		table, name = strings.TrimSuffix(agentsPrefix, "."), strings.TrimPrefix(key, agentsPrefix)
+	} else if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+
+	var content string
+	if data, err := afero.ReadFile(fs, path); err == nil {
+		content = string(data)
+	}
+	updated := setLine(content, table, name, name+" = "+FormatValue(value))
+
+	// Never leave behind a file the next run can't load
+	if _, err := toml.Decode(updated, defaults()); err != nil {
+		return fmt.Errorf("could not update %s: %w", path, err)
+	}
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return afero.WriteFile(fs, path, []byte(updated), 0644)
+}
+
+var tableHeader = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(#.*)?$`)
+
+// setLine replaces the assignment of name inside table, or adds it at the end
+// of the table (creating the table if it doesn't exist)
+func setLine(content, table, name, line string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+	assignment := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(name) + `"?\s*=`)
+
+	current := ""
+	insertAt := -1 // after the last line of the target table
+	if table == "" {
+		insertAt = 0
+	}
+	for i := 0; i < len(lines); i++ {
+		if m := tableHeader.FindStringSubmatch(lines[i]); m != nil {
+			current = strings.TrimSpace(m[1])
+			if current == table {
+				insertAt = i + 1
+			}
+			continue
+		}
+		if current != table {
+			continue
+		}
+		if assignment.MatchString(lines[i]) {
+			end := i + 1
+			// Multi-line arrays continue until the closing bracket
+			if strings.Contains(lines[i], "[") && !strings.Contains(lines[i], "]") {
+				for end < len(lines) && !strings.Contains(lines[end-1], "]") {
+					end++
+				}
+			}
+			return joinLines(append(append(lines[:i:i], line), lines[end:]...))
+		}
+		if strings.TrimSpace(lines[i]) != "" {
+			insertAt = i + 1
+		}
+	}
+
+	if insertAt < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		return joinLines(append(lines, "["+table+"]", line))
+	}
+	inserted := []string{line}
+	if insertAt < len(lines) && tableHeader.MatchString(lines[insertAt]) {
+		inserted = append(inserted, "")
+	}
+	return joinLines(append(append(lines[:insertAt:insertAt], inserted...), lines[insertAt:]...))
+}
+
+func joinLines(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	userPath    = "/home/user/.config/claudex/config.toml"
+	projectPath = "/project/.claudex/config.toml"
+)
+
+func origin(t *testing.T, l *Layered, key string) Origin {
+	t.Helper()
+	v, err := l.Get(key)
+	require.NoError(t, err)
+	return v.Origin
+}
+
+// TestLoadLayered_Precedence verifies each layer overrides the ones below it
+// and records where every value came from
+func TestLoadLayered_Precedence(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile(userPath, `no_overwrite = true
+
+[features]
+autodoc_frequency = 8
+autodoc_session_end = false
+
+[llm]
+backend = "anthropic"
+`)
+	h.WriteFile(projectPath, `[features]
+autodoc_frequency = 12
+
+[models.agents]
+architect = "sonnet"
+`)
+	h.Env.Set("CLAUDEX_LLM_BACKEND", "openai")
+	h.Env.Set("CLAUDEX_MODEL_AGENT_ARCHITECT", "opus")
+	h.Env.Set("CLAUDEX_SESSIONS_ARCHIVE_ON_FRESH", "false")
+	h.Env.Set("CLAUDEX_RETENTION_MAX_AGE_DAYS", "30")
+
+	l, err := LoadLayered(h.FS, Sources{UserPath: userPath, ProjectPath: projectPath, Env: h.Env})
+	require.NoError(t, err)
+	require.NoError(t, l.ApplyFlag("no_overwrite", "no-overwrite", false))
+
+	assert.Equal(t, 12, l.Config.Features.AutodocFrequency)
+	assert.Equal(t, Origin{Layer: LayerProject, Source: projectPath}, origin(t, l, "features.autodoc_frequency"))
+
+	assert.False(t, l.Config.Features.AutodocSessionEnd)
+	assert.Equal(t, LayerUser, origin(t, l, "features.autodoc_session_end").Layer)
+
+	assert.True(t, l.Config.Features.AutodocSessionProgress)
+	assert.Equal(t, Origin{Layer: LayerDefault}, origin(t, l, "features.autodoc_session_progress"))
+
+	assert.Equal(t, "openai", l.Config.LLM.Backend)
+	assert.Equal(t, Origin{Layer: LayerEnv, Source: "CLAUDEX_LLM_BACKEND"}, origin(t, l, "llm.backend"))
+
+	assert.Equal(t, "opus", l.Config.Models.Agents["architect"])
+	assert.Equal(t, LayerEnv, origin(t, l, "models.agents.architect").Layer)
+
+	assert.False(t, l.Config.Sessions.ArchiveOnFresh)
+	assert.Equal(t, Origin{Layer: LayerEnv, Source: "CLAUDEX_SESSIONS_ARCHIVE_ON_FRESH"}, origin(t, l, "sessions.archive_on_fresh"))
+
+	assert.Equal(t, 30, l.Config.Retention.MaxAgeDays)
+	assert.Equal(t, Origin{Layer: LayerEnv, Source: "CLAUDEX_RETENTION_MAX_AGE_DAYS"}, origin(t, l, "retention.max_age_days"))
+
+	assert.False(t, l.Config.NoOverwrite)
+	assert.Equal(t, Origin{Layer: LayerFlag, Source: "--no-overwrite"}, origin(t, l, "no_overwrite"))
+}
+
+// TestLoadLayered_InvalidEnvIgnored verifies unparsable env values keep the
+// lower layer's value
+func TestLoadLayered_InvalidEnvIgnored(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("CLAUDEX_AUTODOC_FREQUENCY", "often")
+
+	l, err := LoadLayered(h.FS, Sources{Env: h.Env})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, l.Config.Features.AutodocFrequency)
+	assert.Equal(t, LayerDefault, origin(t, l, "features.autodoc_frequency").Layer)
+}
+
+// TestLoadLayered_MalformedFile_NamesFile verifies parse errors say which layer failed
+func TestLoadLayered_MalformedFile_NamesFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, userPath, []byte("[features\n"), 0644))
+
+	_, err := LoadLayered(fs, Sources{UserPath: userPath, ProjectPath: projectPath})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), userPath)
+}
+
+// TestValues_ListsEveryKey verifies explain output covers all keys, agents last
+func TestValues_ListsEveryKey(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, projectPath, []byte("[models.agents]\nteam-lead = \"opus\"\n"), 0644))
+
+	l, err := LoadLayered(fs, Sources{ProjectPath: projectPath})
+	require.NoError(t, err)
+
+	values := l.Values()
+	require.Len(t, values, len(Keys())+1)
+	assert.Equal(t, "doc", values[0].Key)
+	last := values[len(values)-1]
+	assert.Equal(t, "models.agents.team-lead", last.Key)
+	assert.Equal(t, "CLAUDEX_MODEL_AGENT_TEAM_LEAD", last.Env)
+	assert.Contains(t, Keys(), "models.index_update")
+	assert.Equal(t, "CLAUDEX_MODEL_INDEX_UPDATE", EnvVar("models.index_update"))
+	assert.Empty(t, EnvVar("doc"))
+}
+
+func TestGet_UnknownKey(t *testing.T) {
+	l, err := LoadLayered(afero.NewMemMapFs(), Sources{})
+	require.NoError(t, err)
+
+	_, err = l.Get("features.autodoc")
+	assert.EqualError(t, err, "unknown config key: features.autodoc")
+}
+
+func TestParse(t *testing.T) {
+	v, err := Parse("features.autodoc_session_end", "false")
+	require.NoError(t, err)
+	assert.Equal(t, false, v)
+
+	v, err = Parse("doc", "docs", "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs", "README.md"}, v)
+
+	_, err = Parse("llm.max_tokens", "lots")
+	assert.EqualError(t, err, `llm.max_tokens must be a whole number, got "lots"`)
+
+	_, err = Parse("nope", "1")
+	assert.Error(t, err)
+}
+
+// TestSetValue_PreservesCommentsAndOtherKeys verifies set edits only the target line
+func TestSetValue_PreservesCommentsAndOtherKeys(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, projectPath, []byte(`# Team settings
+doc = [
+  "docs",
+]
+
+[features]
+autodoc_frequency = 5 # every five edits
+autodoc_session_end = true
+`), 0644))
+
+	require.NoError(t, SetValue(fs, projectPath, "features.autodoc_frequency", 10))
+	require.NoError(t, SetValue(fs, projectPath, "doc", []string{"docs", "guides"}))
+	require.NoError(t, SetValue(fs, projectPath, "no_overwrite", true))
+	require.NoError(t, SetValue(fs, projectPath, "models.agents.architect", "sonnet"))
+
+	data, err := afero.ReadFile(fs, projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, `# Team settings
+doc = ["docs", "guides"]
+no_overwrite = true
+
+[features]
+autodoc_frequency = 10
+autodoc_session_end = true
+
+[models.agents]
+architect = "sonnet"
+`, string(data))
+
+	cfg, err := Load(fs, projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.Features.AutodocFrequency)
+	assert.Equal(t, "sonnet", cfg.Models.Agents["architect"])
+}
+
+// TestSetValue_CreatesFile verifies set creates a missing user config
+func TestSetValue_CreatesFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	require.NoError(t, SetValue(fs, userPath, "llm.backend", "anthropic"))
+
+	data, err := afero.ReadFile(fs, userPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[llm]\nbackend = \"anthropic\"\n", string(data))
+}
+
+func TestUserPath(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/user")
+	assert.Equal(t, userPath, UserPath(h.Env))
+
+	h.Env.Set("XDG_CONFIG_HOME", "/xdg")
+	assert.Equal(t, "/xdg/claudex/config.toml", UserPath(h.Env))
+}
+
+func TestWriteValues(t *testing.T) {
+	values := []Value{
+		{Key: "features.autodoc_frequency", Value: 12, Origin: Origin{Layer: LayerProject, Source: projectPath}},
+		{Key: "llm.backend", Value: "", Origin: Origin{Layer: LayerDefault}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteValues(&buf, values))
+
+	out := buf.String()
+	assert.Contains(t, out, "KEY")
+	assert.Contains(t, out, "features.autodoc_frequency  12     project ("+projectPath+")")
+	assert.Contains(t, out, `llm.backend                 ""     default`)
+}
+
+// TestSetValue_TopLevelKeyBeforeTables verifies top-level keys stay above the first table
+func TestSetValue_TopLevelKeyBeforeTables(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, projectPath, []byte("[llm]\nbackend = \"anthropic\"\n"), 0644))
+
+	require.NoError(t, SetValue(fs, projectPath, "no_overwrite", true))
+
+	data, err := afero.ReadFile(fs, projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, "no_overwrite = true\n\n[llm]\nbackend = \"anthropic\"\n", string(data))
+}
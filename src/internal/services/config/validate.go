@@ -0,0 +1,273 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/afero"
+)
+
+// Problem is a config entry that was ignored or looks wrong
+type Problem struct {
+	Source  string `json:"source"` // File path or env var
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Source, p.Key, p.Message)
+}
+
+// decodeFile decodes data over cfg. Known keys holding the wrong type are
+// dropped instead of failing the whole file, and keys cfg has no field for
+// are reported from the TOML metadata; only syntax errors are returned.
+func decodeFile(data, path string, cfg *Config) (toml.MetaData, []Problem, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(data, &raw); err != nil {
+		return toml.MetaData{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	problems := checkTypes(raw, reflect.TypeOf(Config{}), "", path)
+	if len(problems) > 0 {
+		var buf strings.Builder
+		if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+			return toml.MetaData{}, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		data = buf.String()
+	}
+
+	md, err := toml.Decode(data, cfg)
+	if err != nil {
+		return toml.MetaData{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, key := range md.Undecoded() {
+		if md.Type(key...) == "Hash" {
+			continue // Its keys are reported one by one
+		}
+		problems = append(problems, unknownKey(path, key.String()))
+	}
+	return md, problems, nil
+}
+
+// checkTypes removes the entries of table whose value doesn't fit the field
+// with the same toml tag in t
+func checkTypes(table map[string]interface{}, t reflect.Type, prefix, path string) []Problem {
+	var problems []Problem
+	for _, name := range sortedKeys(table) {
+		f, ok := fieldByTag(t, name)
+		if !ok {
+			continue // Reported as undecoded
+		}
+		key := prefix + name
+		value := table[name]
+
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			sub, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, Problem{path, key, fmt.Sprintf("must be a table, got %s", tomlType(value))})
+				delete(table, name)
+				continue
+			}
+			problems = append(problems, checkTypes(sub, f.Type, key+".", path)...)
+		case reflect.Map:
+			sub, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, Problem{path, key, fmt.Sprintf("must be a table, got %s", tomlType(value))})
+				delete(table, name)
+				continue
+			}
+			for _, entry := range sortedKeys(sub) {
+				if _, ok := sub[entry].(string); !ok {
+					problems = append(problems, Problem{path, key + "." + entry, fmt.Sprintf("must be a string, got %s", tomlType(sub[entry]))})
+					delete(sub, entry)
+				}
+			}
+		default:
+			if !fits(value, f.Type) {
+				problems = append(problems, Problem{path, key, fmt.Sprintf("must be %s, got %s", tomlKind(f.Type), tomlType(value))})
+				delete(table, name)
+			}
+		}
+	}
+	return problems
+}
+
+func sortedKeys(table map[string]interface{}) []string {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("toml") == tag {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func fits(value interface{}, t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool:
+		_, ok := value.(bool)
+		return ok
+	case reflect.Int:
+		_, ok := value.(int64)
+		return ok
+	case reflect.String:
+		_, ok := value.(string)
+		return ok
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// tomlKind describes the value a field expects
+func tomlKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int:
+		return "an integer"
+	case reflect.Slice:
+		return "a list of strings"
+	}
+	return "a string"
+}
+
+// tomlType describes a decoded TOML value
+func tomlType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "a boolean"
+	case int64:
+		return "an integer"
+	case float64:
+		return "a float"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case []interface{}, []map[string]interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "a table"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// unknownKey reports a key no field decodes, suggesting the closest known key
+func unknownKey(source, key string) Problem {
+	message := "unknown key"
+	best, bestDist := "", 3 // Only suggest keys within two edits
+	for _, known := range Keys() {
+		if d := editDistance(key, known); d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+	if best != "" {
+		message += fmt.Sprintf(" (did you mean %s?)", best)
+	}
+	return Problem{Source: source, Key: key, Message: message}
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// Validate returns the problems found while loading followed by values out
+// of range and doc or launch.add_dirs entries that don't exist. Relative
+// paths are resolved against projectDir.
+func (l *Layered) Validate(fs afero.Fs, projectDir string) []Problem {
+	problems := append([]Problem{}, l.problems...)
+
+	checkMin := func(key string, value, min int) {
+		if value < min {
+			problems = append(problems, Problem{l.source(key), key, fmt.Sprintf("must be at least %d, got %d", min, value)})
+		}
+	}
+	checkMin("features.autodoc_frequency", l.Config.Features.AutodocFrequency, 1)
+	checkMin("llm.max_tokens", l.Config.LLM.MaxTokens, 0)
+	checkMin("llm.timeout_seconds", l.Config.LLM.TimeoutSeconds, 0)
+	checkMin("retention.max_age_days", l.Config.Retention.MaxAgeDays, 0)
+	checkMin("retention.max_count", l.Config.Retention.MaxCount, 0)
+
+	checkPaths := func(key string, entries []string) {
+		for _, entry := range entries {
+			path := entry
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(projectDir, path)
+			}
+			if _, err := fs.Stat(path); err != nil {
+				problems = append(problems, Problem{l.source(key), key, fmt.Sprintf("%s does not exist", entry)})
+			}
+		}
+	}
+	checkPaths("doc", l.Config.Doc)
+	checkPaths("launch.add_dirs", l.Config.Launch.AddDirs)
+	return problems
+}
+
+// source names where key was set, for problem reports
+func (l *Layered) source(key string) string {
+	origin, ok := l.origins[key]
+	if !ok {
+		return string(LayerDefault)
+	}
+	if origin.Source == "" {
+		return string(origin.Layer)
+	}
+	return origin.Source
+}
+
+// WriteProblems writes one line per problem
+func WriteProblems(w io.Writer, problems []Problem) {
+	for _, p := range problems {
+		fmt.Fprintf(w, "✗ %s\n", p)
+	}
+}
+
+// WriteProblemsJSON writes the problems as a JSON array
+func WriteProblemsJSON(w io.Writer, problems []Problem) error {
+	if problems == nil {
+		problems = []Problem{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(problems)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validate(t *testing.T, fs afero.Fs, src Sources) (*Layered, []Problem) {
+	t.Helper()
+	l, err := LoadLayered(fs, src)
+	require.NoError(t, err)
+	return l, l.Validate(fs, "/project")
+}
+
+// TestValidate_UnknownKeySuggestsClosest verifies typos are reported instead of dropped silently
+func TestValidate_UnknownKeySuggestsClosest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, projectPath, []byte(`[features]
+autodoc_frequncy = 10
+
+[telemetry]
+enabled = true
+`), 0644))
+
+	l, problems := validate(t, fs, Sources{ProjectPath: projectPath})
+
+	require.Len(t, problems, 2)
+	assert.Equal(t, Problem{projectPath, "features.autodoc_frequncy", "unknown key (did you mean features.autodoc_frequency?)"}, problems[0])
+	assert.Equal(t, Problem{projectPath, "telemetry.enabled", "unknown key"}, problems[1])
+	assert.Equal(t, 5, l.Config.Features.AutodocFrequency)
+}
+
+// TestValidate_WrongTypeKeepsRestOfFile verifies a mistyped value is skipped
+// without discarding the other keys in the file
+func TestValidate_WrongTypeKeepsRestOfFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, projectPath, []byte(`doc = "docs/index.md"
+no_overwrite = true
+
+[features]
+autodoc_frequency = "10"
+autodoc_session_end = false
+
+[models.agents]
+architect = 3
+team-lead = "opus"
+`), 0644))
+
+	l, problems := validate(t, fs, Sources{ProjectPath: projectPath})
+
+	require.Len(t, problems, 3)
+	assert.Equal(t, Problem{projectPath, "doc", `must be a list of strings, got string "docs/index.md"`}, problems[0])
+	assert.Equal(t, Problem{projectPath, "features.autodoc_frequency", `must be an integer, got string "10"`}, problems[1])
+	assert.Equal(t, Problem{projectPath, "models.agents.architect", "must be a string, got an integer"}, problems[2])
+
+	assert.True(t, l.Config.NoOverwrite)
+	assert.False(t, l.Config.Features.AutodocSessionEnd)
+	assert.Equal(t, 5, l.Config.Features.AutodocFrequency)
+	assert.Equal(t, LayerDefault, origin(t, l, "features.autodoc_frequency").Layer)
+	assert.Equal(t, map[string]string{"team-lead": "opus"}, l.Config.Models.Agents)
+}
+
+// TestValidate_RangesAndDocPaths verifies out-of-range values and missing doc
+// entries are reported against the layer that set them
+func TestValidate_RangesAndDocPaths(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.WriteFile(userPath, "[llm]\nmax_tokens = -1\n")
+	h.WriteFile(projectPath, "doc = [\"docs/index.md\", \"docs/missing.md\"]\n\n[features]\nautodoc_frequency = 0\n\n[launch]\nadd_dirs = [\"docs\", \"../api\"]\n")
+	h.WriteFile("/project/docs/index.md", "# Docs")
+
+	_, problems := validate(t, h.FS, Sources{UserPath: userPath, ProjectPath: projectPath})
+
+	assert.Equal(t, []Problem{
+		{projectPath, "features.autodoc_frequency", "must be at least 1, got 0"},
+		{userPath, "llm.max_tokens", "must be at least 0, got -1"},
+		{projectPath, "doc", "docs/missing.md does not exist"},
+		{projectPath, "launch.add_dirs", "../api does not exist"},
+	}, problems)
+}
+
+// TestValidate_InvalidEnvValue verifies unparsable env overrides are reported
+func TestValidate_InvalidEnvValue(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("CLAUDEX_AUTODOC_SESSION_END", "sometimes")
+
+	_, problems := validate(t, h.FS, Sources{Env: h.Env})
+
+	assert.Equal(t, []Problem{
+		{"CLAUDEX_AUTODOC_SESSION_END", "features.autodoc_session_end", `must be true or false, got "sometimes"`},
+	}, problems)
+}
+
+func TestValidate_CleanConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, projectPath, []byte("[features]\nautodoc_frequency = 3\n"), 0644))
+
+	_, problems := validate(t, fs, Sources{ProjectPath: projectPath})
+
+	assert.Empty(t, problems)
+}
+
+func TestWriteProblems(t *testing.T) {
+	problems := []Problem{{projectPath, "features.autodoc_frequncy", "unknown key"}}
+
+	var buf bytes.Buffer
+	WriteProblems(&buf, problems)
+	assert.Equal(t, "✗ "+projectPath+": features.autodoc_frequncy: unknown key\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteProblemsJSON(&buf, nil))
+	var decoded []Problem
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Empty(t, decoded)
+	assert.Equal(t, "[]\n", buf.String())
+}
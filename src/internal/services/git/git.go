@@ -25,6 +25,10 @@ type GitService interface {
 	// GetMergeBase returns the merge base between HEAD and the specified branch
 	// Used as fallback when base commit is unreachable (e.g., after rebase)
 	GetMergeBase(branch string) (string, error)
+
+	// GetCurrentBranch returns the name of the checked out branch
+	// Returns "HEAD" when the HEAD is detached
+	GetCurrentBranch() (string, error)
 }
 
 // OsGitService is the production implementation of GitService
@@ -75,6 +79,15 @@ func (s *OsGitService) GetMergeBase(branch string) (string, error) {
 	return trimOutput(output), nil
 }
 
+// GetCurrentBranch returns the name of the checked out branch
+func (s *OsGitService) GetCurrentBranch() (string, error) {
+	output, err := s.cmdr.Run("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return trimOutput(output), nil
+}
+
 // trimOutput removes leading and trailing whitespace from command output
 func trimOutput(output []byte) string {
 	return strings.TrimSpace(string(output))
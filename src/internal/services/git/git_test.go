@@ -3,6 +3,7 @@ package git
 import (
 	"errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -22,6 +23,10 @@ func (m *mockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Wr
 	return errors.New("Start not implemented in mock")
 }
 
+func (m *mockCommander) StartIn(dir, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return errors.New("StartIn not implemented in mock")
+}
+
 func TestGetCurrentSHA_Success(t *testing.T) {
 	expectedSHA := "abc123def456"
 	mock := &mockCommander{
@@ -338,6 +343,27 @@ func TestGetMergeBase_TrimWhitespace(t *testing.T) {
 	}
 }
 
+func TestGetCurrentBranch_Success(t *testing.T) {
+	mock := &mockCommander{
+		runFunc: func(name string, args ...string) ([]byte, error) {
+			if strings.Join(args, " ") != "rev-parse --abbrev-ref HEAD" {
+				t.Errorf("unexpected args: %v", args)
+			}
+			return []byte("feature/login\n"), nil
+		},
+	}
+
+	svc := New(mock)
+	branch, err := svc.GetCurrentBranch()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "feature/login" {
+		t.Errorf("expected branch 'feature/login', got '%s'", branch)
+	}
+}
+
 func TestSplitLines_EdgeCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -22,6 +22,10 @@ func (m *mockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Wr
 	return nil
 }
 
+func (m *mockCommander) StartIn(dir, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return nil
+}
+
 func TestIsGitRepo_ReturnsFalseWhenGitMissing(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	projectDir := "/test/project"
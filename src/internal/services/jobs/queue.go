@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/uuid"
+
+	"github.com/spf13/afero"
+)
+
+// FileService is the production implementation of Service.
+// Each job is stored as {dir}/{id}.json with its output in {dir}/{id}.log.
+type FileService struct {
+	fs      afero.Fs
+	dir     string
+	clock   clock.Clock
+	uuidGen uuid.UUIDGenerator
+}
+
+// New creates a new Service instance rooted at dir
+func New(fs afero.Fs, dir string, clk clock.Clock, uuidGen uuid.UUIDGenerator) Service {
+	return &FileService{
+		fs:      fs,
+		dir:     dir,
+		clock:   clk,
+		uuidGen: uuidGen,
+	}
+}
+
+// Dir returns the queue directory
+func (s *FileService) Dir() string {
+	return s.dir
+}
+
+// LogPath returns the path of the log file for a job
+func (s *FileService) LogPath(id string) string {
+	return filepath.Join(s.dir, id+".log")
+}
+
+// Enqueue persists a new queued job for a worker to execute
+func (s *FileService) Enqueue(req Request) (*Job, error) {
+	job, err := s.newJob(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Begin records a job the calling process is about to run itself
+func (s *FileService) Begin(req Request) (*Job, error) {
+	job, err := s.newJob(req)
+	if err != nil {
+		return nil, err
+	}
+
+	job.State = StateRunning
+	job.Attempts = 1
+	job.MaxAttempts = 1
+	job.PID = os.Getpid()
+	job.Inline = true
+	job.StartedAt = job.CreatedAt
+
+	if err := s.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Finish records the outcome of a job started with Begin
+func (s *FileService) Finish(job *Job, runErr error) error {
+	job.FinishedAt = s.clock.Now().UTC().Format(time.RFC3339)
+	job.ExitCode = exitCode(runErr)
+	if runErr != nil {
+		job.State = StateFailed
+		job.Error = runErr.Error()
+	} else {
+		job.State = StateDone
+		job.Error = ""
+	}
+	return s.Save(job)
+}
+
+// newJob builds a queued job from a request
+func (s *FileService) newJob(req Request) (*Job, error) {
+	data, err := json.Marshal(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	return &Job{
+		ID:          fmt.Sprintf("%s-%s", now.Format("20060102-150405"), shortID(s.uuidGen.New())),
+		Kind:        req.Kind,
+		State:       StateQueued,
+		Target:      req.Target,
+		Model:       req.Model,
+		Payload:     data,
+		MaxAttempts: DefaultMaxAttempts,
+		CreatedAt:   now.Format(time.RFC3339),
+	}, nil
+}
+
+// Get loads a job by ID
+func (s *FileService) Get(id string) (*Job, error) {
+	data, err := afero.ReadFile(s.fs, s.jobPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("job not found: %s", id)
+		}
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// List returns all jobs ordered by creation time.
+// Unreadable job files are skipped.
+func (s *FileService) List() ([]*Job, error) {
+	entries, err := afero.ReadDir(s.fs, s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Job{}, nil
+		}
+		return nil, err
+	}
+
+	jobs := []*Job{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		job, err := s.Get(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt != jobs[j].CreatedAt {
+			return jobs[i].CreatedAt < jobs[j].CreatedAt
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// Save persists the job's current state atomically
+func (s *FileService) Save(job *Job) error {
+	if err := s.fs.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := s.jobPath(job.ID)
+	tempPath := path + ".tmp"
+
+	// Write to temp file first
+	if err := afero.WriteFile(s.fs, tempPath, data, 0644); err != nil {
+		return err
+	}
+
+	// Atomic rename
+	return s.fs.Rename(tempPath, path)
+}
+
+// jobPath returns the JSON file path for a job
+func (s *FileService) jobPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// shortID returns the first segment of a UUID
+func shortID(id string) string {
+	if i := strings.IndexByte(id, '-'); i > 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// exitCode extracts the exit status from a job error.
+// Success is 0; errors that do not wrap a process exit have no code.
+func exitCode(err error) *int {
+	code := 0
+	if err == nil {
+		return &code
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+		return &code
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileService_Enqueue_PersistsQueuedJob(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	h.UUIDs = []string{"abcdef12-0000-0000-0000-000000000000"}
+	svc := New(h.FS, "/project/.claudex/jobs", h, h)
+
+	job, err := svc.Enqueue(Request{
+		Kind:    KindSessionOverview,
+		Target:  "/s/session-overview.md",
+		Model:   "haiku",
+		Payload: map[string]string{"sessionPath": "/s"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "20250115-103000-abcdef12", job.ID)
+	assert.Equal(t, StateQueued, job.State)
+	assert.Equal(t, DefaultMaxAttempts, job.MaxAttempts)
+	assert.Equal(t, "2025-01-15T10:30:00Z", job.CreatedAt)
+	assert.Equal(t, "/s/session-overview.md", job.Target)
+	assert.Equal(t, "haiku", job.Model)
+	testutil.AssertFileExists(t, h.FS, "/project/.claudex/jobs/20250115-103000-abcdef12.json")
+
+	loaded, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(loaded.Payload, &payload))
+	assert.Equal(t, "/s", payload["sessionPath"])
+}
+
+func TestFileService_BeginFinish_RecordsSyncRun(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	svc := New(h.FS, "/jobs", h, h)
+
+	job, err := svc.Begin(Request{Kind: KindCreateIndex, Target: "/p/pkg/index.md", Model: "haiku"})
+	require.NoError(t, err)
+	assert.Equal(t, StateRunning, job.State)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, 1, job.MaxAttempts)
+	assert.Equal(t, os.Getpid(), job.PID)
+
+	h.FixedTime = h.FixedTime.Add(time.Minute)
+	require.NoError(t, svc.Finish(job, nil))
+
+	loaded, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, loaded.State)
+	assert.Equal(t, "2025-01-15T10:31:00Z", loaded.FinishedAt)
+	require.NotNil(t, loaded.ExitCode)
+	assert.Equal(t, 0, *loaded.ExitCode)
+}
+
+func TestFileService_Finish_RecordsExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh to produce an exit status")
+	}
+	h := testutil.NewTestHarness()
+	svc := New(h.FS, "/jobs", h, h)
+
+	job, err := svc.Begin(Request{Kind: KindCreateIndex})
+	require.NoError(t, err)
+
+	// A real process exit gives us a status code
+	runErr := exec.Command("sh", "-c", "exit 3").Run()
+	require.Error(t, runErr)
+	require.NoError(t, svc.Finish(job, fmt.Errorf("claude invocation failed: %w", runErr)))
+
+	loaded, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateFailed, loaded.State)
+	require.NotNil(t, loaded.ExitCode)
+	assert.Equal(t, 3, *loaded.ExitCode)
+	assert.Contains(t, loaded.Error, "claude invocation failed")
+}
+
+func TestFileService_Get_NotFound(t *testing.T) {
+	h := testutil.NewTestHarness()
+	svc := New(h.FS, "/project/.claudex/jobs", h, h)
+
+	_, err := svc.Get("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job not found")
+}
+
+func TestFileService_List_OrdersByCreationAndSkipsNoise(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"aaaaaaaa-0", "bbbbbbbb-0"}
+	svc := New(h.FS, "/jobs", h, h)
+
+	h.FixedTime = time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)
+	later, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+	h.FixedTime = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	earlier, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+
+	// Logs, locks and corrupt files are ignored
+	h.WriteFile("/jobs/worker.lock", "123")
+	h.WriteFile("/jobs/"+earlier.ID+".log", "output")
+	h.WriteFile("/jobs/corrupt.json", "{not json")
+
+	jobs, err := svc.List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, earlier.ID, jobs[0].ID)
+	assert.Equal(t, later.ID, jobs[1].ID)
+}
+
+func TestFileService_List_MissingDir(t *testing.T) {
+	h := testutil.NewTestHarness()
+	svc := New(h.FS, "/nonexistent/jobs", h, h)
+
+	jobs, err := svc.List()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestFileService_Save_NoTempFileRemains(t *testing.T) {
+	h := testutil.NewTestHarness()
+	svc := New(h.FS, "/jobs", h, h)
+
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+	job.State = StateDone
+	require.NoError(t, svc.Save(job))
+
+	exists, err := afero.Exists(h.FS, "/jobs/"+job.ID+".json.tmp")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	loaded, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, loaded.State)
+}
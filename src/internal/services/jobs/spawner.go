@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"claudex/internal/services/env"
+	"claudex/internal/services/process"
+)
+
+// WorkerCommand is the claudex-hooks subcommand that runs a queue worker
+const WorkerCommand = "job-worker"
+
+// ProcessSpawner is the production implementation of Spawner.
+// It launches `claudex-hooks job-worker <dir>` as a detached process.
+type ProcessSpawner struct {
+	bin string
+}
+
+// NewSpawner creates a new Spawner that runs the given hooks binary
+func NewSpawner(bin string) Spawner {
+	return &ProcessSpawner{bin: bin}
+}
+
+// Spawn launches a detached worker that drains the queue in dir
+func (s *ProcessSpawner) Spawn(dir string) error {
+	cmd := exec.Command(s.bin, WorkerCommand, dir)
+	_, err := process.StartDetached(cmd)
+	return err
+}
+
+// Variables that override the hooks executable. The Windows proxy scripts
+// check EnvWindowsHooksBin before EnvHooksBin.
+const (
+	EnvHooksBin        = "CLAUDEX_HOOKS_BIN"
+	EnvWindowsHooksBin = "CLAUDEX_WINDOWS_HOOKS_BIN"
+)
+
+// hooksBinaryName returns the name of the hooks executable on goos, as built
+// by the Makefile and build.bat
+func hooksBinaryName(goos string) string {
+	if goos == "windows" {
+		return "claudex-windows-hooks"
+	}
+	return "claudex-hooks"
+}
+
+// LookupHooksBinary resolves the hooks executable the way the hook proxy
+// scripts do: the override variables for goos, then the executable name for
+// PATH lookup. variable names the variable that chose it, or is empty.
+func LookupHooksBinary(environment env.Environment, goos string) (bin, variable string) {
+	vars := []string{EnvHooksBin}
+	if goos == "windows" {
+		vars = []string{EnvWindowsHooksBin, EnvHooksBin}
+	}
+	for _, v := range vars {
+		if bin := environment.Get(v); bin != "" {
+			return bin, v
+		}
+	}
+	return hooksBinaryName(goos), ""
+}
+
+// HooksBinary resolves the hooks executable used to run workers. Priority:
+// the override variables, the current executable when it is the hooks
+// binary, a hooks binary next to the current executable, then PATH lookup.
+func HooksBinary(environment env.Environment) string {
+	self, _ := os.Executable()
+	return resolveHooksBinary(environment, runtime.GOOS, self, func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+}
+
+// resolveHooksBinary implements HooksBinary for goos, the path of the current
+// executable (empty when unknown) and a file existence check
+func resolveHooksBinary(environment env.Environment, goos, self string, exists func(path string) bool) string {
+	bin, variable := LookupHooksBinary(environment, goos)
+	if variable != "" || self == "" {
+		return bin
+	}
+
+	ext := ""
+	if goos == "windows" {
+		ext = ".exe"
+	}
+	name := hooksBinaryName(goos)
+	if strings.TrimSuffix(filepath.Base(self), ext) == name {
+		return self
+	}
+	if sibling := filepath.Join(filepath.Dir(self), name+ext); exists(sibling) {
+		return sibling
+	}
+	return bin
+}
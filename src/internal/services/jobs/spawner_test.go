@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveHooksBinary_Windows(t *testing.T) {
+	h := testutil.NewTestHarness()
+	none := func(string) bool { return false }
+
+	// The hooks binary itself, then a sibling, then PATH lookup
+	assert.Equal(t, "C:/claudex/claudex-windows-hooks.exe",
+		resolveHooksBinary(h.Env, "windows", "C:/claudex/claudex-windows-hooks.exe", none))
+	sibling := resolveHooksBinary(h.Env, "windows", "C:/claudex/claudex-windows.exe", func(path string) bool {
+		return filepath.ToSlash(path) == "C:/claudex/claudex-windows-hooks.exe"
+	})
+	assert.Equal(t, "C:/claudex/claudex-windows-hooks.exe", filepath.ToSlash(sibling))
+	assert.Equal(t, "claudex-windows-hooks", resolveHooksBinary(h.Env, "windows", "C:/claudex/claudex-windows.exe", none))
+
+	// The Windows variable wins over the generic one, as in the proxy scripts
+	h.Env.Set(EnvHooksBin, `D:\old\claudex-hooks.exe`)
+	bin, variable := LookupHooksBinary(h.Env, "windows")
+	assert.Equal(t, `D:\old\claudex-hooks.exe`, bin)
+	assert.Equal(t, EnvHooksBin, variable)
+	h.Env.Set(EnvWindowsHooksBin, `D:\bin\claudex-windows-hooks.exe`)
+	assert.Equal(t, `D:\bin\claudex-windows-hooks.exe`, resolveHooksBinary(h.Env, "windows", "C:/claudex/claudex-windows.exe", none))
+}
+
+func TestResolveHooksBinary_Unix(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set(EnvWindowsHooksBin, "/ignored")
+
+	sibling := resolveHooksBinary(h.Env, "linux", "/usr/local/bin/claudex", func(path string) bool {
+		return filepath.ToSlash(path) == "/usr/local/bin/claudex-hooks"
+	})
+	assert.Equal(t, "/usr/local/bin/claudex-hooks", filepath.ToSlash(sibling))
+	assert.Equal(t, "claudex-hooks", resolveHooksBinary(h.Env, "linux", "/usr/local/bin/claudex", func(string) bool { return false }))
+
+	h.Env.Set(EnvHooksBin, "/opt/claudex-hooks")
+	assert.Equal(t, "/opt/claudex-hooks", resolveHooksBinary(h.Env, "linux", "/usr/local/bin/claudex", func(string) bool { return true }))
+}
@@ -0,0 +1,152 @@
+// Package jobs provides a durable, file-backed job queue for background work.
+// Jobs are persisted as JSON files under .claudex/jobs/ and executed by a
+// detached worker process, so work survives the short-lived hook processes
+// that enqueue it.
+package jobs
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// State is the lifecycle state of a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateDone      State = "done"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Finished reports whether the state is terminal
+func (s State) Finished() bool {
+	return s == StateDone || s == StateFailed || s == StateCancelled
+}
+
+// Kind identifies which handler executes a job
+type Kind string
+
+const (
+	// KindSessionOverview updates a session's session-overview.md from its transcript
+	KindSessionOverview Kind = "session-overview"
+
+	// KindIndexUpdate regenerates an index.md after a commit touched its directory
+	KindIndexUpdate Kind = "index-update"
+
+	// KindCreateIndex creates an index.md for a directory (claudex --create-index)
+	KindCreateIndex Kind = "create-index"
+)
+
+// Request describes a job to record
+type Request struct {
+	// Kind selects the handler that executes the job
+	Kind Kind
+
+	// Target is the file the job writes (e.g. an index.md path)
+	Target string
+
+	// Model is the Claude model the job invokes
+	Model string
+
+	// Payload is the handler-specific job input; it is JSON-encoded
+	Payload interface{}
+}
+
+// DefaultMaxAttempts is the number of times a job is tried before it is marked failed
+const DefaultMaxAttempts = 3
+
+// Job represents a unit of background work and its recorded state
+type Job struct {
+	// ID uniquely identifies the job; IDs sort in creation order
+	ID string `json:"id"`
+
+	// Kind selects the handler that executes the job
+	Kind Kind `json:"kind"`
+
+	// State is the current lifecycle state
+	State State `json:"state"`
+
+	// Target is the file the job writes
+	Target string `json:"target,omitempty"`
+
+	// Model is the Claude model the job invokes
+	Model string `json:"model,omitempty"`
+
+	// Payload is the handler-specific job input
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Attempts counts how many times execution has started
+	Attempts int `json:"attempts"`
+
+	// MaxAttempts is the retry budget for the job
+	MaxAttempts int `json:"maxAttempts"`
+
+	// PID is the process currently (or last) executing the job
+	PID int `json:"pid,omitempty"`
+
+	// Inline marks a job recorded with Begin: it runs inside the command that
+	// started it (PID is that command, e.g. an interactive claudex), not a
+	// worker, so it can't be cancelled without killing the command
+	Inline bool `json:"inline,omitempty"`
+
+	// ExitCode is the exit status of the last finished attempt, when known
+	ExitCode *int `json:"exitCode,omitempty"`
+
+	// Error is the error message from the last failed attempt
+	Error string `json:"error,omitempty"`
+
+	// Stderr holds the tail of the output captured during the last attempt
+	Stderr string `json:"stderr,omitempty"`
+
+	// CreatedAt is the RFC3339 timestamp of when the job was enqueued
+	CreatedAt string `json:"createdAt"`
+
+	// StartedAt is the RFC3339 timestamp of when the last attempt started
+	StartedAt string `json:"startedAt,omitempty"`
+
+	// FinishedAt is the RFC3339 timestamp of when the job reached a terminal state
+	FinishedAt string `json:"finishedAt,omitempty"`
+
+	// NotBefore delays a retry until the given RFC3339 timestamp
+	NotBefore string `json:"notBefore,omitempty"`
+}
+
+// Handler executes a job. Anything written to stderr is kept in the job's
+// log file and the tail is recorded on the job.
+type Handler func(job *Job, stderr io.Writer) error
+
+// Service abstracts job persistence for testability
+type Service interface {
+	// Enqueue persists a new queued job for a worker to execute
+	Enqueue(req Request) (*Job, error)
+
+	// Begin records a job the calling process is about to run itself.
+	// The job is created running with a single attempt and no retries.
+	Begin(req Request) (*Job, error)
+
+	// Finish records the outcome of a job started with Begin
+	Finish(job *Job, runErr error) error
+
+	// Get loads a job by ID
+	Get(id string) (*Job, error)
+
+	// List returns all jobs ordered by creation time
+	List() ([]*Job, error)
+
+	// Save persists the job's current state atomically
+	Save(job *Job) error
+
+	// LogPath returns the path of the log file for a job
+	LogPath(id string) string
+
+	// Dir returns the queue directory
+	Dir() string
+}
+
+// Spawner starts a worker process for a queue directory
+type Spawner interface {
+	// Spawn launches a detached worker that drains the queue in dir
+	Spawn(dir string) error
+}
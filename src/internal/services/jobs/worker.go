@@ -0,0 +1,292 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/lock"
+	"claudex/internal/services/process"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// workerLockFile guards the queue so only one worker drains it at a time
+	workerLockFile = "worker.lock"
+
+	// stderrTailSize is how much captured output is kept on the job record
+	stderrTailSize = 4096
+
+	// defaultRetryBackoff is multiplied by the attempt number between retries
+	defaultRetryBackoff = 10 * time.Second
+)
+
+// Worker drains a job queue, executing jobs with their registered handlers
+type Worker struct {
+	fs       afero.Fs
+	svc      Service
+	lockSvc  lock.LockService
+	clock    clock.Clock
+	handlers map[Kind]Handler
+	pid      int
+	backoff  time.Duration
+	alive    func(pid int) bool
+	sleep    func(d time.Duration)
+}
+
+// NewWorker creates a new Worker for the given queue
+func NewWorker(fs afero.Fs, svc Service, lockSvc lock.LockService, clk clock.Clock) *Worker {
+	return &Worker{
+		fs:       fs,
+		svc:      svc,
+		lockSvc:  lockSvc,
+		clock:    clk,
+		handlers: make(map[Kind]Handler),
+		pid:      os.Getpid(),
+		backoff:  defaultRetryBackoff,
+		alive:    process.Alive,
+		sleep:    time.Sleep,
+	}
+}
+
+// Handle registers the handler for a job kind
+func (w *Worker) Handle(kind Kind, h Handler) {
+	w.handlers[kind] = h
+}
+
+// Run drains the queue until no queued jobs remain.
+// If another live worker already owns the queue, Run returns immediately.
+func (w *Worker) Run() error {
+	for {
+		l, err := w.acquire()
+		if err != nil {
+			// Another worker owns the queue and will pick up our jobs
+			return nil
+		}
+
+		w.recoverOrphans()
+		err = w.drain()
+		l.Release()
+		if err != nil {
+			return err
+		}
+
+		// A job may have been enqueued after the last check but before the
+		// lock was released; its spawned worker would have found us holding
+		// the lock and exited, so go around again.
+		if !w.hasQueued() {
+			return nil
+		}
+	}
+}
+
+// acquire takes the worker lock, clearing it first if its holder has died
+func (w *Worker) acquire() (*lock.Lock, error) {
+	lockPath := filepath.Join(w.svc.Dir(), workerLockFile)
+	if err := w.fs.MkdirAll(w.svc.Dir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	l, err := w.lockSvc.Acquire(lockPath)
+	if err == nil {
+		return l, nil
+	}
+
+	if pid := readPID(w.fs, lockPath); pid > 0 && w.alive(pid) {
+		return nil, err
+	}
+
+	// Stale lock from a crashed worker
+	if rmErr := w.fs.Remove(lockPath); rmErr != nil && !os.IsNotExist(rmErr) {
+		return nil, fmt.Errorf("failed to remove stale worker lock: %w", rmErr)
+	}
+	return w.lockSvc.Acquire(lockPath)
+}
+
+// recoverOrphans requeues running jobs whose worker process has died
+func (w *Worker) recoverOrphans() {
+	jobs, err := w.svc.List()
+	if err != nil {
+		return
+	}
+
+	for _, job := range jobs {
+		if job.State != StateRunning || job.PID == w.pid || w.alive(job.PID) {
+			continue
+		}
+		w.finishAttempt(job, fmt.Errorf("worker process %d exited before the job finished", job.PID), job.Stderr)
+	}
+}
+
+// drain executes runnable jobs until the queue is empty, waiting out retry delays
+func (w *Worker) drain() error {
+	for {
+		job, wait, err := w.next()
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			if wait <= 0 {
+				return nil
+			}
+			w.sleep(wait)
+			continue
+		}
+
+		if err := w.execute(job); err != nil {
+			return err
+		}
+	}
+}
+
+// next returns the oldest runnable queued job, or the time until one becomes runnable
+func (w *Worker) next() (*Job, time.Duration, error) {
+	jobs, err := w.svc.List()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	now := w.clock.Now()
+	var wait time.Duration
+	for _, job := range jobs {
+		if job.State != StateQueued {
+			continue
+		}
+		if job.NotBefore == "" {
+			return job, 0, nil
+		}
+		notBefore, err := time.Parse(time.RFC3339, job.NotBefore)
+		if err != nil || !notBefore.After(now) {
+			return job, 0, nil
+		}
+		if d := notBefore.Sub(now); wait == 0 || d < wait {
+			wait = d
+		}
+	}
+	return nil, wait, nil
+}
+
+// hasQueued reports whether any job is waiting to run
+func (w *Worker) hasQueued() bool {
+	jobs, err := w.svc.List()
+	if err != nil {
+		return false
+	}
+	for _, job := range jobs {
+		if job.State == StateQueued {
+			return true
+		}
+	}
+	return false
+}
+
+// execute runs a single attempt of a job and records the outcome
+func (w *Worker) execute(job *Job) error {
+	job.State = StateRunning
+	job.Attempts++
+	job.PID = w.pid
+	job.NotBefore = ""
+	job.StartedAt = w.clock.Now().UTC().Format(time.RFC3339)
+	if err := w.svc.Save(job); err != nil {
+		return fmt.Errorf("failed to mark job %s running: %w", job.ID, err)
+	}
+
+	tail := &tailBuffer{max: stderrTailSize}
+	var out io.Writer = tail
+	logFile, err := w.fs.OpenFile(w.svc.LogPath(job.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err == nil {
+		defer logFile.Close()
+		fmt.Fprintf(logFile, "--- attempt %d/%d at %s ---\n", job.Attempts, job.MaxAttempts, job.StartedAt)
+		out = io.MultiWriter(logFile, tail)
+	}
+
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		// Retrying will not help if nothing can run this kind
+		job.Attempts = job.MaxAttempts
+		runErr := fmt.Errorf("no handler registered for job kind %q", job.Kind)
+		fmt.Fprintln(out, runErr)
+		return w.finishAttempt(job, runErr, tail.String())
+	}
+
+	runErr := runHandler(handler, job, out)
+	if runErr != nil {
+		fmt.Fprintf(out, "error: %v\n", runErr)
+	}
+	return w.finishAttempt(job, runErr, tail.String())
+}
+
+// finishAttempt records the result of an attempt, scheduling a retry if budget remains
+func (w *Worker) finishAttempt(job *Job, runErr error, stderr string) error {
+	now := w.clock.Now().UTC()
+	job.Stderr = stderr
+	job.ExitCode = exitCode(runErr)
+
+	switch {
+	case runErr == nil:
+		job.State = StateDone
+		job.Error = ""
+		job.FinishedAt = now.Format(time.RFC3339)
+	case job.Attempts < job.MaxAttempts:
+		job.State = StateQueued
+		job.Error = runErr.Error()
+		job.NotBefore = now.Add(w.backoff * time.Duration(job.Attempts)).Format(time.RFC3339)
+	default:
+		job.State = StateFailed
+		job.Error = runErr.Error()
+		job.FinishedAt = now.Format(time.RFC3339)
+	}
+
+	if err := w.svc.Save(job); err != nil {
+		return fmt.Errorf("failed to record result of job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// runHandler invokes a handler, converting a panic into an error so one bad
+// job cannot take down the worker
+func runHandler(h Handler, job *Job, stderr io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return h(job, stderr)
+}
+
+// readPID reads the PID written into a lock file, returning 0 if unreadable
+func readPID(fs afero.Fs, path string) int {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// tailBuffer keeps only the last max bytes written to it
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"claudex/internal/services/lock"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWorker creates a worker over an in-memory queue with no live processes
+// and a sleep that advances the harness clock instead of blocking
+func newTestWorker(h *testutil.TestHarness) (*Worker, Service) {
+	h.FixedTime = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	svc := New(h.FS, "/project/.claudex/jobs", h, h)
+	w := NewWorker(h.FS, svc, lock.New(h.FS), h)
+	w.alive = func(pid int) bool { return false }
+	w.sleep = func(d time.Duration) { h.FixedTime = h.FixedTime.Add(d) }
+	return w, svc
+}
+
+func TestWorker_Run_CompletesJob(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+
+	w.Handle(KindSessionOverview, func(job *Job, stderr io.Writer) error {
+		fmt.Fprint(stderr, "working")
+		return nil
+	})
+
+	require.NoError(t, w.Run())
+
+	done, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, done.State)
+	assert.Equal(t, 1, done.Attempts)
+	assert.Equal(t, "working", done.Stderr)
+	assert.NotEmpty(t, done.FinishedAt)
+	testutil.AssertFileContains(t, h.FS, svc.LogPath(job.ID), "working")
+
+	// Worker lock is released when the queue is drained
+	testutil.AssertNoFileExists(t, h.FS, "/project/.claudex/jobs/worker.lock")
+}
+
+func TestWorker_Run_RetriesThenFails(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+
+	calls := 0
+	w.Handle(KindSessionOverview, func(job *Job, stderr io.Writer) error {
+		calls++
+		fmt.Fprintf(stderr, "claude: rate limited (%d)", calls)
+		return errors.New("claude command failed")
+	})
+
+	require.NoError(t, w.Run())
+
+	failed, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxAttempts, calls)
+	assert.Equal(t, StateFailed, failed.State)
+	assert.Equal(t, DefaultMaxAttempts, failed.Attempts)
+	assert.Equal(t, "claude command failed", failed.Error)
+	assert.Contains(t, failed.Stderr, "rate limited (3)")
+	testutil.AssertFileContains(t, h.FS, svc.LogPath(job.ID), "attempt 3/3")
+}
+
+func TestWorker_Run_RetrySucceeds(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+
+	calls := 0
+	w.Handle(KindSessionOverview, func(job *Job, stderr io.Writer) error {
+		calls++
+		if calls == 1 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+
+	require.NoError(t, w.Run())
+
+	done, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, done.State)
+	assert.Equal(t, 2, done.Attempts)
+	assert.Empty(t, done.Error)
+}
+
+func TestWorker_Run_UnknownKindFailsWithoutRetry(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+
+	job, err := svc.Enqueue(Request{Kind: Kind("mystery")})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Run())
+
+	failed, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateFailed, failed.State)
+	assert.Contains(t, failed.Error, "no handler registered")
+}
+
+func TestWorker_Run_RecoversPanic(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+	job.MaxAttempts = 1
+	require.NoError(t, svc.Save(job))
+
+	w.Handle(KindSessionOverview, func(job *Job, stderr io.Writer) error {
+		panic("boom")
+	})
+
+	require.NoError(t, w.Run())
+
+	failed, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateFailed, failed.State)
+	assert.Contains(t, failed.Error, "boom")
+}
+
+func TestWorker_Run_ExitsWhenAnotherWorkerIsAlive(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+	w.alive = func(pid int) bool { return pid == 4242 }
+
+	h.WriteFile("/project/.claudex/jobs/worker.lock", "4242\n")
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+
+	called := false
+	w.Handle(KindSessionOverview, func(job *Job, stderr io.Writer) error {
+		called = true
+		return nil
+	})
+
+	require.NoError(t, w.Run())
+
+	assert.False(t, called, "job belongs to the live worker")
+	queued, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, queued.State)
+}
+
+func TestWorker_Run_ClearsStaleLockAndRequeuesOrphans(t *testing.T) {
+	h := testutil.NewTestHarness()
+	w, svc := newTestWorker(h)
+
+	// A previous worker crashed mid-job, leaving its lock and a running job behind
+	h.WriteFile("/project/.claudex/jobs/worker.lock", "999\n")
+	job, err := svc.Enqueue(Request{Kind: KindSessionOverview})
+	require.NoError(t, err)
+	job.State = StateRunning
+	job.Attempts = 1
+	job.PID = 999
+	require.NoError(t, svc.Save(job))
+
+	w.Handle(KindSessionOverview, func(job *Job, stderr io.Writer) error {
+		return nil
+	})
+
+	require.NoError(t, w.Run())
+
+	done, err := svc.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, done.State)
+	assert.Equal(t, 2, done.Attempts)
+}
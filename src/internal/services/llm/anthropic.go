@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// defaultAnthropicBaseURL is the public Anthropic API endpoint
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+
+	// defaultAnthropicKeyEnv holds the API key unless [llm] api_key_env says otherwise
+	defaultAnthropicKeyEnv = "ANTHROPIC_API_KEY"
+
+	// anthropicVersion is the Messages API version header value
+	anthropicVersion = "2023-06-01"
+)
+
+// anthropicModels maps the CLI's model aliases to Messages API model IDs
+var anthropicModels = map[string]string{
+	"haiku":  "claude-haiku-4-5",
+	"sonnet": "claude-sonnet-4-5",
+	"opus":   "claude-opus-4-1",
+}
+
+// Anthropic runs completions against the Anthropic Messages API
+type Anthropic struct {
+	fs       afero.Fs
+	env      env.Environment
+	settings Settings
+	client   *http.Client
+}
+
+// NewAnthropic creates an Anthropic Messages API backend
+func NewAnthropic(fs afero.Fs, environment env.Environment, settings Settings) *Anthropic {
+	if settings.BaseURL == "" {
+		settings.BaseURL = defaultAnthropicBaseURL
+	}
+	if settings.APIKeyEnv == "" {
+		settings.APIKeyEnv = defaultAnthropicKeyEnv
+	}
+	return &Anthropic{
+		fs:       fs,
+		env:      environment,
+		settings: settings,
+		client:   &http.Client{Timeout: settings.Timeout},
+	}
+}
+
+// Name returns the backend name
+func (a *Anthropic) Name() string {
+	return BackendAnthropic
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Model      string `json:"model"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends the prompt as a single user message
+func (a *Anthropic) Complete(req Request) (*Response, error) {
+	apiKey := a.env.Get(a.settings.APIKeyEnv)
+	if apiKey == "" {
+		return nil, fmt.Errorf("anthropic backend requires an API key in $%s", a.settings.APIKeyEnv)
+	}
+
+	body := anthropicRequest{
+		Model:     a.model(req.Model),
+		MaxTokens: a.settings.MaxTokens,
+		Messages:  []anthropicMessage{{Role: "user", Content: filePrompt(a.fs, req)}},
+	}
+	headers := map[string]string{
+		"x-api-key":         apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var resp anthropicResponse
+	url := strings.TrimRight(a.settings.BaseURL, "/") + "/v1/messages"
+	started := time.Now()
+	if err := postJSON(a.client, url, headers, body, &resp, req.Stderr); err != nil {
+		return nil, err
+	}
+
+	result := &Response{Model: body.Model, Usage: Usage{
+		InputTokens:         resp.Usage.InputTokens,
+		OutputTokens:        resp.Usage.OutputTokens,
+		CacheCreationTokens: resp.Usage.CacheCreationInputTokens,
+		CacheReadTokens:     resp.Usage.CacheReadInputTokens,
+		DurationMS:          time.Since(started).Milliseconds(),
+	}}
+	if resp.Model != "" {
+		result.Model = resp.Model
+	}
+	result.Usage.CostUSD = EstimateCost(body.Model, result.Usage)
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	result.Text = text.String()
+	if resp.StopReason == "max_tokens" && req.OutputPath != "" {
+		return result, fmt.Errorf("reply for %s was truncated at %d tokens", req.OutputPath, a.settings.MaxTokens)
+	}
+
+	if err := writeOutput(a.fs, req, result.Text); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// model resolves the model ID for a request
+func (a *Anthropic) model(requested string) string {
+	model := requested
+	if a.settings.Model != "" {
+		model = a.settings.Model
+	}
+	if model == "" {
+		model = "haiku"
+	}
+	if id, ok := anthropicModels[model]; ok {
+		return id
+	}
+	return model
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"claudex/internal/services/commander"
+	"claudex/internal/services/env"
+)
+
+// ClaudeCLI runs completions through the local `claude -p` CLI.
+// The prompt is passed on stdin to stay clear of command-line length limits.
+type ClaudeCLI struct {
+	cmd   commander.Commander
+	env   env.Environment
+	model string
+}
+
+// NewClaudeCLI creates a Claude CLI backend. A non-empty model overrides
+// the model of every request.
+func NewClaudeCLI(cmd commander.Commander, environment env.Environment, model string) *ClaudeCLI {
+	return &ClaudeCLI{
+		cmd:   cmd,
+		env:   environment,
+		model: model,
+	}
+}
+
+// Name returns the backend name
+func (c *ClaudeCLI) Name() string {
+	return BackendClaudeCLI
+}
+
+// Complete invokes `claude -p` with CLAUDE_HOOK_INTERNAL=1 so hooks fired by
+// the headless session do not recurse back into Claudex. Output is requested
+// as stream-json (which requires --verbose in print mode) so the final
+// result, errors, token usage and cost can be read back.
+func (c *ClaudeCLI) Complete(req Request) (*Response, error) {
+	args := []string{"-p", "--output-format", "stream-json", "--verbose"}
+	model := req.Model
+	if c.model != "" {
+		model = c.model
+	}
+	if model != "" {
+		args = append(args, "--model", model)
+	}
+
+	// Set recursion guard for the child process
+	originalValue := c.env.Get("CLAUDE_HOOK_INTERNAL")
+	c.env.Set("CLAUDE_HOOK_INTERNAL", "1")
+	defer c.env.Set("CLAUDE_HOOK_INTERNAL", originalValue)
+
+	var stdout, stderr bytes.Buffer
+	var errOut io.Writer = &stderr
+	if req.Stderr != nil {
+		errOut = io.MultiWriter(&stderr, req.Stderr)
+	}
+
+	runErr := c.cmd.Start("claude", strings.NewReader(req.Prompt), &stdout, errOut, args...)
+	result, ok := parseStream(stdout.Bytes())
+	if !ok {
+		// No result event: report the exit status, or treat stdout as plain text
+		if runErr != nil {
+			return nil, cliError(runErr, stderr.String())
+		}
+		return &Response{Text: stdout.String()}, nil
+	}
+
+	if result.err != nil {
+		return result.response, result.err
+	}
+	if runErr != nil {
+		return result.response, cliError(runErr, stderr.String())
+	}
+	return result.response, nil
+}
+
+// cliError wraps a failed CLI run with its stderr, if any
+func cliError(err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("claude command failed: %w (stderr: %s)", err, msg)
+	}
+	return fmt.Errorf("claude command failed: %w", err)
+}
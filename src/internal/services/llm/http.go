@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody bounds how much of an error response is quoted back
+const maxErrorBody = 2048
+
+// postJSON sends body as JSON and decodes a successful JSON response into out.
+// Non-2xx responses become errors quoting the start of the response body.
+func postJSON(client *http.Client, url string, headers map[string]string, body, out interface{}, stderr io.Writer) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(respBody))
+		if stderr != nil {
+			fmt.Fprintf(stderr, "%s returned %s: %s\n", url, resp.Status, msg)
+		}
+		if len(msg) > maxErrorBody {
+			msg = msg[:maxErrorBody] + "..."
+		}
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, msg)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
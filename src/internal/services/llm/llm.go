@@ -0,0 +1,197 @@
+// Package llm provides a single abstraction for the headless model calls
+// Claudex makes outside interactive sessions: session naming, session
+// overview updates and index.md generation.
+//
+// Three backends are available:
+//   - claude-cli: the local `claude -p` CLI (default). Agentic; it edits
+//     target files itself using its tools.
+//   - anthropic: the Anthropic Messages API over HTTPS.
+//   - openai: any OpenAI-compatible chat completions endpoint, e.g. a local
+//     model server or a test stub.
+//
+// The HTTP backends are text-only: when a request names an OutputPath they
+// send the file's current content along with the prompt and write the reply
+// to the file themselves.
+package llm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"claudex/internal/services/commander"
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
+)
+
+// Backend names accepted in [llm] backend / CLAUDEX_LLM_BACKEND
+const (
+	BackendClaudeCLI = "claude-cli"
+	BackendAnthropic = "anthropic"
+	BackendOpenAI    = "openai"
+)
+
+// Environment variables carrying backend settings to hooks and job workers.
+// The launcher exports them from config.toml; values already set win.
+const (
+	EnvBackend   = "CLAUDEX_LLM_BACKEND"
+	EnvBaseURL   = "CLAUDEX_LLM_BASE_URL"
+	EnvModel     = "CLAUDEX_LLM_MODEL"
+	EnvAPIKeyEnv = "CLAUDEX_LLM_API_KEY_ENV"
+	EnvMaxTokens = "CLAUDEX_LLM_MAX_TOKENS"
+	EnvTimeout   = "CLAUDEX_LLM_TIMEOUT"
+)
+
+const (
+	// defaultMaxTokens caps the reply of the HTTP backends
+	defaultMaxTokens = 8192
+
+	// defaultTimeout bounds a single HTTP backend request
+	defaultTimeout = 5 * time.Minute
+)
+
+// Request describes a single headless completion
+type Request struct {
+	// Prompt is the full user prompt
+	Prompt string
+
+	// Model is a model alias ("haiku", "sonnet", "opus") or a full model ID.
+	// Empty uses the backend's default.
+	Model string
+
+	// OutputPath is the file this request should produce or update, if any.
+	// The Claude CLI writes it using its own tools; text-only backends
+	// write the reply there.
+	OutputPath string
+
+	// Stderr receives diagnostic output (CLI stderr, HTTP error bodies).
+	// Nil discards it.
+	Stderr io.Writer
+
+	// Feature names what the call is for (a job kind or "session-name").
+	// It tags the call in the usage ledger.
+	Feature string
+
+	// Session is the Claudex session the call belongs to, if any
+	Session string
+}
+
+// Response is the result of a completion
+type Response struct {
+	// Text is the model's reply
+	Text string
+
+	// Model is the model that served the request, when the backend reports it
+	Model string
+
+	// Usage is the token usage and cost of the call
+	Usage Usage
+}
+
+// Usage is the token usage and cost of a single completion
+type Usage struct {
+	InputTokens         int     `json:"inputTokens"`
+	OutputTokens        int     `json:"outputTokens"`
+	CacheCreationTokens int     `json:"cacheCreationTokens,omitempty"`
+	CacheReadTokens     int     `json:"cacheReadTokens,omitempty"`
+	CostUSD             float64 `json:"costUsd"`
+	DurationMS          int64   `json:"durationMs,omitempty"`
+}
+
+// Backend executes headless completions
+type Backend interface {
+	// Name returns the backend name (e.g. "claude-cli")
+	Name() string
+
+	// Complete runs the request and returns the model's reply.
+	// A failed call may still return a Response carrying its usage.
+	Complete(req Request) (*Response, error)
+}
+
+// Settings selects and configures a backend
+type Settings struct {
+	// Backend is one of BackendClaudeCLI, BackendAnthropic or BackendOpenAI.
+	// Empty means BackendClaudeCLI.
+	Backend string
+
+	// BaseURL overrides the HTTP endpoint of the API backends
+	BaseURL string
+
+	// Model, when set, replaces the model of every request
+	Model string
+
+	// APIKeyEnv names the environment variable holding the API key
+	APIKeyEnv string
+
+	// MaxTokens caps the reply length of the HTTP backends
+	MaxTokens int
+
+	// Timeout bounds a single HTTP request
+	Timeout time.Duration
+}
+
+// New creates the backend described by settings
+func New(settings Settings, fs afero.Fs, cmd commander.Commander, environment env.Environment) (Backend, error) {
+	if settings.MaxTokens <= 0 {
+		settings.MaxTokens = defaultMaxTokens
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = defaultTimeout
+	}
+
+	switch strings.ToLower(strings.TrimSpace(settings.Backend)) {
+	case "", BackendClaudeCLI:
+		return NewClaudeCLI(cmd, environment, settings.Model), nil
+	case BackendAnthropic:
+		return NewAnthropic(fs, environment, settings), nil
+	case BackendOpenAI:
+		return NewOpenAI(fs, environment, settings), nil
+	default:
+		return nil, fmt.Errorf("unknown llm backend %q (expected %s, %s or %s)",
+			settings.Backend, BackendClaudeCLI, BackendAnthropic, BackendOpenAI)
+	}
+}
+
+// SettingsFromEnv reads backend settings from the CLAUDEX_LLM_* variables
+func SettingsFromEnv(environment env.Environment) Settings {
+	settings := Settings{
+		Backend:   environment.Get(EnvBackend),
+		BaseURL:   environment.Get(EnvBaseURL),
+		Model:     environment.Get(EnvModel),
+		APIKeyEnv: environment.Get(EnvAPIKeyEnv),
+	}
+	if n, err := strconv.Atoi(environment.Get(EnvMaxTokens)); err == nil {
+		settings.MaxTokens = n
+	}
+	if n, err := strconv.Atoi(environment.Get(EnvTimeout)); err == nil {
+		settings.Timeout = time.Duration(n) * time.Second
+	}
+	return settings
+}
+
+// FromEnv creates the backend selected by the CLAUDEX_LLM_* variables.
+// A misconfigured backend is returned as one whose calls fail with the
+// configuration error, so callers surface it where they report other failures.
+func FromEnv(fs afero.Fs, cmd commander.Commander, environment env.Environment) Backend {
+	backend, err := New(SettingsFromEnv(environment), fs, cmd, environment)
+	if err != nil {
+		return &unavailable{err: err}
+	}
+	return backend
+}
+
+// unavailable is a backend that could not be configured
+type unavailable struct {
+	err error
+}
+
+func (u *unavailable) Name() string {
+	return "unavailable"
+}
+
+func (u *unavailable) Complete(req Request) (*Response, error) {
+	return nil, u.err
+}
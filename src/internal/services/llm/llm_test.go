@@ -0,0 +1,277 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnv_SelectsBackend(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    string
+	}{
+		{"", BackendClaudeCLI},
+		{"claude-cli", BackendClaudeCLI},
+		{"anthropic", BackendAnthropic},
+		{"OpenAI", BackendOpenAI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			h := testutil.NewTestHarness()
+			h.Env.Set(EnvBackend, tt.backend)
+
+			backend := FromEnv(h.FS, h.Commander, h.Env)
+			assert.Equal(t, tt.want, backend.Name())
+		})
+	}
+}
+
+func TestFromEnv_UnknownBackendFailsOnUse(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set(EnvBackend, "gemini")
+
+	backend := FromEnv(h.FS, h.Commander, h.Env)
+	_, err := backend.Complete(Request{Prompt: "hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown llm backend "gemini"`)
+}
+
+func TestSettingsFromEnv(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set(EnvBackend, "openai")
+	h.Env.Set(EnvBaseURL, "http://localhost:9999/v1")
+	h.Env.Set(EnvModel, "llama3")
+	h.Env.Set(EnvAPIKeyEnv, "LOCAL_KEY")
+	h.Env.Set(EnvMaxTokens, "1000")
+	h.Env.Set(EnvTimeout, "30")
+
+	settings := SettingsFromEnv(h.Env)
+	assert.Equal(t, "openai", settings.Backend)
+	assert.Equal(t, "http://localhost:9999/v1", settings.BaseURL)
+	assert.Equal(t, "llama3", settings.Model)
+	assert.Equal(t, "LOCAL_KEY", settings.APIKeyEnv)
+	assert.Equal(t, 1000, settings.MaxTokens)
+	assert.Equal(t, "30s", settings.Timeout.String())
+}
+
+func TestClaudeCLI_Complete(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Commander.OnPattern("claude", "-p").Return([]byte("auth-refactor\n"), nil)
+
+	backend := NewClaudeCLI(h.Commander, h.Env, "")
+	resp, err := backend.Complete(Request{Prompt: "name this", Model: "haiku"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "auth-refactor\n", resp.Text)
+	invocation := h.Commander.LastInvocation()
+	assert.Equal(t, "claude", invocation.Name)
+	assert.Equal(t, []string{"-p", "--output-format", "stream-json", "--verbose", "--model", "haiku"}, invocation.Args)
+	assert.Equal(t, "name this", invocation.Stdin)
+
+	// Recursion guard is restored after the call
+	assert.Empty(t, h.Env.Get("CLAUDE_HOOK_INTERNAL"))
+}
+
+func TestClaudeCLI_ModelOverride(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	backend := NewClaudeCLI(h.Commander, h.Env, "opus")
+	_, err := backend.Complete(Request{Prompt: "x", Model: "haiku"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"-p", "--output-format", "stream-json", "--verbose", "--model", "opus"}, h.Commander.LastInvocation().Args)
+}
+
+func TestClaudeCLI_Complete_ParsesStreamJSON(t *testing.T) {
+	h := testutil.NewTestHarness()
+	stream := `{"type":"system","subtype":"init","model":"claude-haiku-4-5-20251001"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"thinking"}]}}
+{"type":"result","subtype":"success","is_error":false,"result":"auth-refactor","total_cost_usd":0.0123,"duration_ms":2100,"usage":{"input_tokens":120,"output_tokens":8,"cache_creation_input_tokens":300,"cache_read_input_tokens":900}}
+`
+	h.Commander.OnPattern("claude", "-p").Return([]byte(stream), nil)
+
+	backend := NewClaudeCLI(h.Commander, h.Env, "")
+	resp, err := backend.Complete(Request{Prompt: "name this"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "auth-refactor", resp.Text)
+	assert.Equal(t, "claude-haiku-4-5-20251001", resp.Model)
+	assert.Equal(t, Usage{
+		InputTokens:         120,
+		OutputTokens:        8,
+		CacheCreationTokens: 300,
+		CacheReadTokens:     900,
+		CostUSD:             0.0123,
+		DurationMS:          2100,
+	}, resp.Usage)
+}
+
+func TestClaudeCLI_Complete_ErrorResultKeepsUsage(t *testing.T) {
+	h := testutil.NewTestHarness()
+	stream := `{"type":"result","subtype":"error_max_turns","is_error":true,"total_cost_usd":0.5,"usage":{"input_tokens":1000,"output_tokens":50}}`
+	h.Commander.OnPattern("claude", "-p").Return([]byte(stream), fmt.Errorf("exit status 1"))
+
+	backend := NewClaudeCLI(h.Commander, h.Env, "")
+	resp, err := backend.Complete(Request{Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error_max_turns")
+
+	require.NotNil(t, resp)
+	assert.Equal(t, 0.5, resp.Usage.CostUSD)
+	assert.Equal(t, 1000, resp.Usage.InputTokens)
+}
+
+func TestClaudeCLI_Failure(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Commander.OnPattern("claude").Return(nil, fmt.Errorf("exit status 1"))
+
+	backend := NewClaudeCLI(h.Commander, h.Env, "")
+	_, err := backend.Complete(Request{Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "claude command failed")
+}
+
+func TestAnthropic_Complete_WritesOutputFile(t *testing.T) {
+	var got anthropicRequest
+	var gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		gotKey = r.Header.Get("x-api-key")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"`+"```markdown\\n# Pkg\\n\\nUpdated.\\n```"+`"}],"stop_reason":"end_turn","usage":{"input_tokens":2000,"output_tokens":400}}`)
+	}))
+	defer server.Close()
+
+	h := testutil.NewTestHarness()
+	h.Env.Set("ANTHROPIC_API_KEY", "sk-test")
+	h.WriteFile("/project/pkg/index.md", "# Pkg\n\nOld.\n")
+
+	backend := NewAnthropic(h.FS, h.Env, Settings{BaseURL: server.URL, MaxTokens: 100})
+	resp, err := backend.Complete(Request{Prompt: "Update the index", Model: "haiku", OutputPath: "/project/pkg/index.md"})
+	require.NoError(t, err)
+
+	// Priced at $1/MTok input and $5/MTok output
+	assert.Equal(t, 2000, resp.Usage.InputTokens)
+	assert.Equal(t, 400, resp.Usage.OutputTokens)
+	assert.InDelta(t, 0.004, resp.Usage.CostUSD, 1e-9)
+
+	assert.Equal(t, "sk-test", gotKey)
+	assert.Equal(t, "claude-haiku-4-5", got.Model)
+	assert.Equal(t, 100, got.MaxTokens)
+	require.Len(t, got.Messages, 1)
+	assert.Contains(t, got.Messages[0].Content, "Update the index")
+	assert.Contains(t, got.Messages[0].Content, "Old.")
+
+	content, err := afero.ReadFile(h.FS, "/project/pkg/index.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Pkg\n\nUpdated.\n", string(content))
+}
+
+func TestAnthropic_Complete_MissingKey(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	backend := NewAnthropic(h.FS, h.Env, Settings{})
+	_, err := backend.Complete(Request{Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ANTHROPIC_API_KEY")
+}
+
+func TestAnthropic_Complete_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"rate limited"}}`)
+	}))
+	defer server.Close()
+
+	h := testutil.NewTestHarness()
+	h.Env.Set("ANTHROPIC_API_KEY", "sk-test")
+
+	backend := NewAnthropic(h.FS, h.Env, Settings{BaseURL: server.URL})
+	_, err := backend.Complete(Request{Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Contains(t, err.Error(), "rate limited")
+}
+
+func TestOpenAI_Complete(t *testing.T) {
+	var got openAIRequest
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		gotAuth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"user-dashboard"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	h := testutil.NewTestHarness()
+
+	backend := NewOpenAI(h.FS, h.Env, Settings{BaseURL: server.URL + "/v1", Model: "llama3"})
+	resp, err := backend.Complete(Request{Prompt: "name this", Model: "haiku"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user-dashboard", resp.Text)
+	assert.Equal(t, "llama3", got.Model)
+	assert.Equal(t, "name this", got.Messages[0].Content)
+	assert.Empty(t, gotAuth, "no key configured, no Authorization header")
+}
+
+func TestOpenAI_Complete_RequiresModel(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	backend := NewOpenAI(h.FS, h.Env, Settings{})
+	_, err := backend.Complete(Request{Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a model")
+}
+
+func TestOpenAI_Complete_TruncatedFileIsNotWritten(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"# Partial"},"finish_reason":"length"}]}`)
+	}))
+	defer server.Close()
+
+	h := testutil.NewTestHarness()
+	h.WriteFile("/project/index.md", "# Original\n")
+
+	backend := NewOpenAI(h.FS, h.Env, Settings{BaseURL: server.URL, Model: "llama3", MaxTokens: 10})
+	_, err := backend.Complete(Request{Prompt: "x", OutputPath: "/project/index.md"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "truncated")
+	testutil.AssertFileContains(t, h.FS, "/project/index.md", "# Original")
+}
+
+func TestEstimateCost(t *testing.T) {
+	usage := Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheCreationTokens: 200_000, CacheReadTokens: 2_000_000}
+
+	// Dated IDs match their family: $3 in, $15 out, $3.75 cache write, $0.30 cache read
+	assert.InDelta(t, 3+1.5+0.75+0.6, EstimateCost("claude-sonnet-4-5-20250929", usage), 1e-9)
+
+	// Aliases resolve through the default model IDs
+	assert.InDelta(t, EstimateCost("claude-haiku-4-5", usage), EstimateCost("haiku", usage), 1e-9)
+
+	// Opus 4.5 is priced apart from earlier Opus 4 models
+	assert.Less(t, EstimateCost("claude-opus-4-5-20251101", usage), EstimateCost("claude-opus-4-1-20250805", usage))
+
+	assert.Zero(t, EstimateCost("llama3", usage))
+}
+
+func TestFormatTokensAndCost(t *testing.T) {
+	assert.Equal(t, "950", FormatTokens(950))
+	assert.Equal(t, "12.3k", FormatTokens(12_345))
+	assert.Equal(t, "1.2M", FormatTokens(1_234_567))
+
+	assert.Equal(t, "$0.00", FormatCost(0))
+	assert.Equal(t, "$0.0042", FormatCost(0.0042))
+	assert.Equal(t, "$1.50", FormatCost(1.5))
+}
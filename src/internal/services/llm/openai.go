@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// defaultOpenAIBaseURL points at a local OpenAI-compatible server (Ollama's default)
+	defaultOpenAIBaseURL = "http://localhost:11434/v1"
+
+	// defaultOpenAIKeyEnv holds the API key, if the endpoint needs one
+	defaultOpenAIKeyEnv = "OPENAI_API_KEY"
+)
+
+// OpenAI runs completions against an OpenAI-compatible chat completions endpoint
+type OpenAI struct {
+	fs       afero.Fs
+	env      env.Environment
+	settings Settings
+	client   *http.Client
+}
+
+// NewOpenAI creates an OpenAI-compatible backend
+func NewOpenAI(fs afero.Fs, environment env.Environment, settings Settings) *OpenAI {
+	if settings.BaseURL == "" {
+		settings.BaseURL = defaultOpenAIBaseURL
+	}
+	if settings.APIKeyEnv == "" {
+		settings.APIKeyEnv = defaultOpenAIKeyEnv
+	}
+	return &OpenAI{
+		fs:       fs,
+		env:      environment,
+		settings: settings,
+		client:   &http.Client{Timeout: settings.Timeout},
+	}
+}
+
+// Name returns the backend name
+func (o *OpenAI) Name() string {
+	return BackendOpenAI
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model     string          `json:"model"`
+	MaxTokens int             `json:"max_tokens,omitempty"`
+	Messages  []openAIMessage `json:"messages"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Model string `json:"model"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends the prompt as a single user message
+func (o *OpenAI) Complete(req Request) (*Response, error) {
+	model := req.Model
+	if o.settings.Model != "" {
+		model = o.settings.Model
+	}
+	if model == "" {
+		return nil, fmt.Errorf("openai backend requires a model; set [llm] model in config.toml")
+	}
+
+	body := openAIRequest{
+		Model:     model,
+		MaxTokens: o.settings.MaxTokens,
+		Messages:  []openAIMessage{{Role: "user", Content: filePrompt(o.fs, req)}},
+	}
+
+	// Local servers usually need no key; send one only if configured
+	headers := map[string]string{}
+	if apiKey := o.env.Get(o.settings.APIKeyEnv); apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+
+	var resp openAIResponse
+	url := strings.TrimRight(o.settings.BaseURL, "/") + "/chat/completions"
+	started := time.Now()
+	if err := postJSON(o.client, url, headers, body, &resp, req.Stderr); err != nil {
+		return nil, err
+	}
+
+	// Cost is unknown for arbitrary endpoints; only tokens are reported
+	result := &Response{Model: model, Usage: Usage{
+		InputTokens:  resp.Usage.PromptTokens,
+		OutputTokens: resp.Usage.CompletionTokens,
+		DurationMS:   time.Since(started).Milliseconds(),
+	}}
+	if resp.Model != "" {
+		result.Model = resp.Model
+	}
+
+	if len(resp.Choices) == 0 {
+		return result, fmt.Errorf("%s returned no choices", url)
+	}
+	choice := resp.Choices[0]
+	result.Text = choice.Message.Content
+	if choice.FinishReason == "length" && req.OutputPath != "" {
+		return result, fmt.Errorf("reply for %s was truncated at %d tokens", req.OutputPath, o.settings.MaxTokens)
+	}
+
+	if err := writeOutput(o.fs, req, result.Text); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// filePrompt adapts a prompt written for the agentic CLI to a text-only
+// backend: the target file's current content is inlined and the model is
+// asked to reply with the complete new content instead of using tools.
+func filePrompt(fs afero.Fs, req Request) string {
+	if req.OutputPath == "" {
+		return req.Prompt
+	}
+
+	var b strings.Builder
+	b.WriteString(req.Prompt)
+	b.WriteString("\n\n---\n\n")
+
+	current, err := afero.ReadFile(fs, req.OutputPath)
+	if err == nil && len(current) > 0 {
+		fmt.Fprintf(&b, "CURRENT CONTENT OF %s:\n\n%s\n\n---\n\n", req.OutputPath, current)
+	} else {
+		fmt.Fprintf(&b, "%s does not exist yet.\n\n---\n\n", req.OutputPath)
+	}
+
+	fmt.Fprintf(&b, "Tools are not available. Ignore any instruction above to read, edit or write files yourself. "+
+		"Reply with ONLY the complete contents for %s, with no commentary and no code fences. "+
+		"Your reply will be written to the file as-is.", req.OutputPath)
+	return b.String()
+}
+
+// writeOutput writes a text-only backend's reply to the request's OutputPath
+func writeOutput(fs afero.Fs, req Request, text string) error {
+	if req.OutputPath == "" {
+		return nil
+	}
+
+	content := strings.TrimSpace(stripCodeFence(text))
+	if content == "" {
+		return fmt.Errorf("model returned empty content for %s", req.OutputPath)
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(req.OutputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", req.OutputPath, err)
+	}
+
+	// Write to temp file first, then rename atomically
+	tempPath := req.OutputPath + ".tmp"
+	if err := afero.WriteFile(fs, tempPath, []byte(content+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", req.OutputPath, err)
+	}
+	if err := fs.Rename(tempPath, req.OutputPath); err != nil {
+		fs.Remove(tempPath)
+		return fmt.Errorf("failed to replace %s: %w", req.OutputPath, err)
+	}
+	return nil
+}
+
+// stripCodeFence removes a single fenced block wrapping the whole reply,
+// which models add despite being asked not to
+func stripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+		return text
+	}
+
+	firstNewline := strings.IndexByte(trimmed, '\n')
+	if firstNewline < 0 {
+		return text
+	}
+	body := trimmed[firstNewline+1 : len(trimmed)-3]
+	return strings.TrimRight(body, "\n")
+}
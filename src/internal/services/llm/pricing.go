@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// price is a model's list price in USD per million tokens
+type price struct {
+	input, output float64
+}
+
+// prices maps model ID prefixes to list prices. Dated IDs such as
+// claude-sonnet-4-5-20250929 match their family prefix; the longest
+// matching prefix wins.
+var prices = map[string]price{
+	"claude-opus-4-5":   {input: 5, output: 25},
+	"claude-opus-4":     {input: 15, output: 75},
+	"claude-sonnet-4":   {input: 3, output: 15},
+	"claude-3-7-sonnet": {input: 3, output: 15},
+	"claude-haiku-4-5":  {input: 1, output: 5},
+	"claude-3-5-haiku":  {input: 0.8, output: 4},
+}
+
+const (
+	// cacheWriteMultiplier prices 5-minute cache writes relative to input
+	cacheWriteMultiplier = 1.25
+
+	// cacheReadMultiplier prices cache hits relative to input
+	cacheReadMultiplier = 0.1
+)
+
+// EstimateCost prices usage at the model's list price. Unknown models
+// (including non-Anthropic ones) cost 0.
+func EstimateCost(model string, usage Usage) float64 {
+	if id, ok := anthropicModels[model]; ok {
+		model = id
+	}
+
+	var best string
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0
+	}
+
+	p := prices[best]
+	return (float64(usage.InputTokens)*p.input +
+		float64(usage.OutputTokens)*p.output +
+		float64(usage.CacheCreationTokens)*p.input*cacheWriteMultiplier +
+		float64(usage.CacheReadTokens)*p.input*cacheReadMultiplier) / 1e6
+}
+
+// FormatTokens abbreviates token counts for reports (e.g. 12.3k, 1.2M)
+func FormatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// FormatCost formats a USD amount, keeping precision for sub-cent spend
+func FormatCost(usd float64) string {
+	if usd > 0 && usd < 0.01 {
+		return fmt.Sprintf("$%.4f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// streamEvent is one line of `claude -p --output-format stream-json` output.
+// Only the fields Claudex reads are decoded.
+type streamEvent struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+
+	// system/init
+	Model string `json:"model"`
+
+	// result
+	IsError      bool        `json:"is_error"`
+	Result       string      `json:"result"`
+	TotalCostUSD float64     `json:"total_cost_usd"`
+	DurationMS   int64       `json:"duration_ms"`
+	Usage        streamUsage `json:"usage"`
+	Errors       []string    `json:"errors"`
+}
+
+type streamUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// streamResult is the outcome of a stream-json run
+type streamResult struct {
+	response *Response
+	err      error
+}
+
+// parseStream extracts the final result, error and usage from stream-json
+// output. ok is false when the output holds no result event (e.g. an older
+// CLI that ignored --output-format), in which case callers use it as plain text.
+func parseStream(out []byte) (result streamResult, ok bool) {
+	resp := &Response{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "system":
+			if event.Subtype == "init" && event.Model != "" {
+				resp.Model = event.Model
+			}
+		case "result":
+			ok = true
+			resp.Text = event.Result
+			resp.Usage = Usage{
+				InputTokens:         event.Usage.InputTokens,
+				OutputTokens:        event.Usage.OutputTokens,
+				CacheCreationTokens: event.Usage.CacheCreationInputTokens,
+				CacheReadTokens:     event.Usage.CacheReadInputTokens,
+				CostUSD:             event.TotalCostUSD,
+				DurationMS:          event.DurationMS,
+			}
+			if event.IsError || strings.HasPrefix(event.Subtype, "error") {
+				result.err = resultError(event)
+			}
+		}
+	}
+
+	result.response = resp
+	return result, ok
+}
+
+// resultError describes a failed result event
+func resultError(event streamEvent) error {
+	detail := strings.TrimSpace(event.Result)
+	if detail == "" && len(event.Errors) > 0 {
+		detail = strings.Join(event.Errors, "; ")
+	}
+	if detail == "" {
+		return fmt.Errorf("claude reported %s", event.Subtype)
+	}
+	return fmt.Errorf("claude reported %s: %s", event.Subtype, detail)
+}
@@ -0,0 +1,120 @@
+// Package models resolves which model each headless task and generated agent
+// uses. Values come from the [models] section of config.toml, with
+// CLAUDEX_MODEL_* environment variables taking precedence.
+package models
+
+import (
+	"claudex/internal/services/config"
+	"claudex/internal/services/env"
+)
+
+// Task identifies a headless model call
+type Task string
+
+const (
+	// TaskSessionName generates session names from descriptions
+	TaskSessionName Task = "session_name"
+	// TaskOverview updates session-overview.md from the transcript
+	TaskOverview Task = "overview"
+	// TaskIndexUpdate regenerates index.md files after commits
+	TaskIndexUpdate Task = "index_update"
+	// TaskIndexCreate creates missing index.md files
+	TaskIndexCreate Task = "index_create"
+)
+
+// Tasks lists every task in config order
+var Tasks = []Task{TaskSessionName, TaskOverview, TaskIndexUpdate, TaskIndexCreate}
+
+// defaults holds the built-in model per task. An empty value leaves the
+// choice to the backend (the CLI's configured default for claude-cli).
+var defaults = map[Task]string{
+	TaskSessionName: "",
+	TaskOverview:    "haiku",
+	TaskIndexUpdate: "haiku",
+	TaskIndexCreate: "haiku",
+}
+
+// EnvVar returns the override variable for a task (e.g. CLAUDEX_MODEL_INDEX_UPDATE)
+func EnvVar(task Task) string {
+	return config.ModelEnvVar(string(task))
+}
+
+// AgentEnvVar returns the override variable for a generated agent
+// (e.g. CLAUDEX_MODEL_AGENT_PRINCIPAL_ENGINEER_GO)
+func AgentEnvVar(name string) string {
+	return config.AgentEnvVar(name)
+}
+
+// Default returns the built-in model for a task
+func Default(task Task) string {
+	return defaults[task]
+}
+
+// Resolver picks models with precedence env > config > default
+type Resolver struct {
+	cfg config.Models
+	env env.Environment
+}
+
+// New creates a Resolver over the loaded [models] config
+func New(cfg config.Models, environment env.Environment) *Resolver {
+	return &Resolver{cfg: cfg, env: environment}
+}
+
+// FromEnv creates a Resolver for processes that don't load config.toml
+// (hooks, job workers); they see the config through the exported variables.
+func FromEnv(environment env.Environment) *Resolver {
+	return New(config.Models{}, environment)
+}
+
+// Model returns the model for a task
+func (r *Resolver) Model(task Task) string {
+	if model := r.env.Get(EnvVar(task)); model != "" {
+		return model
+	}
+	if model := r.configured(task); model != "" {
+		return model
+	}
+	return Default(task)
+}
+
+// Agent returns the model for a generated agent, or fallback (the model in
+// the agent's own frontmatter) when none is configured
+func (r *Resolver) Agent(name, fallback string) string {
+	if model := r.env.Get(AgentEnvVar(name)); model != "" {
+		return model
+	}
+	if model := r.cfg.Agents[name]; model != "" {
+		return model
+	}
+	return fallback
+}
+
+// Export sets the override variable for every configured model that isn't
+// already set, so hooks and background workers resolve the same models
+func (r *Resolver) Export() {
+	for _, task := range Tasks {
+		if model := r.configured(task); model != "" && r.env.Get(EnvVar(task)) == "" {
+			r.env.Set(EnvVar(task), model)
+		}
+	}
+	for name, model := range r.cfg.Agents {
+		if model != "" && r.env.Get(AgentEnvVar(name)) == "" {
+			r.env.Set(AgentEnvVar(name), model)
+		}
+	}
+}
+
+func (r *Resolver) configured(task Task) string {
+	switch task {
+	case TaskSessionName:
+		return r.cfg.SessionName
+	case TaskOverview:
+		return r.cfg.Overview
+	case TaskIndexUpdate:
+		return r.cfg.IndexUpdate
+	case TaskIndexCreate:
+		return r.cfg.IndexCreate
+	}
+	return ""
+}
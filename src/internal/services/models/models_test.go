@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"claudex/internal/services/config"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModel_Defaults(t *testing.T) {
+	h := testutil.NewTestHarness()
+	r := FromEnv(h.Env)
+
+	assert.Equal(t, "", r.Model(TaskSessionName), "naming defaults to the backend's model")
+	assert.Equal(t, "haiku", r.Model(TaskOverview))
+	assert.Equal(t, "haiku", r.Model(TaskIndexUpdate))
+	assert.Equal(t, "haiku", r.Model(TaskIndexCreate))
+}
+
+func TestModel_ConfigThenEnvPrecedence(t *testing.T) {
+	h := testutil.NewTestHarness()
+	r := New(config.Models{Overview: "sonnet", IndexUpdate: "sonnet"}, h.Env)
+
+	assert.Equal(t, "sonnet", r.Model(TaskOverview))
+
+	h.Env.Set("CLAUDEX_MODEL_INDEX_UPDATE", "opus")
+	assert.Equal(t, "opus", r.Model(TaskIndexUpdate))
+}
+
+func TestAgent_FallbackConfigEnv(t *testing.T) {
+	h := testutil.NewTestHarness()
+	r := New(config.Models{Agents: map[string]string{"architect": "sonnet"}}, h.Env)
+
+	assert.Equal(t, "sonnet", r.Agent("researcher", "sonnet"))
+	assert.Equal(t, "sonnet", r.Agent("architect", "opus"))
+
+	h.Env.Set("CLAUDEX_MODEL_AGENT_PRINCIPAL_ENGINEER_GO", "opus")
+	assert.Equal(t, "opus", r.Agent("principal-engineer-go", "sonnet"))
+}
+
+func TestExport_KeepsExistingEnv(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("CLAUDEX_MODEL_OVERVIEW", "opus")
+
+	New(config.Models{
+		Overview:    "sonnet",
+		IndexCreate: "sonnet",
+		Agents:      map[string]string{"team-lead": "opus"},
+	}, h.Env).Export()
+
+	assert.Equal(t, "opus", h.Env.Get("CLAUDEX_MODEL_OVERVIEW"))
+	assert.Equal(t, "sonnet", h.Env.Get("CLAUDEX_MODEL_INDEX_CREATE"))
+	assert.Equal(t, "opus", h.Env.Get("CLAUDEX_MODEL_AGENT_TEAM_LEAD"))
+	assert.Empty(t, h.Env.Get("CLAUDEX_MODEL_INDEX_UPDATE"))
+
+	// A hook process sees the exported values
+	assert.Equal(t, "sonnet", FromEnv(h.Env).Model(TaskIndexCreate))
+}
@@ -7,6 +7,9 @@ const (
 	// SessionsDir is the directory for session data
 	SessionsDir = ".claudex/sessions"
 
+	// ArchiveDir holds archived sessions, restorable with session unarchive
+	ArchiveDir = ".claudex/archive"
+
 	// LogsDir is the directory for log files
 	LogsDir = ".claudex/logs"
 
@@ -16,6 +19,21 @@ const (
 	// PreferencesFile is the user preferences file path
 	PreferencesFile = ".claudex/preferences.json"
 
+	// PromptsDir holds the documentation prompt templates the hooks load
+	PromptsDir = ".claude/hooks/prompts"
+
+	// TemplatesDir holds the project's activation prompt templates
+	TemplatesDir = ".claudex/templates"
+
+	// JobsDir is the durable background job queue directory
+	JobsDir = ".claudex/jobs"
+
+	// UsageFile is the headless model call usage ledger
+	UsageFile = ".claudex/usage.jsonl"
+
+	// GCStampFile records when the startup retention GC last ran
+	GCStampFile = ".claudex/last-gc"
+
 	// Legacy paths (for migration detection)
 	LegacySessionsDir = "sessions"
 	LegacyLogsDir     = "logs"
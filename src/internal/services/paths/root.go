@@ -0,0 +1,50 @@
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// FindProjectRoot returns the project directory that owns start: the nearest
+// directory at or above start holding a .claudex/ folder, else the git
+// toplevel, else start itself. Every path in this package is relative to that
+// root, so a repository keeps one session store whichever subdirectory
+// claudex or a hook runs from.
+//
+// A .claudex/ folder in the home directory is ignored unless start is the
+// home directory, so a stray ~/.claudex doesn't capture every project
+// outside a git repository.
+func FindProjectRoot(fs afero.Fs, start string) string {
+	start = filepath.Clean(start)
+	home, _ := os.UserHomeDir()
+
+	for dir := start; ; {
+		if dir != home || dir == start {
+			if exists, _ := afero.DirExists(fs, filepath.Join(dir, ClaudexDir)); exists {
+				return dir
+			}
+		}
+		// .git is a file in worktrees and submodules
+		if _, err := fs.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return start
+		}
+		dir = parent
+	}
+}
+
+// ProjectRoot resolves the project root from the working directory
+func ProjectRoot(fs afero.Fs) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return FindProjectRoot(fs, cwd), nil
+}
@@ -0,0 +1,60 @@
+package paths
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectRoot_ExistingClaudexDirAbove(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/repo/.claudex/sessions", 0755))
+	require.NoError(t, fs.MkdirAll("/repo/src/internal", 0755))
+
+	assert.Equal(t, "/repo", FindProjectRoot(fs, "/repo/src/internal"))
+	assert.Equal(t, "/repo", FindProjectRoot(fs, "/repo"))
+}
+
+func TestFindProjectRoot_GitToplevel(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/repo/.git", 0755))
+	require.NoError(t, fs.MkdirAll("/repo/cmd/tool", 0755))
+
+	assert.Equal(t, "/repo", FindProjectRoot(fs, "/repo/cmd/tool"))
+}
+
+func TestFindProjectRoot_GitWorktreeFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/worktree/.git", []byte("gitdir: /repo/.git/worktrees/wt\n"), 0644))
+	require.NoError(t, fs.MkdirAll("/worktree/pkg", 0755))
+
+	assert.Equal(t, "/worktree", FindProjectRoot(fs, "/worktree/pkg"))
+}
+
+func TestFindProjectRoot_NearestClaudexDirWins(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/monorepo/.git", 0755))
+	require.NoError(t, fs.MkdirAll("/monorepo/services/api/.claudex", 0755))
+	require.NoError(t, fs.MkdirAll("/monorepo/services/api/handlers", 0755))
+
+	assert.Equal(t, "/monorepo/services/api", FindProjectRoot(fs, "/monorepo/services/api/handlers"))
+	assert.Equal(t, "/monorepo", FindProjectRoot(fs, "/monorepo/services"))
+}
+
+func TestFindProjectRoot_StopsAtGitToplevel(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/work/.claudex", 0755))
+	require.NoError(t, fs.MkdirAll("/work/repo/.git", 0755))
+	require.NoError(t, fs.MkdirAll("/work/repo/src", 0755))
+
+	assert.Equal(t, "/work/repo", FindProjectRoot(fs, "/work/repo/src"))
+}
+
+func TestFindProjectRoot_FallsBackToStart(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/tmp/scratch/notes", 0755))
+
+	assert.Equal(t, "/tmp/scratch/notes", FindProjectRoot(fs, "/tmp/scratch/notes/"))
+}
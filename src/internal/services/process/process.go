@@ -0,0 +1,23 @@
+// Package process provides helpers for managing OS processes that outlive
+// the claudex binaries, such as detached background workers.
+package process
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// StartDetached starts cmd in its own session/process group so that it keeps
+// running after the calling process exits. Stdio is left unattached unless the
+// caller configured it. The child is released and never waited on.
+func StartDetached(cmd *exec.Cmd) (int, error) {
+	cmd.SysProcAttr = detachedAttr()
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start detached process: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
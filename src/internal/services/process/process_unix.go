@@ -0,0 +1,49 @@
+//go:build !windows
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// detachedAttr starts the child in a new session so it survives the parent's
+// terminal and process group going away.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// Alive reports whether a process with the given PID is still running.
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// Signal 0 performs error checking only; EPERM means the process exists
+	// but belongs to another user.
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Kill terminates the process and, when it leads a process group (as detached
+// workers do), every process in that group.
+func Kill(pid int) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+
+	if err := syscall.Kill(-pid, syscall.SIGKILL); err == nil {
+		return nil
+	}
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to kill process %d: %w", pid, err)
+	}
+	return nil
+}
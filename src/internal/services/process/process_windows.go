@@ -0,0 +1,61 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	createNewProcessGroup          = 0x00000200
+	detachedProcess                = 0x00000008
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// detachedAttr detaches the child from the parent's console so it survives
+// the parent exiting.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: createNewProcessGroup | detachedProcess,
+		HideWindow:    true,
+	}
+}
+
+// Alive reports whether a process with the given PID is still running.
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// Kill terminates the process and its child process tree.
+func Kill(pid int) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+
+	// taskkill walks the tree so the Claude CLI started by a worker goes too
+	cmd := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if out, err := cmd.CombinedOutput(); err != nil && Alive(pid) {
+		return fmt.Errorf("failed to kill process %d: %w (%s)", pid, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -13,6 +13,9 @@ import (
 	"strings"
 )
 
+// DefaultAgent is the entry agent of sessions that don't choose one
+const DefaultAgent = "team-lead"
+
 // GetProfiles returns a sorted list of all available profile names from both
 // embedded FS and filesystem .claude/agents/ directory.
 func GetProfiles(profilesFS fs.FS) ([]string, error) {
@@ -67,7 +70,7 @@ func ExtractDescription(profilesFS fs.FS, profilePath string) string {
 		line := scanner.Text()
 		if re.MatchString(line) {
 			desc := strings.TrimLeft(line, "#*- ")
-			desc = regexp.MustCompile(`(?i)role:`).ReplaceAllString(desc, "")
+			desc = regexp.MustCompile(`(?i)^description:|role:`).ReplaceAllString(desc, "")
 			desc = strings.TrimSpace(desc)
 			if len(desc) > 60 {
 				desc = desc[:60]
@@ -79,6 +82,26 @@ func ExtractDescription(profilesFS fs.FS, profilePath string) string {
 	return ""
 }
 
+// Exists reports whether name is an available profile (see GetProfiles)
+func Exists(profilesFS fs.FS, name string) bool {
+	profiles, _ := GetProfiles(profilesFS)
+	for _, p := range profiles {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe returns the description of a profile from the embedded FS or, for
+// custom agents, from .claude/agents/
+func Describe(profilesFS fs.FS, name string) string {
+	if path := ResolvePath(profilesFS, name); path != "" {
+		return ExtractDescription(profilesFS, path)
+	}
+	return ExtractDescription(os.DirFS(filepath.Join(".claude", "agents")), name+".md")
+}
+
 // Load loads a profile from the embedded FS profiles/agents/ directory.
 func Load(profilesFS fs.FS, profileName string) ([]byte, error) {
 	// Look for profile in profiles/agents/ directory
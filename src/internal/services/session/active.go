@@ -0,0 +1,45 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/afero"
+)
+
+// ActiveFile marks a session that Claude is running in. It holds the PID of
+// the claudex process that launched Claude; the .lock suffix keeps it out of
+// exported bundles.
+const ActiveFile = ".claude-running.lock"
+
+// MarkActive records that the claudex process pid is running Claude in a
+// session. Call ClearActive once Claude exits.
+func MarkActive(fs afero.Fs, sessionPath string, pid int) error {
+	path := filepath.Join(sessionPath, ActiveFile)
+	if err := afero.WriteFile(fs, path, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to mark session active: %w", err)
+	}
+	return nil
+}
+
+// ClearActive removes the marker written by MarkActive
+func ClearActive(fs afero.Fs, sessionPath string) error {
+	err := fs.Remove(filepath.Join(sessionPath, ActiveFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear session active marker: %w", err)
+	}
+	return nil
+}
+
+// ActivePID returns the PID recorded by MarkActive, or 0 when the session
+// is not marked active. The process may have died without clearing the
+// marker, so callers check that it is still alive.
+func ActivePID(fs afero.Fs, sessionPath string) int {
+	pid, err := readIntFile(fs, filepath.Join(sessionPath, ActiveFile))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/filesystem"
+
+	"github.com/spf13/afero"
+)
+
+// Archive moves a session folder from sessionsDir to archiveDir and records
+// when it was archived. The folder keeps its name, so the Claude session ID
+// and transcript link survive.
+func Archive(fs afero.Fs, clk clock.Clock, sessionsDir, archiveDir, name string) error {
+	src := filepath.Join(sessionsDir, name)
+	err := UpdateMetadata(fs, src, func(m *SessionMetadata) {
+		m.Archived = clk.Now().UTC().Format(time.RFC3339)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record archive time: %w", err)
+	}
+
+	if err := moveSession(fs, src, filepath.Join(archiveDir, name)); err != nil {
+		UpdateMetadata(fs, src, func(m *SessionMetadata) { m.Archived = "" }) // Best effort: it stays active
+		return err
+	}
+	return nil
+}
+
+// Unarchive moves an archived session back to sessionsDir
+func Unarchive(fs afero.Fs, sessionsDir, archiveDir, name string) error {
+	dst := filepath.Join(sessionsDir, name)
+	if err := moveSession(fs, filepath.Join(archiveDir, name), dst); err != nil {
+		return err
+	}
+	return UpdateMetadata(fs, dst, func(m *SessionMetadata) {
+		m.Archived = ""
+	})
+}
+
+// moveSession renames a session folder, copying it when a rename isn't
+// possible. An existing destination is never overwritten.
+func moveSession(fs afero.Fs, src, dst string) error {
+	if _, err := fs.Stat(dst); err == nil {
+		return fmt.Errorf("session %s already exists in %s", filepath.Base(dst), filepath.Dir(dst))
+	}
+	if err := fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	if err := fs.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// Rename fails across filesystems; fall back to copy + delete
+	if err := filesystem.CopyDir(fs, src, dst, false); err != nil {
+		fs.RemoveAll(dst)
+		return fmt.Errorf("failed to move session %s: %w", filepath.Base(src), err)
+	}
+	if err := fs.RemoveAll(src); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it: %w", src, err)
+	}
+	return nil
+}
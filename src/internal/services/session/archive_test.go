@@ -0,0 +1,69 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ArchiveAndUnarchive tests a session round trip through the archive
+func Test_ArchiveAndUnarchive(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	sessionsDir := "/.claudex/sessions"
+	archiveDir := "/.claudex/archive"
+	name := "auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, name), map[string]string{
+		DescriptionFile:       "Add auth",
+		"session-overview.md": "# Overview",
+	})
+
+	require.NoError(t, Archive(h.FS, h, sessionsDir, archiveDir, name))
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(sessionsDir, name))
+	testutil.AssertFileContains(t, h.FS, filepath.Join(archiveDir, name, "session-overview.md"), "# Overview")
+
+	metadata, err := ReadMetadata(h.FS, filepath.Join(archiveDir, name))
+	require.NoError(t, err)
+	require.Equal(t, "Add auth", metadata.Description)
+	require.Equal(t, "2024-01-15T10:30:00Z", metadata.Archived)
+
+	require.NoError(t, Unarchive(h.FS, sessionsDir, archiveDir, name))
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(archiveDir, name))
+	metadata, err = ReadMetadata(h.FS, filepath.Join(sessionsDir, name))
+	require.NoError(t, err)
+	require.Empty(t, metadata.Archived)
+}
+
+// Test_Archive_NeverOverwrites tests a name taken in the destination fails
+// without touching either folder
+func Test_Archive_NeverOverwrites(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionsDir := "/.claudex/sessions"
+	archiveDir := "/.claudex/archive"
+	name := "auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, name), map[string]string{DescriptionFile: "Active"})
+	h.CreateSessionWithFiles(filepath.Join(archiveDir, name), map[string]string{DescriptionFile: "Archived"})
+
+	err := Archive(h.FS, h, sessionsDir, archiveDir, name)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+
+	err = Unarchive(h.FS, sessionsDir, archiveDir, name)
+	require.Error(t, err)
+
+	desc, err := ReadDescription(h.FS, filepath.Join(sessionsDir, name))
+	require.NoError(t, err)
+	require.Equal(t, "Active", desc)
+	metadata, err := ReadMetadata(h.FS, filepath.Join(sessionsDir, name))
+	require.NoError(t, err)
+	require.Empty(t, metadata.Archived)
+	desc, err = ReadDescription(h.FS, filepath.Join(archiveDir, name))
+	require.NoError(t, err)
+	require.Equal(t, "Archived", desc)
+}
@@ -3,7 +3,6 @@ package session
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -11,41 +10,44 @@ import (
 )
 
 const (
-	// DocUpdateCounterFile is the filename for the auto-doc update counter
+	// DocUpdateCounterFile is the legacy filename for the auto-doc update counter
 	DocUpdateCounterFile = ".doc-update-counter"
 
-	// LastProcessedLineFile is the filename for the last processed line tracker
+	// LastProcessedLineFile is the legacy filename for the last processed line tracker
 	LastProcessedLineFile = ".last-processed-line-overview"
 )
 
-// ReadCounter reads an integer counter from a file in the session folder.
-// Returns 0 if the file does not exist or is empty.
-// Returns an error only if the file exists but contains invalid data.
+// ReadCounter reads the auto-doc update counter of a session.
+// Returns 0 if it was never written.
+// Returns an error only if the stored data is invalid.
 func ReadCounter(fs afero.Fs, sessionPath string) (int, error) {
-	path := filepath.Join(sessionPath, DocUpdateCounterFile)
-	return readIntFile(fs, path)
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return 0, err
+	}
+	return metadata.Counters.DocUpdates, nil
 }
 
-// WriteCounter writes an integer counter to a file in the session folder.
+// WriteCounter writes the auto-doc update counter of a session.
 func WriteCounter(fs afero.Fs, sessionPath string, value int) error {
-	path := filepath.Join(sessionPath, DocUpdateCounterFile)
-	return writeIntFile(fs, path, value)
+	return UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.Counters.DocUpdates = value
+	})
 }
 
-// IncrementCounter atomically reads, increments, and writes the counter.
+// IncrementCounter reads, increments, and writes the counter in one locked
+// session.json update.
 // Returns the new counter value.
 func IncrementCounter(fs afero.Fs, sessionPath string) (int, error) {
-	current, err := ReadCounter(fs, sessionPath)
+	var value int
+	err := UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.Counters.DocUpdates++
+		value = m.Counters.DocUpdates
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to read counter: %w", err)
-	}
-
-	newValue := current + 1
-	if err := WriteCounter(fs, sessionPath, newValue); err != nil {
-		return 0, fmt.Errorf("failed to write incremented counter: %w", err)
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
 	}
-
-	return newValue, nil
+	return value, nil
 }
 
 // ResetCounter sets the counter to 0.
@@ -54,16 +56,20 @@ func ResetCounter(fs afero.Fs, sessionPath string) error {
 }
 
 // ReadLastProcessedLine reads the last processed line number for transcript tracking.
-// Returns 0 if the file does not exist (meaning no lines have been processed yet).
+// Returns 0 if it was never written (meaning no lines have been processed yet).
 func ReadLastProcessedLine(fs afero.Fs, sessionPath string) (int, error) {
-	path := filepath.Join(sessionPath, LastProcessedLineFile)
-	return readIntFile(fs, path)
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return 0, err
+	}
+	return metadata.Counters.LastProcessedLine, nil
 }
 
 // WriteLastProcessedLine writes the last processed line number.
 func WriteLastProcessedLine(fs afero.Fs, sessionPath string, line int) error {
-	path := filepath.Join(sessionPath, LastProcessedLineFile)
-	return writeIntFile(fs, path, line)
+	return UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.Counters.LastProcessedLine = line
+	})
 }
 
 // readIntFile reads an integer from a file, returning 0 if the file doesn't exist.
@@ -91,12 +97,3 @@ func readIntFile(fs afero.Fs, path string) (int, error) {
 
 	return value, nil
 }
-
-// writeIntFile writes an integer to a file.
-func writeIntFile(fs afero.Fs, path string, value int) error {
-	content := fmt.Sprintf("%d", value)
-	if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", path, err)
-	}
-	return nil
-}
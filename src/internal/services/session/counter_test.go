@@ -104,8 +104,8 @@ func Test_WriteCounter(t *testing.T) {
 
 	// Verify
 	require.NoError(t, err)
-	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, ".doc-update-counter"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, ".doc-update-counter"), "42")
+	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, MetadataFile))
+	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, MetadataFile), `"docUpdates": 42`)
 }
 
 // Test_WriteCounter_Overwrite tests overwriting an existing counter
@@ -122,7 +122,8 @@ func Test_WriteCounter_Overwrite(t *testing.T) {
 
 	// Verify
 	require.NoError(t, err)
-	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, ".doc-update-counter"), "20")
+	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, MetadataFile), `"docUpdates": 20`)
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(sessionPath, ".doc-update-counter"))
 
 	// Verify old value is gone
 	result, _ := ReadCounter(h.FS, sessionPath)
@@ -165,7 +166,7 @@ func Test_IncrementCounter_FromZero(t *testing.T) {
 	require.Equal(t, 1, newValue)
 
 	// Verify file was created
-	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, ".doc-update-counter"))
+	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, MetadataFile))
 }
 
 // Test_ResetCounter tests resetting counter to zero
@@ -232,8 +233,8 @@ func Test_WriteLastProcessedLine(t *testing.T) {
 
 	// Verify
 	require.NoError(t, err)
-	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, ".last-processed-line-overview"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, ".last-processed-line-overview"), "250")
+	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, MetadataFile))
+	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, MetadataFile), `"lastProcessedLine": 250`)
 }
 
 // Test_WriteLastProcessedLine_Update tests updating last processed line
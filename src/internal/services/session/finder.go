@@ -2,6 +2,7 @@ package session
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -13,40 +14,19 @@ import (
 
 // FindSessionFolder locates the session folder by ID using a priority-based search strategy.
 // Priority 1: CLAUDEX_SESSION_PATH environment variable
-// Priority 2: Pattern match in ./sessions/*-{sessionID}
+// Priority 2: Pattern match in {project root}/.claudex/sessions/*-{sessionID}, where the
+// project root is found from the working directory (see paths.FindProjectRoot)
 // Returns the absolute path to the session folder or an error if not found.
 func FindSessionFolder(fs afero.Fs, environment env.Environment, sessionID string) (string, error) {
-	// Priority 1: Check environment variable
-	if envPath := environment.Get("CLAUDEX_SESSION_PATH"); envPath != "" {
-		// Verify the path exists
-		exists, err := afero.DirExists(fs, envPath)
-		if err != nil {
-			return "", fmt.Errorf("failed to check CLAUDEX_SESSION_PATH: %w", err)
-		}
-		if exists {
-			return envPath, nil
-		}
-		// If env var is set but path doesn't exist, that's an error
-		return "", fmt.Errorf("CLAUDEX_SESSION_PATH is set but directory does not exist: %s", envPath)
-	}
-
-	// Priority 2: Pattern match in ./.claudex/sessions/*-{sessionID}
-	pattern := filepath.Join(".", paths.SessionsDir, fmt.Sprintf("*-%s", sessionID))
-	matches, err := afero.Glob(fs, pattern)
+	cwd, err := os.Getwd()
 	if err != nil {
-		return "", fmt.Errorf("failed to glob session pattern: %w", err)
-	}
-
-	if len(matches) == 0 {
-		return "", fmt.Errorf("session folder not found for session ID: %s", sessionID)
+		return "", fmt.Errorf("failed to get working directory: %w", err)
 	}
-
-	// Return the first match (should be only one in practice)
-	return matches[0], nil
+	return FindSessionFolderWithCwd(fs, environment, sessionID, cwd)
 }
 
-// FindSessionFolderWithCwd is a variant that searches relative to a specific working directory.
-// This is useful when the cwd is not the project root.
+// FindSessionFolderWithCwd is a variant that searches from a specific working directory,
+// such as the cwd Claude reports to hooks. The cwd may be any directory inside the project.
 func FindSessionFolderWithCwd(fs afero.Fs, environment env.Environment, sessionID string, cwd string) (string, error) {
 	// Priority 1: Check environment variable (absolute path)
 	if envPath := environment.Get("CLAUDEX_SESSION_PATH"); envPath != "" {
@@ -57,11 +37,13 @@ func FindSessionFolderWithCwd(fs afero.Fs, environment env.Environment, sessionI
 		if exists {
 			return envPath, nil
 		}
+		// If env var is set but path doesn't exist, that's an error
 		return "", fmt.Errorf("CLAUDEX_SESSION_PATH is set but directory does not exist: %s", envPath)
 	}
 
-	// Priority 2: Pattern match in {cwd}/.claudex/sessions/*-{sessionID}
-	pattern := filepath.Join(cwd, paths.SessionsDir, fmt.Sprintf("*-%s", sessionID))
+	// Priority 2: Pattern match in {root}/.claudex/sessions/*-{sessionID}
+	root := paths.FindProjectRoot(fs, cwd)
+	pattern := filepath.Join(root, paths.SessionsDir, fmt.Sprintf("*-%s", sessionID))
 	matches, err := afero.Glob(fs, pattern)
 	if err != nil {
 		return "", fmt.Errorf("failed to glob session pattern: %w", err)
@@ -71,6 +53,7 @@ func FindSessionFolderWithCwd(fs afero.Fs, environment env.Environment, sessionI
 		return "", fmt.Errorf("session folder not found for session ID: %s", sessionID)
 	}
 
+	// Return the first match (should be only one in practice)
 	return matches[0], nil
 }
 
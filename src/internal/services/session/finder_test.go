@@ -1,6 +1,8 @@
 package session
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"claudex/internal/testutil"
@@ -53,7 +55,7 @@ func Test_FindSessionFolder_PatternMatch(t *testing.T) {
 	h := testutil.NewTestHarness()
 
 	sessionID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
-	sessionPath := "./.claudex/sessions/feature-login-" + sessionID
+	sessionPath := filepath.Join(workingDir(t), ".claudex/sessions/feature-login-"+sessionID)
 	h.CreateDir(sessionPath)
 
 	// Exercise
@@ -72,8 +74,8 @@ func Test_FindSessionFolder_MultipleMatches(t *testing.T) {
 	sessionID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
 
 	// Create multiple sessions with same ID (unlikely but possible)
-	h.CreateDir("./.claudex/sessions/feature-a-" + sessionID)
-	h.CreateDir("./.claudex/sessions/feature-b-" + sessionID)
+	h.CreateDir(filepath.Join(workingDir(t), ".claudex/sessions/feature-a-"+sessionID))
+	h.CreateDir(filepath.Join(workingDir(t), ".claudex/sessions/feature-b-"+sessionID))
 
 	// Exercise
 	result, err := FindSessionFolder(h.FS, h.Env, sessionID)
@@ -116,6 +118,24 @@ func Test_FindSessionFolderWithCwd_CustomCwd(t *testing.T) {
 	require.Equal(t, sessionPath, result)
 }
 
+// Test_FindSessionFolderWithCwd_Subdirectory tests that a cwd inside the project finds
+// the sessions at the project root
+func Test_FindSessionFolderWithCwd_Subdirectory(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionID := "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	sessionPath := "/project/.claudex/sessions/feature-login-" + sessionID
+	h.CreateDir(sessionPath)
+	h.CreateDir("/project/src/handlers")
+
+	// Exercise
+	result, err := FindSessionFolderWithCwd(h.FS, h.Env, sessionID, "/project/src/handlers")
+
+	// Verify
+	require.NoError(t, err)
+	require.Equal(t, sessionPath, result)
+}
+
 // Test_FindSessionFolderWithCwd_EnvVarOverridesCwd tests that env var still has priority with custom cwd
 func Test_FindSessionFolderWithCwd_EnvVarOverridesCwd(t *testing.T) {
 	h := testutil.NewTestHarness()
@@ -174,3 +194,12 @@ func Test_GetSessionID_ValidUUID(t *testing.T) {
 		})
 	}
 }
+
+// workingDir returns the test's working directory, where FindSessionFolder
+// looks when no project marker exists above it
+func workingDir(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	return wd
+}
@@ -1,14 +1,41 @@
 package session
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"claudex/internal/services/lock"
 
 	"github.com/spf13/afero"
 )
 
+const (
+	// MetadataFile is the versioned file holding a session's state
+	MetadataFile = "session.json"
+
+	// MetadataVersion is the session.json format this version reads and writes
+	MetadataVersion = 1
+
+	// metadataLockFile is held while session.json is read, changed and written
+	// back, so concurrent writers (hooks, job workers, the CLI) don't drop each
+	// other's fields
+	metadataLockFile = ".session.json.lock"
+
+	// metadataLockTimeout is how long UpdateMetadata waits for the lock
+	metadataLockTimeout = 5 * time.Second
+
+	// metadataLockStale is the age after which a lock left by a crashed
+	// process is broken
+	metadataLockStale = 30 * time.Second
+)
+
+// Legacy metadata files, one value each. They are read when a session has no
+// session.json yet and removed once it is written.
 const (
 	// DescriptionFile is the filename for session description
 	DescriptionFile = ".description"
@@ -18,64 +45,370 @@ const (
 
 	// LastUsedFile is the filename for last used timestamp
 	LastUsedFile = ".last_used"
+
+	// AgentFile is the filename for the entry agent chosen at creation
+	AgentFile = ".agent"
+
+	// TemplateFile is the filename for the activation template chosen at creation
+	TemplateFile = ".template"
+
+	// ClaudeArgsFile holds the Claude CLI arguments given after -- for the
+	// session, one per line
+	ClaudeArgsFile = ".claude_args"
+
+	// LegacyLastProcessedLineFile was written by early versions and is no
+	// longer read
+	LegacyLastProcessedLineFile = ".last-processed-line"
 )
 
-// SessionMetadata represents metadata files stored in a session folder.
+// legacyFiles lists every file session.json replaces
+var legacyFiles = []string{
+	DescriptionFile,
+	CreatedFile,
+	LastUsedFile,
+	AgentFile,
+	TemplateFile,
+	ClaudeArgsFile,
+	DocUpdateCounterFile,
+	LastProcessedLineFile,
+	LegacyLastProcessedLineFile,
+}
+
+// Operations recorded in a session's lineage
+const (
+	OpFork   = "fork"   // Copied from another session with a new description
+	OpFresh  = "fresh"  // Restarted with a new Claude session, replacing the original
+	OpImport = "import" // Imported from a bundle exported in another project
+)
+
+// LineageEvent records a fork, fresh restart or import that produced a session
+type LineageEvent struct {
+	Op   string `json:"op"`   // OpFork, OpFresh or OpImport
+	From string `json:"from"` // Name of the session it was created from
+	At   string `json:"at"`   // RFC3339 timestamp
+}
+
+// Counters track the autodoc progress of a session
+type Counters struct {
+	DocUpdates        int `json:"docUpdates"`        // Edits since the last overview update
+	LastProcessedLine int `json:"lastProcessedLine"` // Last transcript line folded into the overview
+}
+
+// SessionMetadata is the content of session.json
 type SessionMetadata struct {
-	Description string // Content of .description file
-	Created     string // Content of .created file (RFC3339 timestamp)
-	LastUsed    string // Content of .last_used file (RFC3339 timestamp)
+	Version         int      `json:"version"`
+	ClaudeSessionID string   `json:"claudeSessionId,omitempty"`
+	Description     string   `json:"description"`
+	Created         string   `json:"created,omitempty"`    // RFC3339 timestamp
+	LastUsed        string   `json:"lastUsed,omitempty"`   // RFC3339 timestamp
+	Parent          string   `json:"parent,omitempty"`     // Session this one was forked from, kept across fresh restarts
+	Tags            []string `json:"tags,omitempty"`       // User labels
+	Pinned          bool     `json:"pinned,omitempty"`     // Never archived by the retention GC
+	Agent           string   `json:"agent,omitempty"`      // Entry agent profile (empty for the default agent)
+	Template        string   `json:"template,omitempty"`   // Activation template (empty for the configured template)
+	ClaudeArgs      []string `json:"claudeArgs,omitempty"` // Claude CLI arguments given after --
+	Branch          string   `json:"branch,omitempty"`     // Git branch the session was created on
+	Archived        string   `json:"archived,omitempty"`   // RFC3339 timestamp, set while the session is in the archive
+	Counters        Counters `json:"counters"`
+
+	// Lineage lists the forks, fresh restarts and imports that produced the session,
+	// oldest first. A fork starts a new lineage.
+	Lineage []LineageEvent `json:"lineage,omitempty"`
 }
 
-// ReadMetadata reads all metadata files from a session folder.
-// Missing files result in empty strings in the returned struct (not an error).
-// Only returns an error if reading fails for reasons other than file not existing.
+// PreviousNames returns the names the session had before fresh restarts
+// replaced them, oldest first
+func (m *SessionMetadata) PreviousNames() []string {
+	var names []string
+	for _, event := range m.Lineage {
+		if event.Op == OpFresh {
+			names = append(names, event.From)
+		}
+	}
+	return names
+}
+
+// ReadMetadata reads a session's metadata from session.json, or from the
+// legacy dotfiles when the session has not been migrated yet. Missing files
+// result in empty fields (not an error). The Claude session ID falls back to
+// the one in the folder name.
 func ReadMetadata(fs afero.Fs, sessionPath string) (*SessionMetadata, error) {
+	path := filepath.Join(sessionPath, MetadataFile)
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return readLegacyMetadata(fs, sessionPath)
+		}
+		return nil, err
+	}
+
 	metadata := &SessionMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if metadata.Version > MetadataVersion {
+		return nil, fmt.Errorf("%s has version %d; this claudex reads up to version %d", path, metadata.Version, MetadataVersion)
+	}
+	if metadata.ClaudeSessionID == "" {
+		metadata.ClaudeSessionID = ExtractClaudeSessionID(filepath.Base(sessionPath))
+	}
+	return metadata, nil
+}
 
-	// Read description
-	desc, err := readMetadataFile(fs, filepath.Join(sessionPath, DescriptionFile))
+// readLegacyMetadata assembles the metadata from the per-value dotfiles
+func readLegacyMetadata(fs afero.Fs, sessionPath string) (*SessionMetadata, error) {
+	metadata := &SessionMetadata{
+		ClaudeSessionID: ExtractClaudeSessionID(filepath.Base(sessionPath)),
+	}
+
+	strs := []struct {
+		file  string
+		what  string
+		field *string
+	}{
+		{DescriptionFile, "description", &metadata.Description},
+		{CreatedFile, "created timestamp", &metadata.Created},
+		{LastUsedFile, "last used timestamp", &metadata.LastUsed},
+		{AgentFile, "agent", &metadata.Agent},
+		{TemplateFile, "template", &metadata.Template},
+	}
+	for _, s := range strs {
+		value, err := readMetadataFile(fs, filepath.Join(sessionPath, s.file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", s.what, err)
+		}
+		*s.field = value
+	}
+
+	claudeArgs, err := readMetadataFile(fs, filepath.Join(sessionPath, ClaudeArgsFile))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read description: %w", err)
+		return nil, fmt.Errorf("failed to read claude args: %w", err)
+	}
+	if claudeArgs != "" {
+		metadata.ClaudeArgs = strings.Split(claudeArgs, "\n")
 	}
-	metadata.Description = desc
 
-	// Read created timestamp
-	created, err := readMetadataFile(fs, filepath.Join(sessionPath, CreatedFile))
+	if metadata.Counters.DocUpdates, err = readIntFile(fs, filepath.Join(sessionPath, DocUpdateCounterFile)); err != nil {
+		return nil, err
+	}
+	if metadata.Counters.LastProcessedLine, err = readIntFile(fs, filepath.Join(sessionPath, LastProcessedLineFile)); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// WriteMetadata atomically replaces session.json. The first write removes
+// the legacy dotfiles it supersedes.
+func WriteMetadata(fs afero.Fs, sessionPath string, metadata *SessionMetadata) error {
+	path := filepath.Join(sessionPath, MetadataFile)
+	_, statErr := fs.Stat(path)
+
+	metadata.Version = MetadataVersion
+	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read created timestamp: %w", err)
+		return err
+	}
+	if err := writeFileAtomic(fs, path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
-	metadata.Created = created
 
-	// Read last used timestamp
-	lastUsed, err := readMetadataFile(fs, filepath.Join(sessionPath, LastUsedFile))
+	if os.IsNotExist(statErr) {
+		for _, name := range legacyFiles {
+			fs.Remove(filepath.Join(sessionPath, name)) // Ignore errors - file may not exist
+		}
+	}
+	return nil
+}
+
+// UpdateMetadata reads a session's metadata, applies update and writes it
+// back while holding the session's metadata lock, so updates from other
+// processes are never lost
+func UpdateMetadata(fs afero.Fs, sessionPath string, update func(*SessionMetadata)) error {
+	l, err := lockMetadata(fs, sessionPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read last used timestamp: %w", err)
+		return err
 	}
-	metadata.LastUsed = lastUsed
+	defer l.Release()
 
-	return metadata, nil
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return err
+	}
+	update(metadata)
+	return WriteMetadata(fs, sessionPath, metadata)
 }
 
-// ReadDescription reads only the description file from a session folder.
-// Returns empty string if the file doesn't exist.
+// lockMetadata acquires the metadata lock of a session, waiting while another
+// writer holds it and breaking a lock older than metadataLockStale
+func lockMetadata(fs afero.Fs, sessionPath string) (*lock.Lock, error) {
+	path := filepath.Join(sessionPath, metadataLockFile)
+	locks := lock.New(fs)
+	deadline := time.Now().Add(metadataLockTimeout)
+	for {
+		l, err := locks.Acquire(path)
+		if err == nil {
+			return l, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", MetadataFile, err)
+		}
+		if info, statErr := fs.Stat(path); statErr == nil && time.Since(info.ModTime()) > metadataLockStale {
+			fs.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no claudex process is running", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// MigrateMetadata converts a session's legacy dotfiles to session.json.
+// Returns false when the session already has one or has no dotfiles.
+func MigrateMetadata(fs afero.Fs, sessionPath string) (bool, error) {
+	if _, err := fs.Stat(filepath.Join(sessionPath, MetadataFile)); err == nil {
+		return false, nil
+	}
+	if !hasLegacyFiles(fs, sessionPath) {
+		return false, nil
+	}
+	metadata, err := readLegacyMetadata(fs, sessionPath)
+	if err != nil {
+		return false, err
+	}
+	if err := WriteMetadata(fs, sessionPath, metadata); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// hasLegacyFiles reports whether any of the dotfiles session.json replaces exist
+func hasLegacyFiles(fs afero.Fs, sessionPath string) bool {
+	for _, name := range legacyFiles {
+		if _, err := fs.Stat(filepath.Join(sessionPath, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partial file
+func writeFileAtomic(fs afero.Fs, path string, data []byte) error {
+	tmp, err := afero.TempFile(fs, filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		fs.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		fs.Remove(tmp.Name())
+		return err
+	}
+	if err := fs.Chmod(tmp.Name(), 0644); err != nil {
+		fs.Remove(tmp.Name())
+		return err
+	}
+	if err := fs.Rename(tmp.Name(), path); err != nil {
+		fs.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// ReadDescription reads only the description of a session.
+// Returns empty string if it has none.
 func ReadDescription(fs afero.Fs, sessionPath string) (string, error) {
-	path := filepath.Join(sessionPath, DescriptionFile)
-	return readMetadataFile(fs, path)
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return "", err
+	}
+	return metadata.Description, nil
 }
 
-// ReadCreatedTimestamp reads only the created timestamp file from a session folder.
-// Returns empty string if the file doesn't exist.
+// ReadCreatedTimestamp reads only the created timestamp of a session.
+// Returns empty string if it has none.
 func ReadCreatedTimestamp(fs afero.Fs, sessionPath string) (string, error) {
-	path := filepath.Join(sessionPath, CreatedFile)
-	return readMetadataFile(fs, path)
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return "", err
+	}
+	return metadata.Created, nil
 }
 
-// ReadLastUsedTimestamp reads only the last used timestamp file from a session folder.
-// Returns empty string if the file doesn't exist.
+// ReadLastUsedTimestamp reads only the last used timestamp of a session.
+// Returns empty string if it has none.
 func ReadLastUsedTimestamp(fs afero.Fs, sessionPath string) (string, error) {
-	path := filepath.Join(sessionPath, LastUsedFile)
-	return readMetadataFile(fs, path)
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return "", err
+	}
+	return metadata.LastUsed, nil
+}
+
+// ReadAgent reads only the entry agent of a session.
+// Returns empty string if it has none.
+func ReadAgent(fs afero.Fs, sessionPath string) (string, error) {
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return "", err
+	}
+	return metadata.Agent, nil
+}
+
+// WriteAgent records the entry agent of a session. Forks and fresh sessions
+// copy the folder and so keep it.
+func WriteAgent(fs afero.Fs, sessionPath, agent string) error {
+	return UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.Agent = agent
+	})
+}
+
+// ReadTemplate reads only the activation template of a session.
+// Returns empty string if it has none.
+func ReadTemplate(fs afero.Fs, sessionPath string) (string, error) {
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return "", err
+	}
+	return metadata.Template, nil
+}
+
+// WriteTemplate records the activation template of a session
+func WriteTemplate(fs afero.Fs, sessionPath, name string) error {
+	return UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.Template = name
+	})
+}
+
+// ReadClaudeArgs reads the Claude CLI arguments stored for a session.
+// Returns nil if it has none.
+func ReadClaudeArgs(fs afero.Fs, sessionPath string) ([]string, error) {
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.ClaudeArgs, nil
+}
+
+// WriteClaudeArgs stores the Claude CLI arguments resume, fork and fresh
+// reapply. An empty list removes them.
+func WriteClaudeArgs(fs afero.Fs, sessionPath string, args []string) error {
+	return UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.ClaudeArgs = args
+	})
+}
+
+// ClaudeSessionID returns the Claude session ID of a session, falling back
+// to the one in the folder name
+func ClaudeSessionID(fs afero.Fs, sessionPath string) string {
+	if metadata, err := ReadMetadata(fs, sessionPath); err == nil {
+		return metadata.ClaudeSessionID
+	}
+	return ExtractClaudeSessionID(filepath.Base(sessionPath))
 }
 
 // readMetadataFile reads a metadata file and returns its trimmed content.
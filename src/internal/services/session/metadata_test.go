@@ -2,9 +2,12 @@ package session
 
 import (
 	"testing"
+	"time"
 
+	"claudex/internal/services/lock"
 	"claudex/internal/testutil"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -257,3 +260,260 @@ func Test_ReadMetadata_UnicodeCharacters(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "Implement feature 🚀 with emoji support", metadata.Description)
 }
+
+// Test_ReadAgent tests reading the entry agent written at creation
+func Test_ReadAgent(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateDir(sessionPath)
+
+	// Exercise - missing file means the default agent
+	agent, err := ReadAgent(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "", agent)
+
+	require.NoError(t, WriteAgent(h.FS, sessionPath, "researcher"))
+
+	agent, err = ReadAgent(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "researcher", agent)
+
+	metadata, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "researcher", metadata.Agent)
+}
+
+// Test_ReadTemplate tests reading the activation template written at creation
+func Test_ReadTemplate(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateDir(sessionPath)
+
+	template, err := ReadTemplate(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "", template)
+
+	require.NoError(t, WriteTemplate(h.FS, sessionPath, "bugfix"))
+
+	metadata, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "bugfix", metadata.Template)
+}
+
+// Test_WriteClaudeArgs tests storing, replacing and clearing a session's
+// Claude CLI arguments
+func Test_WriteClaudeArgs(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateDir(sessionPath)
+
+	args, err := ReadClaudeArgs(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Nil(t, args)
+
+	require.NoError(t, WriteClaudeArgs(h.FS, sessionPath, []string{"--model", "opus", "--add-dir", "../my api"}))
+
+	metadata, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"--model", "opus", "--add-dir", "../my api"}, metadata.ClaudeArgs)
+
+	require.NoError(t, WriteClaudeArgs(h.FS, sessionPath, nil))
+	args, err = ReadClaudeArgs(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Nil(t, args)
+	testutil.AssertNoFileExists(t, h.FS, sessionPath+"/"+ClaudeArgsFile)
+}
+
+// Test_WriteMetadata_RoundTrip tests every field survives session.json
+func Test_WriteMetadata_RoundTrip(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	h.CreateDir(sessionPath)
+
+	written := &SessionMetadata{
+		ClaudeSessionID: "11111111-2222-3333-4444-555555555555",
+		Description:     "Add auth",
+		Created:         "2024-01-15T10:30:00Z",
+		LastUsed:        "2024-01-16T09:00:00Z",
+		Parent:          "login-12345678-abcd-ef12-3456-7890abcdef12",
+		Tags:            []string{"backend", "auth"},
+		Agent:           "architect",
+		Template:        "feature",
+		ClaudeArgs:      []string{"--model", "opus"},
+		Branch:          "feat/auth",
+		Counters:        Counters{DocUpdates: 3, LastProcessedLine: 120},
+	}
+	require.NoError(t, WriteMetadata(h.FS, sessionPath, written))
+
+	read, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, MetadataVersion, read.Version)
+	require.Equal(t, written, read)
+
+	// No temporary files are left behind
+	entries, err := afero.ReadDir(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, MetadataFile, entries[0].Name())
+}
+
+// Test_ReadMetadata_IDFromFolderName tests the Claude session ID falls back to
+// the folder name when session.json doesn't record one
+func Test_ReadMetadata_IDFromFolderName(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		MetadataFile: `{"version": 1, "description": "Add auth"}`,
+	})
+
+	metadata, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", metadata.ClaudeSessionID)
+	require.Equal(t, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", ClaudeSessionID(h.FS, sessionPath))
+}
+
+// Test_ReadMetadata_RejectsNewerVersion tests a session.json written by a
+// newer claudex is not misread
+func Test_ReadMetadata_RejectsNewerVersion(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		MetadataFile: `{"version": 2, "description": "From the future"}`,
+	})
+
+	_, err := ReadMetadata(h.FS, sessionPath)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "has version 2; this claudex reads up to version 1")
+
+	// Writes don't clobber it either
+	require.Error(t, WriteAgent(h.FS, sessionPath, "architect"))
+	testutil.AssertFileContains(t, h.FS, sessionPath+"/"+MetadataFile, "From the future")
+}
+
+// Test_ReadMetadata_Malformed tests a corrupt session.json is reported
+func Test_ReadMetadata_Malformed(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		MetadataFile: `{"version": 1,`,
+	})
+
+	_, err := ReadMetadata(h.FS, sessionPath)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse")
+}
+
+// Test_MigrateMetadata tests the legacy dotfiles are folded into session.json
+// and removed, and that a migrated session is left alone
+func Test_MigrateMetadata(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionPath := "/.claudex/sessions/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{
+		DescriptionFile:             "Add auth",
+		CreatedFile:                 "2024-01-15T10:30:00Z",
+		LastUsedFile:                "2024-01-16T09:00:00Z",
+		AgentFile:                   "architect",
+		TemplateFile:                "feature",
+		ClaudeArgsFile:              "--model\nopus",
+		DocUpdateCounterFile:        "3",
+		LastProcessedLineFile:       "120",
+		LegacyLastProcessedLineFile: "99",
+		"session-overview.md":       "# Overview",
+	})
+
+	migrated, err := MigrateMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.True(t, migrated)
+
+	metadata, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, &SessionMetadata{
+		Version:         MetadataVersion,
+		ClaudeSessionID: "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+		Description:     "Add auth",
+		Created:         "2024-01-15T10:30:00Z",
+		LastUsed:        "2024-01-16T09:00:00Z",
+		Agent:           "architect",
+		Template:        "feature",
+		ClaudeArgs:      []string{"--model", "opus"},
+		Counters:        Counters{DocUpdates: 3, LastProcessedLine: 120},
+	}, metadata)
+
+	for _, name := range legacyFiles {
+		testutil.AssertNoFileExists(t, h.FS, sessionPath+"/"+name)
+	}
+	testutil.AssertFileExists(t, h.FS, sessionPath+"/session-overview.md")
+
+	migrated, err = MigrateMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.False(t, migrated)
+
+	// Folders without dotfiles are not sessions to migrate
+	h.CreateDir("/.claudex/sessions/notes")
+	migrated, err = MigrateMetadata(h.FS, "/.claudex/sessions/notes")
+	require.NoError(t, err)
+	require.False(t, migrated)
+	testutil.AssertNoFileExists(t, h.FS, "/.claudex/sessions/notes/"+MetadataFile)
+}
+
+// Test_UpdateMetadata_WaitsForLock tests that an update waits while another
+// writer holds the session's metadata lock, so neither loses the other's fields
+func Test_UpdateMetadata_WaitsForLock(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{".description": "Concurrent"})
+
+	held, err := lock.New(h.FS).Acquire(sessionPath + "/" + metadataLockFile)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		_, err := IncrementCounter(h.FS, sessionPath)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the update ran while the lock was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The holder's own update lands first and survives the waiting one
+	metadata, err := ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	metadata.Tags = []string{"auth"}
+	require.NoError(t, WriteMetadata(h.FS, sessionPath, metadata))
+	require.NoError(t, held.Release())
+	require.NoError(t, <-done)
+
+	metadata, err = ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, 1, metadata.Counters.DocUpdates)
+	require.Equal(t, []string{"auth"}, metadata.Tags)
+	exists, err := afero.Exists(h.FS, sessionPath+"/"+metadataLockFile)
+	require.NoError(t, err)
+	require.False(t, exists, "the lock is released")
+}
+
+// Test_UpdateMetadata_BreaksStaleLock tests that a lock left by a crashed
+// process doesn't block updates
+func Test_UpdateMetadata_BreaksStaleLock(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionPath := "/.claudex/sessions/test-session"
+	h.CreateSessionWithFiles(sessionPath, map[string]string{metadataLockFile: "123\n"})
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, h.FS.Chtimes(sessionPath+"/"+metadataLockFile, old, old))
+
+	require.NoError(t, WriteCounter(h.FS, sessionPath, 3))
+	value, err := ReadCounter(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, 3, value)
+}
@@ -3,14 +3,13 @@
 package session
 
 import (
-	"bytes"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
-	"claudex/internal/services/commander"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/usage"
 
 	"github.com/spf13/afero"
 )
@@ -92,22 +91,18 @@ func RenameWithClaudeID(fs afero.Fs, sessionPath, claudeSessionID string) error
 	return nil
 }
 
-// GenerateNameWithCmd generates a session name using the provided Commander
-func GenerateNameWithCmd(cmd commander.Commander, description string) (string, error) {
+// GenerateName generates a session name slug from a description using the given LLM backend.
+// An empty model leaves the choice to the backend.
+func GenerateName(backend llm.Backend, model, description string) (string, error) {
 	prompt := fmt.Sprintf("Generate a short, descriptive slug (2-4 words max, lowercase, hyphen-separated) for a work session based on this Description: '%s'. Reply with ONLY the slug, nothing else. Examples: 'auth-refactor', 'api-performance-fix', 'user-dashboard-ui'", description)
 
-	// Create a pipe to capture output
-	var stdout bytes.Buffer
-	stdin := strings.NewReader(prompt)
-
-	// Use Start method which supports stdin/stdout/stderr
-	err := cmd.Start("claude", stdin, &stdout, os.Stderr, "-p")
+	resp, err := backend.Complete(llm.Request{Prompt: prompt, Model: model, Feature: usage.FeatureSessionName})
 	if err != nil {
 		return "", err
 	}
 
 	re := regexp.MustCompile(`[a-z0-9-]+`)
-	matches := re.FindAllString(stdout.String(), -1)
+	matches := re.FindAllString(resp.Text, -1)
 
 	if len(matches) == 0 {
 		return "", fmt.Errorf("no valid slug")
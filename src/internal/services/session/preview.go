@@ -0,0 +1,71 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// overviewFile is the session overview maintained by the autodoc hooks
+const overviewFile = "session-overview.md"
+
+// DocFile is a document in a session folder
+type DocFile struct {
+	Path    string // Relative to the session folder, with forward slashes
+	Size    int64
+	ModTime time.Time
+}
+
+// Preview is what the session selector shows of the highlighted session
+type Preview struct {
+	Overview string    // Content of session-overview.md, empty when missing
+	Docs     []DocFile // In path order; session.json and dotfiles excluded
+	Updated  time.Time // Latest modification of a document, zero without any
+	Counters Counters
+}
+
+// LoadPreview reads the overview, document list and autodoc counters of a
+// session folder
+func LoadPreview(fs afero.Fs, sessionPath string) (*Preview, error) {
+	metadata, err := ReadMetadata(fs, sessionPath)
+	if err != nil {
+		return nil, err
+	}
+	p := &Preview{Counters: metadata.Counters}
+
+	err = afero.Walk(fs, sessionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != sessionPath {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || info.Name() == MetadataFile {
+			return nil
+		}
+		rel, err := filepath.Rel(sessionPath, path)
+		if err != nil {
+			rel = info.Name()
+		}
+		p.Docs = append(p.Docs, DocFile{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		if info.ModTime().After(p.Updated) {
+			p.Updated = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session files: %w", err)
+	}
+
+	if data, err := afero.ReadFile(fs, filepath.Join(sessionPath, overviewFile)); err == nil {
+		p.Overview = string(data)
+	}
+	return p, nil
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPreview(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionPath := "/project/.claudex/sessions/auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+	require.NoError(t, WriteMetadata(h.FS, sessionPath, &SessionMetadata{
+		Description: "Refactor auth",
+		Counters:    Counters{DocUpdates: 3, LastProcessedLine: 42},
+	}))
+	h.WriteFile(sessionPath+"/session-overview.md", "# Overview")
+	h.WriteFile(sessionPath+"/research/tokens.md", "JWT refresh")
+	h.WriteFile(sessionPath+"/.doc-update-counter", "3")
+	h.WriteFile(sessionPath+"/.cache/state.md", "hidden")
+
+	updated := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	require.NoError(t, h.FS.Chtimes(sessionPath+"/research/tokens.md", updated, updated))
+	require.NoError(t, h.FS.Chtimes(sessionPath+"/session-overview.md", updated.Add(-time.Hour), updated.Add(-time.Hour)))
+
+	p, err := LoadPreview(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Overview", p.Overview)
+	assert.Equal(t, Counters{DocUpdates: 3, LastProcessedLine: 42}, p.Counters)
+	assert.True(t, updated.Equal(p.Updated))
+	require.Len(t, p.Docs, 2)
+	assert.Equal(t, "research/tokens.md", p.Docs[0].Path)
+	assert.Equal(t, int64(11), p.Docs[0].Size)
+	assert.Equal(t, "session-overview.md", p.Docs[1].Path)
+}
+
+func TestLoadPreview_WithoutOverview(t *testing.T) {
+	h := testutil.NewTestHarness()
+	sessionPath := "/project/.claudex/sessions/empty"
+	require.NoError(t, WriteMetadata(h.FS, sessionPath, &SessionMetadata{Description: "Empty"}))
+
+	p, err := LoadPreview(h.FS, sessionPath)
+	require.NoError(t, err)
+	assert.Empty(t, p.Overview)
+	assert.Empty(t, p.Docs)
+	assert.True(t, p.Updated.IsZero())
+}
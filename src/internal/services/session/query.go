@@ -0,0 +1,92 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Session statuses a query can select with status:
+const (
+	StatusActive   = "active"   // In the session store
+	StatusArchived = "archived" // In the archive
+)
+
+// Qualifier prefixes of a query
+const (
+	tagQualifier    = "tag:"
+	statusQualifier = "status:"
+)
+
+// filterSep separates the name in a SessionItem filter value from its qualifiers
+const filterSep = "\x1f"
+
+// Query is a session search or filter: free-text words narrowed by
+// tag:<name> and status:active|archived qualifiers
+type Query struct {
+	Words  []string // Lowercased free-text words
+	Tags   []string // Tags a session must all have
+	Status string   // StatusActive, StatusArchived, or empty for either
+}
+
+// ParseQuery splits a query into words and qualifiers. Unknown statuses are
+// kept so Validate can report them; a filter just matches nothing.
+func ParseQuery(s string) Query {
+	var q Query
+	for _, field := range strings.Fields(s) {
+		lower := strings.ToLower(field)
+		switch {
+		case strings.HasPrefix(lower, tagQualifier):
+			if tag := strings.TrimPrefix(lower, tagQualifier); tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+		case strings.HasPrefix(lower, statusQualifier):
+			q.Status = strings.TrimPrefix(lower, statusQualifier)
+		default:
+			q.Words = append(q.Words, lower)
+		}
+	}
+	return q
+}
+
+// Validate reports a status qualifier other than active or archived
+func (q Query) Validate() error {
+	switch q.Status {
+	case "", StatusActive, StatusArchived:
+		return nil
+	}
+	return fmt.Errorf("unknown status %q: use status:%s or status:%s", q.Status, StatusActive, StatusArchived)
+}
+
+// Matches reports whether a session with these tags and status satisfies
+// the qualifiers; the words are matched by the caller
+func (q Query) Matches(tags []string, status string) bool {
+	if q.Status != "" && q.Status != status {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !HasTag(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Text returns the free-text words joined by spaces
+func (q Query) Text() string {
+	return strings.Join(q.Words, " ")
+}
+
+// ParseFilterValue splits a SessionItem filter value into the session name
+// and the tags and status the item was listed with
+func ParseFilterValue(value string) (name string, tags []string, status string) {
+	parts := strings.Split(value, filterSep)
+	for _, part := range parts[1:] {
+		switch {
+		case strings.HasPrefix(part, tagQualifier):
+			tags = append(tags, strings.TrimPrefix(part, tagQualifier))
+		case strings.HasPrefix(part, statusQualifier):
+			status = strings.TrimPrefix(part, statusQualifier)
+		}
+	}
+	return parts[0], tags, status
+}
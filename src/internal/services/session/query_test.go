@@ -0,0 +1,50 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"Auth, #backend", "auth", " bug-123 ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "backend", "bug-123"}, tags)
+
+	_, err = NormalizeTags([]string{"needs review"})
+	assert.ErrorContains(t, err, `invalid tag "needs review"`)
+}
+
+func TestParseQuery(t *testing.T) {
+	q := ParseQuery("Token tag:Auth refresh status:archived tag:")
+	assert.Equal(t, []string{"token", "refresh"}, q.Words)
+	assert.Equal(t, []string{"auth"}, q.Tags)
+	assert.Equal(t, StatusArchived, q.Status)
+	assert.Equal(t, "token refresh", q.Text())
+	assert.NoError(t, q.Validate())
+
+	assert.ErrorContains(t, ParseQuery("status:done").Validate(), `unknown status "done"`)
+}
+
+func TestQuery_Matches(t *testing.T) {
+	q := ParseQuery("tag:auth tag:backend status:active")
+	assert.True(t, q.Matches([]string{"backend", "auth", "ui"}, StatusActive))
+	assert.False(t, q.Matches([]string{"auth"}, StatusActive))
+	assert.False(t, q.Matches([]string{"auth", "backend"}, StatusArchived))
+	assert.True(t, ParseQuery("words only").Matches(nil, StatusArchived))
+}
+
+func TestSessionItem_FilterValueRoundTrip(t *testing.T) {
+	item := SessionItem{Title: "auth-refactor", Tags: []string{"auth", "backend"}, Status: StatusActive}
+
+	name, tags, status := ParseFilterValue(item.FilterValue())
+	assert.Equal(t, "auth-refactor", name)
+	assert.Equal(t, []string{"auth", "backend"}, tags)
+	assert.Equal(t, StatusActive, status)
+
+	name, tags, status = ParseFilterValue(SessionItem{Title: "Create New Session"}.FilterValue())
+	assert.Equal(t, "Create New Session", name)
+	assert.Empty(t, tags)
+	assert.Empty(t, status)
+}
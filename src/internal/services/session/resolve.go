@@ -0,0 +1,82 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// AmbiguousError reports a session query that matches several sessions
+type AmbiguousError struct {
+	Query      string
+	Candidates []string // Matching session names, most recently used first
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("session name %q is ambiguous (%d matches):\n  %s",
+		e.Query, len(e.Candidates), strings.Join(e.Candidates, "\n  "))
+}
+
+// matchers rank how a query matches a session name, best first. Resolve uses
+// the first tier that matches anything, so "auth" picks auth-refactor-... even
+// when oauth-fix-... also contains it.
+var matchers = []func(name, query string) bool{
+	// Exact name
+	func(name, query string) bool { return name == query },
+	// Prefix (e.g. "auth" for auth-refactor-33342657-...)
+	func(name, query string) bool { return strings.HasPrefix(name, query) },
+	// Case-insensitive substring, including the Claude session ID suffix
+	func(name, query string) bool {
+		return strings.Contains(strings.ToLower(name), strings.ToLower(query))
+	},
+	// Characters in order (e.g. "arf" for auth-refactor)
+	func(name, query string) bool { return isSubsequence(strings.ToLower(name), strings.ToLower(query)) },
+}
+
+// Resolve finds a session folder name in sessionsDir from a name, prefix or
+// fuzzy query. Returns an *AmbiguousError listing the candidates when the best
+// matching tier holds several sessions.
+func Resolve(fs afero.Fs, sessionsDir, query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("session name is required")
+	}
+
+	sessions, err := GetSessions(fs, sessionsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	for _, matches := range matchers {
+		var candidates []string
+		for _, s := range sessions {
+			if matches(s.Title, query) {
+				candidates = append(candidates, s.Title)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			return "", &AmbiguousError{Query: query, Candidates: candidates}
+		}
+	}
+
+	return "", fmt.Errorf("session not found: %s", query)
+}
+
+// isSubsequence reports whether the characters of query appear in s in order
+func isSubsequence(s, query string) bool {
+	q := []rune(query)
+	i := 0
+	for _, r := range s {
+		if i < len(q) && r == q[i] {
+			i++
+		}
+	}
+	return i == len(q)
+}
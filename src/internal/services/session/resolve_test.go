@@ -0,0 +1,77 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const resolveSessionsDir = "/project/.claudex/sessions"
+
+func setupResolveSessions(h *testutil.TestHarness) {
+	sessions := map[string]string{
+		"auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff": "2024-01-15T10:00:00Z",
+		"oauth-fix-11112222-3333-4444-5555-666666666666":     "2024-01-14T10:00:00Z",
+		"billing-ui-22223333-4444-5555-6666-777777777777":    "2024-01-16T10:00:00Z",
+		"billing-api-33334444-5555-6666-7777-888888888888":   "2024-01-13T10:00:00Z",
+	}
+	for name, lastUsed := range sessions {
+		h.CreateSessionWithFiles(filepath.Join(resolveSessionsDir, name), map[string]string{
+			LastUsedFile: lastUsed,
+		})
+	}
+}
+
+func Test_Resolve_Tiers(t *testing.T) {
+	h := testutil.NewTestHarness()
+	setupResolveSessions(h)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff", "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"},
+		{"auth", "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"}, // prefix beats oauth's substring
+		{"FIX", "oauth-fix-11112222-3333-4444-5555-666666666666"},
+		{"11112222", "oauth-fix-11112222-3333-4444-5555-666666666666"},
+		{"bui", "billing-ui-22223333-4444-5555-6666-777777777777"},
+		{"arf", "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(h.FS, resolveSessionsDir, tt.query)
+		require.NoError(t, err, tt.query)
+		assert.Equal(t, tt.want, got, tt.query)
+	}
+}
+
+func Test_Resolve_AmbiguousListsCandidates(t *testing.T) {
+	h := testutil.NewTestHarness()
+	setupResolveSessions(h)
+
+	_, err := Resolve(h.FS, resolveSessionsDir, "billing")
+
+	var ambiguous *AmbiguousError
+	require.True(t, errors.As(err, &ambiguous))
+	// Most recently used first
+	assert.Equal(t, []string{
+		"billing-ui-22223333-4444-5555-6666-777777777777",
+		"billing-api-33334444-5555-6666-7777-888888888888",
+	}, ambiguous.Candidates)
+	assert.Contains(t, err.Error(), "\n  billing-api-")
+}
+
+func Test_Resolve_NotFound(t *testing.T) {
+	h := testutil.NewTestHarness()
+	setupResolveSessions(h)
+
+	_, err := Resolve(h.FS, resolveSessionsDir, "zzz")
+	assert.EqualError(t, err, "session not found: zzz")
+
+	_, err = Resolve(h.FS, "/missing", "auth")
+	assert.EqualError(t, err, "session not found: auth")
+}
@@ -17,7 +17,7 @@ import (
 
 // GetSessions retrieves all sessions from the sessions directory
 func GetSessions(fs afero.Fs, sessionsDir string) ([]SessionItem, error) {
-	entries, err := os.ReadDir(sessionsDir)
+	entries, err := afero.ReadDir(fs, sessionsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []SessionItem{}, nil
@@ -34,32 +34,45 @@ func GetSessions(fs afero.Fs, sessionsDir string) ([]SessionItem, error) {
 		var desc string
 		var lastUsedTime time.Time
 		var lastUsedStr string
+		var parent string
+		var tags []string
+		var pinned bool
 
-		if data, err := afero.ReadFile(fs, filepath.Join(sessionsDir, entry.Name(), ".description")); err == nil {
-			desc = strings.TrimSpace(string(data))
-		}
+		if metadata, err := ReadMetadata(fs, filepath.Join(sessionsDir, entry.Name())); err == nil {
+			desc = metadata.Description
+			parent = metadata.Parent
+			tags = metadata.Tags
+			pinned = metadata.Pinned
 
-		// Try to read last_used first, fall back to created
-		if data, err := afero.ReadFile(fs, filepath.Join(sessionsDir, entry.Name(), ".last_used")); err == nil {
-			lastUsedStr = strings.TrimSpace(string(data))
-			if t, err := time.Parse(time.RFC3339, lastUsedStr); err == nil {
-				lastUsedTime = t
-				lastUsedStr = t.Format("2 Jan 2006 15:04:05")
+			// Use last_used first, fall back to created
+			lastUsedStr = metadata.LastUsed
+			if lastUsedStr == "" {
+				lastUsedStr = metadata.Created
 			}
-		} else if data, err := afero.ReadFile(fs, filepath.Join(sessionsDir, entry.Name(), ".created")); err == nil {
-			// Fall back to created date if no last_used file
-			lastUsedStr = strings.TrimSpace(string(data))
 			if t, err := time.Parse(time.RFC3339, lastUsedStr); err == nil {
 				lastUsedTime = t
 				lastUsedStr = t.Format("2 Jan 2006 15:04:05")
 			}
 		}
 
+		description := fmt.Sprintf("%s • %s", desc, lastUsedStr)
+		if parent != "" {
+			description += " • forked from " + StripClaudeSessionID(parent)
+		}
+		if len(tags) > 0 {
+			description += " • #" + strings.Join(tags, " #")
+		}
+		if pinned {
+			description += " • pinned"
+		}
+
 		sessions = append(sessions, SessionItem{
 			Title:       entry.Name(),
-			Description: fmt.Sprintf("%s • %s", desc, lastUsedStr),
+			Description: description,
 			Created:     lastUsedTime,
 			ItemType:    "session",
+			Tags:        tags,
+			Status:      StatusActive,
 		})
 	}
 
@@ -79,7 +92,9 @@ func UpdateLastUsedWithDeps(fs afero.Fs, clk clock.Clock, sessionPath string) er
 	}
 
 	lastUsed := clk.Now().UTC().Format(time.RFC3339)
-	return afero.WriteFile(fs, filepath.Join(sessionPath, ".last_used"), []byte(lastUsed), 0644)
+	return UpdateMetadata(fs, sessionPath, func(m *SessionMetadata) {
+		m.LastUsed = lastUsed
+	})
 }
 
 // UpdateLastUsed is a wrapper that uses default dependencies
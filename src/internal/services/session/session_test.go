@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/services/llm"
 	"claudex/internal/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -106,8 +107,8 @@ func Test_StripClaudeSessionID(t *testing.T) {
 	}
 }
 
-// Test_GenerateNameWithCmd tests slug generation using Claude CLI
-func Test_GenerateNameWithCmd(t *testing.T) {
+// Test_GenerateName tests slug generation using the Claude CLI backend
+func Test_GenerateName(t *testing.T) {
 	// Setup
 	h := testutil.NewTestHarness()
 
@@ -115,7 +116,7 @@ func Test_GenerateNameWithCmd(t *testing.T) {
 	h.Commander.OnPattern("claude", "-p").Return([]byte("feature-login"), nil)
 
 	description := "Implement login feature"
-	slug, err := GenerateNameWithCmd(h.Commander, description)
+	slug, err := GenerateName(llm.NewClaudeCLI(h.Commander, h.Env, ""), "", description)
 
 	// Verify slug generation
 	require.NoError(t, err)
@@ -145,8 +146,15 @@ func Test_UpdateLastUsedWithDeps(t *testing.T) {
 
 	// Verify
 	require.NoError(t, err)
-	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionDir, ".last_used"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionDir, ".last_used"), "2024-01-15T14:00:00Z")
+	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionDir, MetadataFile), `"lastUsed": "2024-01-15T14:00:00Z"`)
+
+	// The legacy files are folded into session.json
+	metadata, err := ReadMetadata(h.FS, sessionDir)
+	require.NoError(t, err)
+	require.Equal(t, "Login feature", metadata.Description)
+	require.Equal(t, "2024-01-15T10:30:00Z", metadata.Created)
+	require.Equal(t, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", metadata.ClaudeSessionID)
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(sessionDir, ".description"))
 }
 
 // Test_UpdateLastUsedWithDeps_EphemeralSession tests that ephemeral sessions (empty path) are handled
@@ -160,3 +168,28 @@ func Test_UpdateLastUsedWithDeps_EphemeralSession(t *testing.T) {
 	// Verify - no error for ephemeral sessions
 	require.NoError(t, err)
 }
+
+// Test_GetSessions_ShowsParent tests forks name their parent in the
+// description line of the selector
+func Test_GetSessions_ShowsParent(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	sessionsDir := "/.claudex/sessions"
+	forkPath := filepath.Join(sessionsDir, "oauth-11111111-2222-3333-4444-555555555555")
+	h.CreateDir(forkPath)
+	require.NoError(t, WriteMetadata(h.FS, forkPath, &SessionMetadata{
+		Description: "OAuth",
+		LastUsed:    "2024-01-15T14:00:00Z",
+		Parent:      "login-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+	}))
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, "login-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"), map[string]string{
+		DescriptionFile: "Login",
+		LastUsedFile:    "2024-01-14T14:00:00Z",
+	})
+
+	sessions, err := GetSessions(h.FS, sessionsDir)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, "OAuth • 15 Jan 2024 14:00:00 • forked from login", sessions[0].Description)
+	require.Equal(t, "Login • 14 Jan 2024 14:00:00", sessions[1].Description)
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tagPattern is what a tag may contain once lowercased
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// NormalizeTags lowercases tags, splits comma-separated lists and drops
+// duplicates, keeping the order they were given in. Tags are single words
+// of letters, digits, '.', '_' and '-'.
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, list := range tags {
+		for _, tag := range strings.Split(list, ",") {
+			tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			if !tagPattern.MatchString(tag) {
+				return nil, fmt.Errorf("invalid tag %q: use letters, digits, '.', '_' and '-'", tag)
+			}
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// HasTag reports whether tags contains tag
+func HasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"claudex/internal/services/env"
+
+	"github.com/spf13/afero"
+)
+
+// nonAlphanumeric matches the characters Claude Code replaces when it turns a
+// project path into a transcript directory name
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// ClaudeProjectsDir returns the directory Claude Code keeps transcripts in:
+// $CLAUDE_CONFIG_DIR/projects, or ~/.claude/projects
+func ClaudeProjectsDir(environment env.Environment) (string, error) {
+	if configDir := environment.Get("CLAUDE_CONFIG_DIR"); configDir != "" {
+		return filepath.Join(configDir, "projects"), nil
+	}
+
+	home, err := HomeDir(environment)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "projects"), nil
+}
+
+// HomeDir returns the user's home directory: $HOME, or %USERPROFILE% on Windows
+func HomeDir(environment env.Environment) (string, error) {
+	home := environment.Get("HOME")
+	if home == "" {
+		home = environment.Get("USERPROFILE")
+	}
+	if home == "" {
+		return "", fmt.Errorf("HOME environment variable not set")
+	}
+	return home, nil
+}
+
+// EncodeProjectPath returns the transcript directory name Claude Code uses
+// for a project (e.g. /home/me/app -> -home-me-app)
+func EncodeProjectPath(projectDir string) string {
+	return nonAlphanumeric.ReplaceAllString(projectDir, "-")
+}
+
+// Transcripts holds the transcript files of a Claude session
+type Transcripts struct {
+	// Main is the session's own transcript
+	Main string
+
+	// Subagents are the transcripts of subagents the session started
+	Subagents []string
+}
+
+// FindTranscripts locates the transcript of a Claude session and its
+// subagents. The project's own transcript directory is tried first; if the
+// project moved, every project directory is searched for the session ID.
+func FindTranscripts(fs afero.Fs, environment env.Environment, projectDir, claudeSessionID string) (*Transcripts, error) {
+	if claudeSessionID == "" {
+		return nil, fmt.Errorf("session has no Claude session ID")
+	}
+
+	projectsDir, err := ClaudeProjectsDir(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := claudeSessionID + ".jsonl"
+	main := filepath.Join(projectsDir, EncodeProjectPath(projectDir), fileName)
+	if exists, _ := afero.Exists(fs, main); !exists {
+		matches, _ := afero.Glob(fs, filepath.Join(projectsDir, "*", fileName))
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no transcript found for Claude session %s in %s", claudeSessionID, projectsDir)
+		}
+		main = matches[0]
+	}
+
+	dir := filepath.Dir(main)
+	transcripts := &Transcripts{Main: main}
+
+	// Current CLI versions keep subagent transcripts next to the session
+	nested, _ := afero.Glob(fs, filepath.Join(dir, claudeSessionID, "subagents", "*.jsonl"))
+	transcripts.Subagents = append(transcripts.Subagents, nested...)
+
+	// Older versions write agent-*.jsonl files shared by every session of the project
+	shared, _ := afero.Glob(fs, filepath.Join(dir, "agent-*.jsonl"))
+	for _, path := range shared {
+		if transcriptSessionID(fs, path) == claudeSessionID {
+			transcripts.Subagents = append(transcripts.Subagents, path)
+		}
+	}
+
+	sort.Strings(transcripts.Subagents)
+	return transcripts, nil
+}
+
+// transcriptSessionID returns the sessionId recorded on the first line of a transcript
+func transcriptSessionID(fs afero.Fs, path string) string {
+	file, err := fs.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line struct {
+			SessionID string `json:"sessionId"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &line) == nil && line.SessionID != "" {
+			return line.SessionID
+		}
+	}
+	return ""
+}
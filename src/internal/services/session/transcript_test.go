@@ -0,0 +1,70 @@
+package session
+
+import (
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const transcriptSessionUUID = "33342657-73dc-407d-9aa6-a28f2e619268"
+
+func Test_EncodeProjectPath(t *testing.T) {
+	assert.Equal(t, "-home-me-my-app", EncodeProjectPath("/home/me/my.app"))
+	assert.Equal(t, "C--Users-me-app", EncodeProjectPath(`C:\Users\me\app`))
+}
+
+func Test_ClaudeProjectsDir(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/me")
+
+	dir, err := ClaudeProjectsDir(h.Env)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me/.claude/projects", dir)
+
+	h.Env.Set("CLAUDE_CONFIG_DIR", "/opt/claude")
+	dir, err = ClaudeProjectsDir(h.Env)
+	require.NoError(t, err)
+	assert.Equal(t, "/opt/claude/projects", dir)
+}
+
+func Test_FindTranscripts_WithSubagents(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/me")
+	dir := "/home/me/.claude/projects/-work-app"
+
+	h.WriteFile(dir+"/"+transcriptSessionUUID+".jsonl", `{"sessionId":"`+transcriptSessionUUID+`"}`)
+	h.WriteFile(dir+"/"+transcriptSessionUUID+"/subagents/agent-nested.jsonl", `{"sessionId":"`+transcriptSessionUUID+`"}`)
+	h.WriteFile(dir+"/agent-mine.jsonl", `{"type":"summary"}`+"\n"+`{"sessionId":"`+transcriptSessionUUID+`"}`)
+	h.WriteFile(dir+"/agent-other.jsonl", `{"sessionId":"another-session"}`)
+
+	transcripts, err := FindTranscripts(h.FS, h.Env, "/work/app", transcriptSessionUUID)
+	require.NoError(t, err)
+
+	assert.Equal(t, dir+"/"+transcriptSessionUUID+".jsonl", transcripts.Main)
+	assert.Equal(t, []string{
+		dir + "/" + transcriptSessionUUID + "/subagents/agent-nested.jsonl",
+		dir + "/agent-mine.jsonl",
+	}, transcripts.Subagents)
+}
+
+func Test_FindTranscripts_ProjectMoved(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/me")
+	h.WriteFile("/home/me/.claude/projects/-old-path/"+transcriptSessionUUID+".jsonl", "{}")
+
+	transcripts, err := FindTranscripts(h.FS, h.Env, "/new/path", transcriptSessionUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me/.claude/projects/-old-path/"+transcriptSessionUUID+".jsonl", transcripts.Main)
+}
+
+func Test_FindTranscripts_NotFound(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/me")
+
+	_, err := FindTranscripts(h.FS, h.Env, "/work/app", transcriptSessionUUID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no transcript found")
+}
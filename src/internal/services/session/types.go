@@ -2,7 +2,10 @@
 // It handles session metadata, storage operations, and naming utilities.
 package session
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // SessionItem represents session metadata for UI display and operations.
 // It is used by both UI components and session management functions.
@@ -10,8 +13,21 @@ type SessionItem struct {
 	Title       string
 	Description string
 	Created     time.Time
-	ItemType    string // "new", "ephemeral", "session"
+	ItemType    string   // "new", "ephemeral", "session", "archived"
+	Tags        []string // Session tags, for tag: filters
+	Status      string   // StatusActive or StatusArchived for sessions, for status: filters
 }
 
-// FilterValue implements the list.Item interface for Bubble Tea filtering
-func (i SessionItem) FilterValue() string { return i.Title }
+// FilterValue implements the list.Item interface for Bubble Tea filtering:
+// the title, followed by the tags and status that qualifiers match
+// (see ParseFilterValue)
+func (i SessionItem) FilterValue() string {
+	parts := []string{i.Title}
+	if i.Status != "" {
+		parts = append(parts, statusQualifier+i.Status)
+	}
+	for _, tag := range i.Tags {
+		parts = append(parts, tagQualifier+tag)
+	}
+	return strings.Join(parts, filterSep)
+}
@@ -0,0 +1,50 @@
+// Package terminal detects how claudex is being run: from an interactive
+// terminal, or from scripts, CI and editor tasks where nobody can answer a
+// prompt.
+package terminal
+
+import (
+	"os"
+
+	"claudex/internal/services/env"
+
+	"github.com/mattn/go-isatty"
+)
+
+// EnvNoPrompts is set to "1" by --no-prompts (alias --yes) so every claudex
+// process started from the same command (including subcommands) skips
+// optional prompts. It never confirms destructive actions.
+const EnvNoPrompts = "CLAUDEX_NO_PROMPTS"
+
+// Mode describes the terminal claudex runs in
+type Mode struct {
+	Interactive bool // stdin is a terminal
+	NoPrompts   bool // --no-prompts/--yes or CLAUDEX_NO_PROMPTS=1
+}
+
+// Detect inspects stdin and the environment
+func Detect(environment env.Environment) Mode {
+	return Mode{
+		Interactive: IsTerminal(os.Stdin),
+		NoPrompts:   isTrue(environment.Get(EnvNoPrompts)),
+	}
+}
+
+// CanPrompt reports whether claudex may ask the user a question
+func (m Mode) CanPrompt() bool {
+	return m.Interactive && !m.NoPrompts
+}
+
+// IsTerminal reports whether f is a TTY or console (including mintty/Git
+// Bash on Windows) rather than a pipe, file or /dev/null
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func isTrue(value string) bool {
+	switch value {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
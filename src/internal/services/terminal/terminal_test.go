@@ -0,0 +1,26 @@
+package terminal
+
+import (
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect_Environment(t *testing.T) {
+	h := testutil.NewTestHarness()
+
+	mode := Detect(h.Env)
+	assert.False(t, mode.NoPrompts)
+
+	h.Env.Set(EnvNoPrompts, "1")
+	mode = Detect(h.Env)
+	assert.True(t, mode.NoPrompts)
+}
+
+func TestCanPrompt(t *testing.T) {
+	assert.True(t, Mode{Interactive: true}.CanPrompt())
+	assert.False(t, Mode{Interactive: true, NoPrompts: true}.CanPrompt())
+	assert.False(t, Mode{Interactive: false}.CanPrompt())
+}
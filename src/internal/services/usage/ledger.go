@@ -0,0 +1,92 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudex/internal/services/paths"
+
+	"github.com/spf13/afero"
+)
+
+// FileLedger is the production implementation of Ledger: one JSON object per
+// line. Each entry is written with a single O_APPEND write, so concurrent
+// workers don't interleave lines.
+type FileLedger struct {
+	fs   afero.Fs
+	path string
+}
+
+// New creates a Ledger backed by the file at path
+func New(fs afero.Fs, path string) Ledger {
+	return &FileLedger{fs: fs, path: path}
+}
+
+// PathForJobs returns the ledger path of the project owning a jobs directory
+// (<project>/.claudex/jobs -> <project>/.claudex/usage.jsonl)
+func PathForJobs(jobsDir string) string {
+	return filepath.Join(filepath.Dir(jobsDir), filepath.Base(paths.UsageFile))
+}
+
+// Path returns the ledger file path
+func (l *FileLedger) Path() string {
+	return l.path
+}
+
+// Append records an entry
+func (l *FileLedger) Append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage entry: %w", err)
+	}
+
+	if err := l.fs.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage ledger directory: %w", err)
+	}
+
+	f, err := l.fs.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+	return nil
+}
+
+// Entries returns every recorded entry. Malformed lines (e.g. a write cut
+// short by a crash) are skipped.
+func (l *FileLedger) Entries() ([]Entry, error) {
+	data, err := afero.ReadFile(l.fs, l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,61 @@
+package usage
+
+import (
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/llm"
+)
+
+// Recorder is an llm.Backend that appends every call, successful or not,
+// to a usage ledger
+type Recorder struct {
+	backend llm.Backend
+	ledger  Ledger
+	clock   clock.Clock
+}
+
+// NewRecorder wraps backend so its calls are recorded in ledger
+func NewRecorder(backend llm.Backend, ledger Ledger, clk clock.Clock) *Recorder {
+	return &Recorder{
+		backend: backend,
+		ledger:  ledger,
+		clock:   clk,
+	}
+}
+
+// Name returns the wrapped backend's name
+func (r *Recorder) Name() string {
+	return r.backend.Name()
+}
+
+// Complete runs the request on the wrapped backend and records its usage.
+// Ledger failures never fail the call.
+func (r *Recorder) Complete(req llm.Request) (*llm.Response, error) {
+	resp, err := r.backend.Complete(req)
+
+	entry := Entry{
+		Time:    r.clock.Now().UTC().Format(time.RFC3339),
+		Session: req.Session,
+		Feature: req.Feature,
+		Backend: r.backend.Name(),
+		Model:   req.Model,
+	}
+	if resp != nil {
+		if resp.Model != "" {
+			entry.Model = resp.Model
+		}
+		entry.InputTokens = resp.Usage.InputTokens
+		entry.OutputTokens = resp.Usage.OutputTokens
+		entry.CacheCreationTokens = resp.Usage.CacheCreationTokens
+		entry.CacheReadTokens = resp.Usage.CacheReadTokens
+		entry.CostUSD = resp.Usage.CostUSD
+		entry.DurationMS = resp.Usage.DurationMS
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	_ = r.ledger.Append(entry)
+	return resp, err
+}
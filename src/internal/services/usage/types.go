@@ -0,0 +1,59 @@
+// Package usage records the token usage and cost of headless model calls in
+// a per-project, append-only ledger (.claudex/usage.jsonl), so background
+// features such as autodoc are no longer billed invisibly.
+package usage
+
+import "time"
+
+// FeatureSessionName tags session name generation. Background work is
+// tagged with its job kind (session-overview, index-update, create-index).
+const FeatureSessionName = "session-name"
+
+// Entry is one headless model call
+type Entry struct {
+	// Time is when the call finished (RFC3339)
+	Time string `json:"time"`
+
+	// Session is the Claudex session the call belongs to, if any
+	Session string `json:"session,omitempty"`
+
+	// Feature is what the call was for (job kind or "session-name")
+	Feature string `json:"feature"`
+
+	// Backend is the llm backend that served the call
+	Backend string `json:"backend"`
+
+	// Model is the model that served the call (or the one requested)
+	Model string `json:"model,omitempty"`
+
+	InputTokens         int     `json:"inputTokens"`
+	OutputTokens        int     `json:"outputTokens"`
+	CacheCreationTokens int     `json:"cacheCreationTokens,omitempty"`
+	CacheReadTokens     int     `json:"cacheReadTokens,omitempty"`
+	CostUSD             float64 `json:"costUsd"`
+	DurationMS          int64   `json:"durationMs,omitempty"`
+
+	// Error is set when the call failed
+	Error string `json:"error,omitempty"`
+}
+
+// Day returns the local calendar day of the entry (YYYY-MM-DD)
+func (e Entry) Day() string {
+	t, err := time.Parse(time.RFC3339, e.Time)
+	if err != nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// Ledger stores usage entries
+type Ledger interface {
+	// Append records an entry
+	Append(entry Entry) error
+
+	// Entries returns every recorded entry in ledger order
+	Entries() ([]Entry, error)
+
+	// Path returns the ledger file path
+	Path() string
+}
@@ -0,0 +1,104 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"claudex/internal/services/llm"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend returns a canned response and error
+type fakeBackend struct {
+	resp *llm.Response
+	err  error
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) Complete(req llm.Request) (*llm.Response, error) {
+	return b.resp, b.err
+}
+
+func TestPathForJobs(t *testing.T) {
+	assert.Equal(t, "/project/.claudex/usage.jsonl", PathForJobs("/project/.claudex/jobs"))
+}
+
+func TestLedger_AppendAndEntries(t *testing.T) {
+	h := testutil.NewTestHarness()
+	ledger := New(h.FS, "/project/.claudex/usage.jsonl")
+
+	entries, err := ledger.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries, "missing ledger reads as empty")
+
+	require.NoError(t, ledger.Append(Entry{Time: "2025-01-15T10:00:00Z", Feature: "index-update", CostUSD: 0.01}))
+	require.NoError(t, ledger.Append(Entry{Time: "2025-01-15T11:00:00Z", Feature: "session-overview", Session: "auth-1"}))
+
+	// A torn final line is skipped
+	f, err := h.FS.OpenFile(ledger.Path(), os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, _ = f.Write([]byte(`{"time":"2025-01`))
+	f.Close()
+
+	entries, err = ledger.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "index-update", entries[0].Feature)
+	assert.Equal(t, "auth-1", entries[1].Session)
+}
+
+func TestRecorder_RecordsUsage(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	ledger := New(h.FS, "/project/.claudex/usage.jsonl")
+	backend := &fakeBackend{resp: &llm.Response{
+		Text:  "ok",
+		Model: "claude-haiku-4-5",
+		Usage: llm.Usage{InputTokens: 100, OutputTokens: 20, CacheReadTokens: 500, CostUSD: 0.002, DurationMS: 1500},
+	}}
+
+	resp, err := NewRecorder(backend, ledger, h).Complete(llm.Request{
+		Model:   "haiku",
+		Feature: "session-overview",
+		Session: "auth-refactor-1234",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Text)
+
+	entries, err := ledger.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, Entry{
+		Time:            "2025-01-15T10:30:00Z",
+		Session:         "auth-refactor-1234",
+		Feature:         "session-overview",
+		Backend:         "fake",
+		Model:           "claude-haiku-4-5",
+		InputTokens:     100,
+		OutputTokens:    20,
+		CacheReadTokens: 500,
+		CostUSD:         0.002,
+		DurationMS:      1500,
+	}, entries[0])
+}
+
+func TestRecorder_RecordsFailures(t *testing.T) {
+	h := testutil.NewTestHarness()
+	ledger := New(h.FS, "/project/.claudex/usage.jsonl")
+	backend := &fakeBackend{err: fmt.Errorf("exit status 1")}
+
+	_, err := NewRecorder(backend, ledger, h).Complete(llm.Request{Model: "haiku", Feature: "index-update"})
+	require.Error(t, err)
+
+	entries, err := ledger.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "haiku", entries[0].Model, "falls back to the requested model")
+	assert.Equal(t, "exit status 1", entries[0].Error)
+}
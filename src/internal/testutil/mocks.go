@@ -10,6 +10,7 @@ type CommandInvocation struct {
 	Name  string
 	Args  []string
 	Stdin string
+	Dir   string
 }
 
 // MockCommander captures command invocations for verification
@@ -84,6 +85,11 @@ func (m *MockCommander) Run(name string, args ...string) ([]byte, error) {
 
 // Start executes an interactive command and captures the invocation
 func (m *MockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	return m.StartIn("", name, stdin, stdout, stderr, args...)
+}
+
+// StartIn executes an interactive command in dir and captures the invocation
+func (m *MockCommander) StartIn(dir, name string, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
 	stdinContent := ""
 	if stdin != nil {
 		data, _ := io.ReadAll(stdin)
@@ -94,6 +100,7 @@ func (m *MockCommander) Start(name string, stdin io.Reader, stdout, stderr io.Wr
 		Name:  name,
 		Args:  args,
 		Stdin: stdinContent,
+		Dir:   dir,
 	})
 
 	// Match against patterns and write output
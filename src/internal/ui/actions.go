@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/session"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var modalStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#FF5F5F")).
+	Padding(1, 3)
+
+// actionDoneMsg reports a finished session action. The list is reloaded with
+// selectName highlighted, or the cursor kept in place when it is empty.
+type actionDoneMsg struct {
+	status     string
+	selectName string
+	err        error
+}
+
+// actionTarget returns the highlighted session when session actions apply:
+// in the session stage, on an active or archived session, while not typing a
+// filter
+func (m Model) actionTarget() (SessionItem, bool) {
+	if m.Stage != "session" || m.List.FilterState() == list.Filtering {
+		return SessionItem{}, false
+	}
+	item, ok := m.List.SelectedItem().(SessionItem)
+	if !ok || (item.ItemType != "session" && item.ItemType != "archived") {
+		return SessionItem{}, false
+	}
+	return item, true
+}
+
+// startAction runs the session action bound to key. handled is false when
+// the key has no action for this item, so it goes on to the list.
+func (m Model) startAction(item SessionItem, key string) (model tea.Model, cmd tea.Cmd, handled bool) {
+	active := item.ItemType == "session"
+	switch key {
+	case "r":
+		if active && m.RenameSession != nil {
+			model, cmd = m.openInput("rename", item.Title, "New name: ", session.StripClaudeSessionID(item.Title))
+			return model, cmd, true
+		}
+
+	case "f":
+		if active && m.ForkSession != nil {
+			model, cmd = m.openInput("fork", item.Title, "Fork description: ", "")
+			return model, cmd, true
+		}
+
+	case "x":
+		if active && m.ArchiveSession != nil {
+			name, err := m.ArchiveSession(item.Title)
+			model, cmd = m.finishAction(actionDoneMsg{status: "✓ Archived " + name, err: err})
+			return model, cmd, true
+		}
+		if !active && m.UnarchiveSession != nil {
+			name, err := m.UnarchiveSession(item.Title)
+			model, cmd = m.finishAction(actionDoneMsg{status: "✓ Restored " + name, selectName: name, err: err})
+			return model, cmd, true
+		}
+
+	case "d":
+		if (active && m.RemoveSession != nil) || (!active && m.PurgeSession != nil) {
+			m.confirming = item
+			return m, nil, true
+		}
+
+	case "e":
+		if m.Editor != "" {
+			return m, m.openFolder(item), true
+		}
+	}
+	return m, nil, false
+}
+
+// openInput shows a text input below the list for an action on a session
+func (m Model) openInput(action, target, prompt, value string) (tea.Model, tea.Cmd) {
+	m.inputAction = action
+	m.inputTarget = target
+	m.input = textinput.New()
+	m.input.Prompt = prompt
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m, m.input.Focus()
+}
+
+// updateInput handles keys while a new name or fork description is typed:
+// enter runs the action, esc cancels
+func (m Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.Quitting = true
+		return m, tea.Quit
+
+	case "esc":
+		m.inputAction = ""
+		return m, nil
+
+	case "enter":
+		action, target, value := m.inputAction, m.inputTarget, m.input.Value()
+		m.inputAction = ""
+		switch action {
+		case "rename":
+			newName, err := m.RenameSession(target, value)
+			return m.finishAction(actionDoneMsg{status: "✓ Renamed to " + newName, selectName: newName, err: err})
+
+		case "fork":
+			// Naming the fork may call a model, so it runs outside Update
+			fork := m.ForkSession
+			m.status = "Forking " + target + "…"
+			return m, func() tea.Msg {
+				name, err := fork(target, value)
+				return actionDoneMsg{status: "✓ Forked to " + name, selectName: name, err: err}
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateConfirm handles keys while the delete confirmation is shown: y
+// deletes, n or esc cancels
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.Quitting = true
+		return m, tea.Quit
+
+	case "n", "N", "esc":
+		m.confirming = SessionItem{}
+		return m, nil
+
+	case "y", "Y":
+		item := m.confirming
+		m.confirming = SessionItem{}
+		if item.ItemType == "archived" {
+			name, err := m.PurgeSession(item.Title)
+			return m.finishAction(actionDoneMsg{status: "✓ Purged " + name, err: err})
+		}
+		name, err := m.RemoveSession(item.Title)
+		return m.finishAction(actionDoneMsg{status: "✓ Deleted " + name, err: err})
+	}
+	return m, nil
+}
+
+// confirmView renders the delete confirmation in the middle of the screen
+func (m Model) confirmView() string {
+	question := "Delete this session?"
+	if m.confirming.ItemType == "archived" {
+		question = "Purge this archived session?"
+	}
+	body := strings.Join([]string{
+		titleStyle.UnsetPadding().Render(question),
+		"",
+		m.confirming.Title,
+		previewLabelStyle.Render("Its folder and documents are removed for good."),
+		"",
+		"y delete • n cancel",
+	}, "\n")
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Render(body))
+}
+
+// openFolder suspends the TUI and opens a session folder with the editor
+func (m Model) openFolder(item SessionItem) tea.Cmd {
+	fields := strings.Fields(m.Editor)
+	c := exec.Command(fields[0], append(fields[1:], m.itemPath(item))...)
+	editor := m.Editor
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		if err != nil {
+			return actionDoneMsg{selectName: item.Title, err: fmt.Errorf("%s: %w", editor, err)}
+		}
+		return actionDoneMsg{selectName: item.Title}
+	})
+}
+
+// itemPath returns the folder of an active or archived session item
+func (m Model) itemPath(item SessionItem) string {
+	if item.ItemType == "archived" {
+		return filepath.Join(m.ArchiveDir, item.Title)
+	}
+	return filepath.Join(m.SessionsDir, item.Title)
+}
+
+// finishAction shows the outcome of a session action and reloads the list in
+// place, so the preview reflects the change too
+func (m Model) finishAction(msg actionDoneMsg) (tea.Model, tea.Cmd) {
+	m.status = msg.status
+	if msg.err != nil {
+		m.status = "✗ " + msg.err.Error()
+	}
+	if m.LoadItems == nil {
+		return m, nil
+	}
+
+	items, err := m.LoadItems(m.ShowArchived)
+	if err != nil {
+		m.status = "✗ " + err.Error()
+		return m, nil
+	}
+	cmd := m.List.SetItems(items)
+	m.previewFor = ""
+	if m.List.FilterState() != list.Unfiltered {
+		// The filter runs again and the list keeps its place in the results
+		return m, cmd
+	}
+
+	index := m.List.Index()
+	for i, item := range items {
+		if it, ok := item.(SessionItem); ok && msg.selectName != "" && it.Title == msg.selectName {
+			index = i
+		}
+	}
+	m.List.Select(max(0, min(index, len(items)-1)))
+	return m, cmd
+}
@@ -0,0 +1,268 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"claudex/internal/services/session"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minPreviewWidth is the terminal width below which the session list keeps
+// the whole screen and no preview pane is shown
+const minPreviewWidth = 100
+
+// maxPreviewDocs is the number of documents listed before "… and N more"
+const maxPreviewDocs = 8
+
+// Preview pane styles
+var (
+	previewStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(lipgloss.Color("#626262")).
+			PaddingLeft(2).
+			MarginLeft(1)
+
+	previewTitleStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#00D7FF")).
+				Bold(true)
+
+	previewLabelStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#626262"))
+
+	mdHeadingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#00FF87")).
+			Bold(true)
+
+	mdSubheadingStyle = lipgloss.NewStyle().Bold(true)
+
+	mdCodeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#D7AF5F"))
+
+	mdQuoteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#8A8A8A")).
+			Italic(true)
+)
+
+var (
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdListItem   = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdRule       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+)
+
+// previewKey identifies the item a preview was loaded for
+func previewKey(item SessionItem) string {
+	return item.ItemType + "/" + item.Title
+}
+
+// showsPreview reports whether the list shares the screen with the preview pane
+func (m Model) showsPreview() bool {
+	return m.Stage == "session" && m.LoadPreview != nil && m.width >= minPreviewWidth
+}
+
+// layout sizes the list, leaving the right part of the screen to the
+// preview pane when there is room for it
+func (m *Model) layout() {
+	if !m.showsPreview() {
+		m.List.SetSize(m.width, m.height)
+		return
+	}
+	m.List.SetSize(m.width*2/5, m.height)
+}
+
+// syncPreview loads the preview of the highlighted item when the highlight
+// moved since the last load
+func (m *Model) syncPreview() {
+	if !m.showsPreview() {
+		return
+	}
+	item, ok := m.List.SelectedItem().(SessionItem)
+	if !ok || previewKey(item) == m.previewFor {
+		return
+	}
+	m.previewFor = previewKey(item)
+	m.preview, m.previewErr = m.LoadPreview(item)
+}
+
+// previewView renders the preview pane of the highlighted item
+func (m Model) previewView() string {
+	width := m.width - m.List.Width() - previewStyle.GetHorizontalFrameSize()
+	item, ok := m.List.SelectedItem().(SessionItem)
+	if !ok || width <= 0 {
+		return ""
+	}
+
+	preview, err := m.preview, m.previewErr
+	if previewKey(item) != m.previewFor {
+		preview, err = m.LoadPreview(item)
+	}
+
+	content := renderPreview(item, preview, err, m.AutodocFrequency, width)
+	return previewStyle.
+		Height(m.height).
+		MaxHeight(m.height).
+		Render(lipgloss.NewStyle().MaxWidth(width).Render(content))
+}
+
+// renderPreview renders a session's last update, autodoc state, documents
+// and overview. Items without a session folder show their description.
+func renderPreview(item SessionItem, p *session.Preview, err error, autodocFrequency, width int) string {
+	var b strings.Builder
+	b.WriteString(previewTitleStyle.Render(item.Title) + "\n")
+
+	if err != nil {
+		b.WriteString("\n" + previewLabelStyle.Render("✗ "+err.Error()) + "\n")
+		return b.String()
+	}
+	if p == nil {
+		if item.Description != "" {
+			b.WriteString("\n" + previewLabelStyle.Render(item.Description) + "\n")
+		}
+		return b.String()
+	}
+
+	if p.Updated.IsZero() {
+		b.WriteString(previewLabelStyle.Render("No documents yet") + "\n")
+	} else {
+		b.WriteString(previewLabelStyle.Render("Updated ") + p.Updated.Local().Format("2006-01-02 15:04") + "\n")
+	}
+	autodoc := fmt.Sprintf("%d edits since the last overview update", p.Counters.DocUpdates)
+	if autodocFrequency > 0 {
+		autodoc = fmt.Sprintf("%d/%d edits until the next overview update", p.Counters.DocUpdates, autodocFrequency)
+	}
+	if p.Counters.LastProcessedLine > 0 {
+		autodoc += fmt.Sprintf(", transcript line %d", p.Counters.LastProcessedLine)
+	}
+	b.WriteString(previewLabelStyle.Render("Autodoc ") + autodoc + "\n")
+
+	if len(p.Docs) > 0 {
+		b.WriteString("\n" + mdSubheadingStyle.Render("Docs") + "\n")
+		pathWidth := 0
+		for _, doc := range p.Docs[:min(len(p.Docs), maxPreviewDocs)] {
+			pathWidth = max(pathWidth, lipgloss.Width(doc.Path))
+		}
+		for _, doc := range p.Docs[:min(len(p.Docs), maxPreviewDocs)] {
+			fmt.Fprintf(&b, "  %-*s  %s\n", pathWidth, doc.Path, previewLabelStyle.Render(formatSize(doc.Size)))
+		}
+		if more := len(p.Docs) - maxPreviewDocs; more > 0 {
+			b.WriteString(previewLabelStyle.Render(fmt.Sprintf("  … and %d more", more)) + "\n")
+		}
+	}
+
+	b.WriteString("\n" + strings.Repeat("─", width) + "\n")
+	if strings.TrimSpace(p.Overview) == "" {
+		b.WriteString(previewLabelStyle.Render("No session-overview.md yet") + "\n")
+	} else {
+		b.WriteString(renderMarkdown(p.Overview, width))
+	}
+	return b.String()
+}
+
+// formatSize returns a byte count in B, KB or MB
+func formatSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+// renderMarkdown styles the markdown the overview documenter writes
+// (headings, lists, task boxes, quotes, rules, code blocks, bold and inline
+// code) and wraps it to width. Anything else is shown as plain text.
+func renderMarkdown(text string, width int) string {
+	var b strings.Builder
+	inCode, blank := false, true
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(mdCodeStyle.Render("  "+line) + "\n")
+			blank = false
+			continue
+		}
+
+		if trimmed == "" {
+			// Collapse runs of blank lines
+			if !blank {
+				b.WriteString("\n")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			heading := strings.TrimSpace(trimmed[level:])
+			if level == 1 {
+				b.WriteString(wrap(mdHeadingStyle.Render(heading), width, "", "") + "\n")
+			} else {
+				b.WriteString(wrap(mdSubheadingStyle.Render(heading), width, "", "") + "\n")
+			}
+
+		case mdRule.MatchString(trimmed):
+			b.WriteString(previewLabelStyle.Render(strings.Repeat("─", width)) + "\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			b.WriteString(wrap(mdQuoteStyle.Render(inline(quote)), width, "│ ", "│ ") + "\n")
+
+		case mdListItem.MatchString(line):
+			parts := mdListItem.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", len(strings.ReplaceAll(parts[1], "\t", "  ")))
+			marker, rest := parts[2], parts[3]
+			switch {
+			case strings.HasPrefix(rest, "[ ] "):
+				marker, rest = "☐", rest[4:]
+			case strings.HasPrefix(rest, "[x] "), strings.HasPrefix(rest, "[X] "):
+				marker, rest = "☑", rest[4:]
+			case marker == "-" || marker == "*" || marker == "+":
+				marker = "•"
+			}
+			prefix := indent + marker + " "
+			b.WriteString(wrap(inline(rest), width, prefix, strings.Repeat(" ", lipgloss.Width(prefix))) + "\n")
+
+		default:
+			b.WriteString(wrap(inline(trimmed), width, "", "") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// inline styles **bold** and `code` spans
+func inline(text string) string {
+	text = mdInlineCode.ReplaceAllStringFunc(text, func(s string) string {
+		return mdCodeStyle.Render(s[1 : len(s)-1])
+	})
+	return mdBold.ReplaceAllStringFunc(text, func(s string) string {
+		return mdSubheadingStyle.Render(s[2 : len(s)-2])
+	})
+}
+
+// wrap word-wraps text to width, starting the first line with first and the
+// following lines with rest
+func wrap(text string, width int, first, rest string) string {
+	textWidth := max(width-lipgloss.Width(first), 10)
+	lines := strings.Split(lipgloss.NewStyle().Width(textWidth).Render(text), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+		if i == 0 {
+			lines[i] = first + lines[i]
+		} else {
+			lines[i] = rest + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
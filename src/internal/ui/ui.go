@@ -8,12 +8,12 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"claudex/internal/services/session"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -49,9 +49,47 @@ type Model struct {
 	SessionPath string
 	ProjectDir  string
 	SessionsDir string
+	ArchiveDir  string
 	Stage       string
 	Quitting    bool
 	Choice      string
+
+	// ShowArchived lists archived sessions after the active ones. The "a"
+	// key toggles it and reloads the list with LoadItems.
+	ShowArchived bool
+	LoadItems    func(showArchived bool) ([]list.Item, error)
+
+	// Session actions of the session stage (see actions.go). Each returns
+	// the name of the session it produced or acted on; a nil action has no
+	// key binding.
+	RenameSession    func(name, slug string) (string, error)        // "r", asks for the slug
+	ForkSession      func(name, description string) (string, error) // "f", asks for the description
+	ArchiveSession   func(name string) (string, error)              // "x" on an active session
+	UnarchiveSession func(name string) (string, error)              // "x" on an archived session
+	RemoveSession    func(name string) (string, error)              // "d" on an active session, after confirmation
+	PurgeSession     func(name string) (string, error)              // "d" on an archived session, after confirmation
+
+	// Editor is the command that "e" opens a session folder with
+	// ($VISUAL or $EDITOR); empty disables the key
+	Editor string
+
+	// LoadPreview reads what the preview pane shows of a session item; it
+	// returns nil for items without a session folder. The pane sits right
+	// of the session list when the terminal is wide enough.
+	LoadPreview func(item SessionItem) (*session.Preview, error)
+	// AutodocFrequency is the number of edits between overview updates
+	AutodocFrequency int
+
+	inputAction string // "rename" or "fork" while its input is shown
+	inputTarget string // Session the input applies to
+	input       textinput.Model
+	confirming  SessionItem // Session awaiting delete confirmation
+	status      string      // Outcome of the last action, shown below the list
+
+	width, height int              // Screen size inside docStyle
+	previewFor    string           // previewKey of the loaded preview
+	preview       *session.Preview // Preview of the highlighted item
+	previewErr    error
 }
 
 func (m Model) Init() tea.Cmd {
@@ -59,10 +97,20 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		nm.syncPreview()
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		m.List.SetSize(msg.Width-h, msg.Height-v)
+		m.width, m.height = msg.Width-h, msg.Height-v
+		m.layout()
 
 	case SessionChoiceMsg:
 		m.SessionName = msg.SessionName
@@ -82,8 +130,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Choice = msg.Choice
 		return m, tea.Quit
 
+	case actionDoneMsg:
+		return m.finishAction(msg)
+
 	case tea.KeyMsg:
+		m.status = ""
+		if m.inputAction != "" {
+			return m.updateInput(msg)
+		}
+		if m.confirming.Title != "" {
+			return m.updateConfirm(msg)
+		}
+		if item, ok := m.actionTarget(); ok {
+			if model, cmd, handled := m.startAction(item, msg.String()); handled {
+				return model, cmd
+			}
+		}
+
 		switch msg.String() {
+		case "a":
+			// While filtering the key goes to the filter input
+			if m.Stage == "session" && m.LoadItems != nil && m.List.FilterState() != list.Filtering {
+				items, err := m.LoadItems(!m.ShowArchived)
+				if err != nil {
+					m.status = "✗ " + err.Error()
+					return m, nil
+				}
+				m.ShowArchived = !m.ShowArchived
+				return m, m.List.SetItems(items)
+			}
+
 		case "ctrl+c", "q":
 			m.Quitting = true
 			return m, tea.Quit
@@ -107,11 +183,49 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.inputAction != "" {
+		// Keep the cursor blinking
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
 	var cmd tea.Cmd
 	m.List, cmd = m.List.Update(msg)
 	return m, cmd
 }
 
+// FilterSessions is the session list's filter (list.FilterFunc): fuzzy
+// matching on the session name, narrowed by tag:<name> and
+// status:active|archived qualifiers
+func FilterSessions(term string, targets []string) []list.Rank {
+	query := session.ParseQuery(term)
+
+	var names []string
+	var indexes []int
+	for i, target := range targets {
+		name, tags, status := session.ParseFilterValue(target)
+		if query.Matches(tags, status) {
+			names = append(names, name)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(query.Words) == 0 {
+		ranks := make([]list.Rank, len(indexes))
+		for i, index := range indexes {
+			ranks[i] = list.Rank{Index: index}
+		}
+		return ranks
+	}
+
+	ranks := list.DefaultFilter(query.Text(), names)
+	for i := range ranks {
+		ranks[i].Index = indexes[ranks[i].Index]
+	}
+	return ranks
+}
+
 type SessionChoiceMsg struct {
 	SessionName string
 	SessionPath string
@@ -132,9 +246,9 @@ func (m Model) handleSessionChoice(item SessionItem) tea.Cmd {
 			sessionName = "ephemeral"
 			sessionPath = ""
 
-		case "session":
+		case "session", "archived":
 			sessionName = item.Title
-			sessionPath = filepath.Join(m.SessionsDir, item.Title)
+			sessionPath = m.itemPath(item)
 		}
 
 		return SessionChoiceMsg{
@@ -180,7 +294,20 @@ func (m Model) View() string {
 		return "\n  👋 Goodbye!\n\n"
 	}
 
-	return docStyle.Render(m.List.View())
+	if m.confirming.Title != "" {
+		return docStyle.Render(m.confirmView())
+	}
+
+	view := m.List.View()
+	if m.showsPreview() {
+		view = lipgloss.JoinHorizontal(lipgloss.Top, view, m.previewView())
+	}
+	if m.inputAction != "" {
+		view += "\n" + m.input.View()
+	} else if m.status != "" {
+		view += "\n" + dimmedItemStyle.Render(m.status)
+	}
+	return docStyle.Render(view)
 }
 
 // Custom delegate for better item rendering
@@ -203,6 +330,8 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		icon = "⚡"
 	case "session":
 		icon = "📁"
+	case "archived":
+		icon = "🗄"
 	case "profile":
 		icon = "🎭"
 	case "continue":
@@ -237,7 +366,7 @@ func TitleStyle() lipgloss.Style {
 func PromptDescription(title string, originalSession string) (string, error) {
 	fmt.Print("\033[H\033[2J") // Clear screen
 	fmt.Println()
-	fmt.Printf("\033[1;36m %s \033[0m\n", title)
+	fmt.Println(sgr("1;36", " "+title+" "))
 	if originalSession != "" {
 		fmt.Printf("  Original: %s\n", originalSession)
 	}
@@ -263,28 +392,65 @@ func PromptDescription(title string, originalSession string) (string, error) {
 	return description, nil
 }
 
+// PromptTags asks for optional comma-separated tags after the description
+// Returns: the tags as typed, nil when none were given
+func PromptTags() ([]string, error) {
+	fmt.Print("  Tags (optional, comma-separated): ")
+
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return nil, err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return nil, nil
+	}
+	return strings.Split(line, ","), nil
+}
+
 // ShowGenerating displays "Generating session name..." message
 func ShowGenerating() {
 	fmt.Println()
-	fmt.Println("\033[90m  Generating session name...\033[0m")
+	fmt.Println(sgr("90", "  Generating session name..."))
 }
 
 // ShowSessionCreated displays success message for new session
 // Parameters: sessionName
 func ShowSessionCreated(sessionName string) {
 	fmt.Println()
-	fmt.Printf("\033[1;32m  Created: %s\033[0m\n", sessionName)
+	fmt.Println(sgr("1;32", "  Created: "+sessionName))
 	fmt.Println()
 }
 
 // ShowSessionForked displays success message for forked session
 // Parameters: originalName, newName
 func ShowSessionForked(originalName, newName string) {
-	fmt.Printf("\n\033[1;32m✅ Forked session: %s → %s\033[0m\n", originalName, newName)
+	fmt.Printf("\n%s\n", sgr("1;32", fmt.Sprintf("✅ Forked session: %s → %s", originalName, newName)))
 }
 
 // ShowFreshMemory displays success message for fresh memory
-// Parameters: originalName, newName
-func ShowFreshMemory(originalName, newName string) {
-	fmt.Printf("\n\033[1;32m🔄 Fresh memory: %s → %s (original deleted)\033[0m\n", originalName, newName)
+// Parameters: originalName, newName, archived (whether the original was
+// archived rather than deleted)
+func ShowFreshMemory(originalName, newName string, archived bool) {
+	what := "original deleted"
+	if archived {
+		what = "original archived"
+	}
+	fmt.Printf("\n%s\n", sgr("1;32", fmt.Sprintf("🔄 Fresh memory: %s → %s (%s)", originalName, newName, what)))
+}
+
+// ShowSessionRestored displays success message for a session brought back
+// from the archive
+func ShowSessionRestored(sessionName string) {
+	fmt.Printf("\n%s\n", sgr("1;32", "🗄  Restored from archive: "+sessionName))
+}
+
+// sgr wraps text in an ANSI style (e.g. "1;32" for bold green), or returns it
+// unstyled when NO_COLOR is set (https://no-color.org). lipgloss styles honor
+// NO_COLOR on their own.
+func sgr(code, text string) string {
+	if os.Getenv("NO_COLOR") != "" {
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
 }
@@ -5,31 +5,57 @@
 package createindex
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"claudex/internal/services/clock"
 	"claudex/internal/services/commander"
 	"claudex/internal/services/env"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/models"
+	"claudex/internal/services/usage"
 
 	"github.com/spf13/afero"
 )
 
 // CreateIndexUseCase orchestrates the index.md generation workflow
 type CreateIndexUseCase struct {
-	fs  afero.Fs
-	cmd commander.Commander
-	env env.Environment
+	fs      afero.Fs
+	cmd     commander.Commander
+	env     env.Environment
+	backend llm.Backend
+	queue   jobs.Service
 }
 
-// New creates a new CreateIndexUseCase instance with the given dependencies
-func New(fs afero.Fs, cmd commander.Commander, env env.Environment) *CreateIndexUseCase {
+// IndexJob is the payload of a create-index job
+type IndexJob struct {
+	Prompt     string `json:"prompt"`
+	OutputPath string `json:"outputPath"`
+	Model      string `json:"model"`
+}
+
+// New creates a new CreateIndexUseCase instance with the given dependencies.
+// The LLM backend is selected from the CLAUDEX_LLM_* environment.
+// Runs are recorded in queue so they show up in `claudex jobs`, and their
+// usage in the project's usage ledger; queue may be nil.
+func New(fs afero.Fs, cmd commander.Commander, env env.Environment, queue jobs.Service) *CreateIndexUseCase {
+	backend := llm.FromEnv(fs, cmd, env)
+	if queue != nil {
+		ledger := usage.New(fs, usage.PathForJobs(queue.Dir()))
+		backend = usage.NewRecorder(backend, ledger, clock.New())
+	}
+
 	return &CreateIndexUseCase{
-		fs:  fs,
-		cmd: cmd,
-		env: env,
+		fs:      fs,
+		cmd:     cmd,
+		env:     env,
+		backend: backend,
+		queue:   queue,
 	}
 }
 
@@ -82,7 +108,7 @@ func (uc *CreateIndexUseCase) Execute(dirPath string) error {
 	// 4. Build prompt
 	prompt := uc.buildPrompt(absPath, fileListing, styleReference)
 
-	// 5. Invoke Claude with haiku model - Claude will create the file directly
+	// 5. Invoke the LLM backend with haiku model - it creates the file
 	outputPath := filepath.Join(absPath, "index.md")
 	if err := uc.invokeClaudeSync(prompt, outputPath); err != nil {
 		return fmt.Errorf("failed to generate index.md: %w", err)
@@ -207,7 +233,8 @@ Requirements:
 - Do NOT output the content to stdout - write it to the file`, dirPath, fileListing, styleReference)
 }
 
-// invokeClaudeSync invokes Claude synchronously to create the index.md file directly
+// invokeClaudeSync invokes Claude synchronously to create the index.md file directly.
+// The run is recorded as a create-index job so it can be inspected and retried.
 func (uc *CreateIndexUseCase) invokeClaudeSync(prompt string, outputPath string) error {
 	// Recursion guard: check if we're already inside a hook invocation
 	if uc.env.Get("CLAUDE_HOOK_INTERNAL") == "1" {
@@ -215,17 +242,69 @@ func (uc *CreateIndexUseCase) invokeClaudeSync(prompt string, outputPath string)
 	}
 
 	// Add output path to prompt so Claude knows where to write
-	fullPrompt := fmt.Sprintf("%s\n\nWrite the index.md file to: %s", prompt, outputPath)
+	payload := IndexJob{
+		Prompt:     fmt.Sprintf("%s\n\nWrite the index.md file to: %s", prompt, outputPath),
+		OutputPath: outputPath,
+		Model:      models.FromEnv(uc.env).Model(models.TaskIndexCreate),
+	}
+
+	if uc.queue == nil {
+		return runBackend(uc.backend, payload, io.Discard)
+	}
+
+	job, err := uc.queue.Begin(jobs.Request{
+		Kind:    jobs.KindCreateIndex,
+		Target:  outputPath,
+		Model:   payload.Model,
+		Payload: payload,
+	})
+	if err != nil {
+		// Recording is best effort; still generate the index
+		return runBackend(uc.backend, payload, io.Discard)
+	}
 
-	// Create command with haiku model for cost efficiency
-	cmd := exec.Command("claude", "-p", fullPrompt, "--model", "haiku")
+	var stderr io.Writer = io.Discard
+	logFile, err := uc.fs.OpenFile(uc.queue.LogPath(job.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err == nil {
+		defer logFile.Close()
+		stderr = logFile
+	}
 
-	// Set recursion guard in environment for this command
-	cmd.Env = append(os.Environ(), "CLAUDE_HOOK_INTERNAL=1")
+	runErr := runBackend(uc.backend, payload, stderr)
+	uc.queue.Finish(job, runErr)
+	return runErr
+}
 
-	// Run the command - Claude will write the file directly using the Write tool
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("claude invocation failed: %w", err)
+// JobHandler returns the jobs.Handler that executes queued create-index jobs.
+// Used when a recorded run is retried through `claudex jobs retry`.
+func JobHandler(backend llm.Backend) jobs.Handler {
+	return func(job *jobs.Job, stderr io.Writer) error {
+		var payload IndexJob
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid job payload: %w", err)
+		}
+		return runBackend(backend, payload, stderr)
+	}
+}
+
+// runBackend runs a create-index payload through the LLM backend.
+// The Claude CLI writes the file directly using the Write tool; text-only
+// backends write the reply to the output path.
+func runBackend(backend llm.Backend, payload IndexJob, stderr io.Writer) error {
+	model := payload.Model
+	if model == "" {
+		model = models.Default(models.TaskIndexCreate)
+	}
+
+	_, err := backend.Complete(llm.Request{
+		Prompt:     payload.Prompt,
+		Model:      model,
+		OutputPath: payload.OutputPath,
+		Stderr:     stderr,
+		Feature:    string(jobs.KindCreateIndex),
+	})
+	if err != nil {
+		return fmt.Errorf("%s invocation failed: %w", backend.Name(), err)
 	}
 
 	return nil
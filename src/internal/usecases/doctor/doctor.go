@@ -0,0 +1,572 @@
+// Package doctor provides the usecase behind `claudex doctor`: installation
+// and project health checks that explain why hooks or documentation updates
+// silently do nothing, with a fix for every failed check and automatic
+// repairs for the safe ones.
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"claudex"
+	"claudex/internal/services/commander"
+	"claudex/internal/services/env"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/mcpconfig"
+	"claudex/internal/services/paths"
+	"claudex/internal/services/process"
+	"claudex/internal/services/settings"
+	setupuc "claudex/internal/usecases/setup"
+	setuphookuc "claudex/internal/usecases/setuphook"
+
+	"github.com/spf13/afero"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn" // Works, but something is degraded or missing
+	StatusFail Status = "fail" // Hooks or documentation updates will not work
+)
+
+const (
+	// docUpdateLock is the lock file the range updater keeps in a session folder
+	docUpdateLock = "doc_update.lock"
+
+	// promptTemplate is the documenter prompt the auto-doc hooks load
+	promptTemplate = "session-overview-documenter.md"
+
+	// hooksBinEnv overrides the hooks executable in the proxy scripts
+	hooksBinEnv = jobs.EnvHooksBin
+)
+
+// Check is the result of one diagnostic
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Detail  string `json:"detail"`
+	Fix     string `json:"fix,omitempty"`     // What to do about a warn or fail
+	Fixable bool   `json:"fixable,omitempty"` // Whether `claudex doctor --fix` repairs it
+
+	repair func() error
+}
+
+// UseCase runs the health checks for one project
+type UseCase struct {
+	fs         afero.Fs
+	cmd        commander.Commander
+	env        env.Environment
+	projectDir string
+	version    string
+
+	claudeConfigPath string
+	lookPath         func(file string) (string, error)
+	alive            func(pid int) bool
+	hook             *setuphookuc.UseCase
+	setup            func() error
+}
+
+// New creates a new Doctor usecase. version is the running claudex version,
+// which the claudex-hooks binary is expected to match.
+func New(afs afero.Fs, cmdr commander.Commander, environment env.Environment, projectDir, version string) *UseCase {
+	claudeConfigPath, _ := mcpconfig.New(afs).GetConfigPath()
+	return &UseCase{
+		fs:               afs,
+		cmd:              cmdr,
+		env:              environment,
+		projectDir:       projectDir,
+		version:          version,
+		claudeConfigPath: claudeConfigPath,
+		lookPath:         exec.LookPath,
+		alive:            process.Alive,
+		hook:             setuphookuc.New(afs, projectDir, cmdr),
+		setup: func() error {
+			return setupuc.New(afs, environment).Execute(projectDir, true)
+		},
+	}
+}
+
+// Run performs every check, in the order they are reported
+func (uc *UseCase) Run() []Check {
+	return []Check{
+		uc.checkClaude(),
+		uc.checkHooksBinary(),
+		uc.checkHooksVersion(),
+		uc.checkHookCommands(),
+		uc.checkProxies(),
+		uc.checkLocks(),
+		uc.checkPromptTemplate(),
+		uc.checkClaudeConfig(),
+		uc.checkPostCommitHook(),
+	}
+}
+
+// Repair applies the automatic fix of a fixable check
+func (uc *UseCase) Repair(c Check) error {
+	if c.repair == nil {
+		return fmt.Errorf("%s cannot be fixed automatically", c.Name)
+	}
+	return c.repair()
+}
+
+// Failed counts the checks with StatusFail
+func Failed(checks []Check) int {
+	n := 0
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
+
+// Write prints the checks with the fix for each problem
+func Write(w io.Writer, checks []Check) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	problems, fixable := 0, 0
+	for _, c := range checks {
+		fmt.Fprintf(tw, "%s %s\t%s\n", icon(c.Status), c.Name, c.Detail)
+		if c.Status == StatusOK {
+			continue
+		}
+		problems++
+		if c.Fixable {
+			fixable++
+		}
+		if c.Fix != "" {
+			fmt.Fprintf(tw, "\t→ %s\n", c.Fix)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case problems == 0:
+		fmt.Fprintln(w, "All checks passed.")
+	case fixable > 0:
+		fmt.Fprintf(w, "%d problem(s) found; %d can be repaired with: claudex doctor --fix\n", problems, fixable)
+	default:
+		fmt.Fprintf(w, "%d problem(s) found.\n", problems)
+	}
+	return nil
+}
+
+// WriteJSON prints the checks as an indented JSON array
+func WriteJSON(w io.Writer, checks []Check) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(checks)
+}
+
+func icon(s Status) string {
+	switch s {
+	case StatusOK:
+		return "✓"
+	case StatusWarn:
+		return "⚠"
+	default:
+		return "✗"
+	}
+}
+
+// checkClaude verifies the Claude Code CLI is on PATH
+func (uc *UseCase) checkClaude() Check {
+	c := Check{Name: "claude"}
+	if _, err := uc.lookPath("claude"); err != nil {
+		c.Status = StatusFail
+		c.Detail = "Claude Code CLI not found on PATH"
+		c.Fix = "Install it with: npm install -g @anthropic-ai/claude-code"
+		return c
+	}
+
+	out, err := uc.cmd.Run("claude", "--version")
+	if err != nil {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("claude --version failed: %v", err)
+		c.Fix = "Reinstall it with: npm install -g @anthropic-ai/claude-code"
+		return c
+	}
+	c.Status = StatusOK
+	c.Detail = firstLine(string(out))
+	return c
+}
+
+// hooksBinary resolves the hooks executable the way the proxy scripts do and
+// reports which variable, if any, chose it
+func (uc *UseCase) hooksBinary() (bin, variable string) {
+	return jobs.LookupHooksBinary(uc.env, runtime.GOOS)
+}
+
+// checkHooksBinary verifies the hook proxies can find the hooks executable
+func (uc *UseCase) checkHooksBinary() Check {
+	c := Check{Name: "hooks binary"}
+	bin, variable := uc.hooksBinary()
+	path, err := uc.lookPath(bin)
+	if err != nil {
+		c.Status = StatusFail
+		if variable != "" {
+			c.Detail = fmt.Sprintf("%s=%s is not an executable", variable, bin)
+			c.Fix = fmt.Sprintf("Point %s at the claudex-hooks executable, or unset it to use %s from PATH", variable, filepath.Base(bin))
+		} else {
+			c.Detail = fmt.Sprintf("%s not found on PATH; every hook fails", bin)
+			c.Fix = fmt.Sprintf("Install it with `make install-hooks` (build.bat install-hooks on Windows) and add its folder to PATH, or set %s", hooksBinEnv)
+		}
+		return c
+	}
+
+	c.Status = StatusOK
+	c.Detail = path
+	if variable != "" {
+		c.Detail = fmt.Sprintf("%s (from %s)", path, variable)
+	}
+	return c
+}
+
+// checkHooksVersion verifies claudex-hooks comes from the same build as claudex
+func (uc *UseCase) checkHooksVersion() Check {
+	c := Check{Name: "hooks version"}
+	bin, _ := uc.hooksBinary()
+	path, err := uc.lookPath(bin)
+	if err != nil {
+		c.Status = StatusWarn
+		c.Detail = "skipped: hooks binary not found"
+		return c
+	}
+
+	out, err := uc.cmd.Run(path, "version")
+	if err != nil {
+		c.Status = StatusWarn
+		c.Detail = "claudex-hooks does not report its version (built before `claudex-hooks version`)"
+		c.Fix = "Reinstall it from the same release as claudex: make install"
+		return c
+	}
+
+	fields := strings.Fields(string(out))
+	hooksVersion := ""
+	if len(fields) > 0 {
+		hooksVersion = fields[len(fields)-1]
+	}
+
+	switch {
+	case hooksVersion == uc.version:
+		c.Status = StatusOK
+		c.Detail = fmt.Sprintf("%s, matches claudex", hooksVersion)
+	case hooksVersion == "dev" || uc.version == "dev":
+		c.Status = StatusOK
+		c.Detail = fmt.Sprintf("claudex %s, claudex-hooks %s (development build, not compared)", uc.version, hooksVersion)
+	default:
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("claudex-hooks %s does not match claudex %s", hooksVersion, uc.version)
+		c.Fix = "Reinstall both from the same release: make install (or npm install -g @claudex/cli)"
+	}
+	return c
+}
+
+// checkHookCommands verifies every hook command in settings.local.json exists
+// and is executable
+func (uc *UseCase) checkHookCommands() Check {
+	c := Check{Name: "hook commands"}
+	settingsPath := filepath.Join(uc.projectDir, ".claude", "settings.local.json")
+
+	data, err := afero.ReadFile(uc.fs, settingsPath)
+	if err != nil {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("%s not found; no hooks are registered with Claude", settingsPath)
+		c.Fix = "Reinstall the project's .claude setup"
+		c.Fixable = true
+		c.repair = uc.setup
+		return c
+	}
+
+	var s settings.Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("%s is not valid JSON: %v", settingsPath, err)
+		c.Fix = "Fix the JSON syntax, or delete the file and run: claudex doctor --fix"
+		return c
+	}
+
+	var missing, notExecutable []string
+	total := 0
+	for _, entries := range s.Hooks {
+		for _, entry := range entries {
+			for _, hook := range entry.Hooks {
+				path := uc.commandPath(hook.Command)
+				if path == "" {
+					continue
+				}
+				total++
+				info, err := uc.fs.Stat(path)
+				switch {
+				case err != nil:
+					missing = append(missing, path)
+				case runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0:
+					notExecutable = append(notExecutable, path)
+				}
+			}
+		}
+	}
+
+	if len(missing) == 0 && len(notExecutable) == 0 {
+		c.Status = StatusOK
+		c.Detail = fmt.Sprintf("%d hook command(s) found and executable", total)
+		return c
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing: "+strings.Join(missing, ", "))
+	}
+	if len(notExecutable) > 0 {
+		problems = append(problems, "not executable: "+strings.Join(notExecutable, ", "))
+	}
+	c.Status = StatusFail
+	c.Detail = strings.Join(problems, "; ")
+	c.Fix = "Reinstall the missing hook scripts and mark them executable (chmod +x)"
+	c.Fixable = true
+	c.repair = func() error {
+		if len(missing) > 0 {
+			if err := uc.setup(); err != nil {
+				return err
+			}
+		}
+		for _, path := range notExecutable {
+			if err := uc.fs.Chmod(path, 0755); err != nil {
+				return fmt.Errorf("failed to make %s executable: %w", path, err)
+			}
+		}
+		return nil
+	}
+	return c
+}
+
+// commandPath returns the executable of a hook command, resolved against the
+// project directory the way Claude runs it
+func (uc *UseCase) commandPath(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	path := strings.Trim(fields[0], `"'`)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(uc.projectDir, path)
+	}
+	return path
+}
+
+// checkProxies verifies the installed hook proxies honor CLAUDEX_HOOKS_BIN,
+// which older proxies with a hard-coded binary path don't
+func (uc *UseCase) checkProxies() Check {
+	c := Check{Name: "hook proxies"}
+	hooksDir := filepath.Join(uc.projectDir, ".claude", "hooks")
+
+	entries, err := afero.ReadDir(uc.fs, hooksDir)
+	if err != nil {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("%s not found", hooksDir)
+		c.Fix = "Reinstall the project's .claude setup"
+		c.Fixable = true
+		c.repair = uc.setup
+		return c
+	}
+
+	var proxies, outdated []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isProxy(entry.Name()) {
+			continue
+		}
+		proxies = append(proxies, entry.Name())
+		data, err := afero.ReadFile(uc.fs, filepath.Join(hooksDir, entry.Name()))
+		if err != nil || !strings.Contains(string(data), hooksBinEnv) {
+			outdated = append(outdated, entry.Name())
+		}
+	}
+
+	switch {
+	case len(proxies) == 0:
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("no hook proxies in %s", hooksDir)
+		c.Fix = "Reinstall the project's .claude setup"
+		c.Fixable = true
+		c.repair = uc.setup
+	case len(outdated) > 0:
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("%s ignore %s", strings.Join(outdated, ", "), hooksBinEnv)
+		c.Fix = "Replace them with the current proxies"
+		c.Fixable = true
+		c.repair = func() error { return uc.replaceProxies(hooksDir, outdated) }
+	default:
+		c.Status = StatusOK
+		bin, _ := uc.hooksBinary()
+		c.Detail = fmt.Sprintf("%d proxies resolve %s (currently %s)", len(proxies), hooksBinEnv, bin)
+	}
+	return c
+}
+
+// replaceProxies overwrites outdated proxies with the embedded ones
+func (uc *UseCase) replaceProxies(hooksDir string, names []string) error {
+	for _, name := range names {
+		content, err := fs.ReadFile(claudex.Hooks, "scripts/proxies/"+name)
+		if err != nil {
+			return fmt.Errorf("no current version of %s to install: %w", name, err)
+		}
+		if err := afero.WriteFile(uc.fs, filepath.Join(hooksDir, name), content, 0755); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func isProxy(name string) bool {
+	switch filepath.Ext(name) {
+	case ".sh", ".bat", ".ps1":
+		return true
+	}
+	return false
+}
+
+// checkLocks looks for documentation update locks left behind by a process
+// that died, which block every later update of that session
+func (uc *UseCase) checkLocks() Check {
+	c := Check{Name: "doc update locks"}
+	sessionsDir := filepath.Join(uc.projectDir, paths.SessionsDir)
+
+	entries, err := afero.ReadDir(uc.fs, sessionsDir)
+	if err != nil && !os.IsNotExist(err) {
+		c.Status = StatusWarn
+		c.Detail = fmt.Sprintf("could not read %s: %v", sessionsDir, err)
+		return c
+	}
+
+	var stale []string
+	held := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		lockPath := filepath.Join(sessionsDir, entry.Name(), docUpdateLock)
+		data, err := afero.ReadFile(uc.fs, lockPath)
+		if err != nil {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err == nil && uc.alive(pid) {
+			held++
+			continue
+		}
+		stale = append(stale, lockPath)
+	}
+
+	if len(stale) == 0 {
+		c.Status = StatusOK
+		c.Detail = "no stale locks"
+		if held > 0 {
+			c.Detail = fmt.Sprintf("no stale locks (%d update(s) running)", held)
+		}
+		return c
+	}
+
+	c.Status = StatusFail
+	c.Detail = fmt.Sprintf("%d stale lock(s) block documentation updates: %s", len(stale), strings.Join(stale, ", "))
+	c.Fix = "Remove the locks; the process that held them is gone"
+	c.Fixable = true
+	c.repair = func() error {
+		for _, path := range stale {
+			if err := uc.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+	return c
+}
+
+// checkPromptTemplate verifies the documenter prompt the auto-doc hooks load
+func (uc *UseCase) checkPromptTemplate() Check {
+	c := Check{Name: "prompt template"}
+	templatePath := filepath.Join(uc.projectDir, ".claude", "hooks", "prompts", promptTemplate)
+
+	if _, err := uc.fs.Stat(templatePath); err != nil {
+		c.Status = StatusWarn
+		c.Detail = fmt.Sprintf("%s not found; session overviews are not updated automatically", templatePath)
+		c.Fix = fmt.Sprintf("Create %s with the instructions for the session documenter", templatePath)
+		return c
+	}
+	c.Status = StatusOK
+	c.Detail = templatePath
+	return c
+}
+
+// checkClaudeConfig verifies ~/.claude.json parses, since both Claude and the
+// MCP setup read it
+func (uc *UseCase) checkClaudeConfig() Check {
+	c := Check{Name: "~/.claude.json"}
+	if uc.claudeConfigPath == "" {
+		c.Status = StatusWarn
+		c.Detail = "home directory not found"
+		return c
+	}
+
+	data, err := afero.ReadFile(uc.fs, uc.claudeConfigPath)
+	if err != nil {
+		c.Status = StatusWarn
+		c.Detail = fmt.Sprintf("%s not found", uc.claudeConfigPath)
+		c.Fix = "Start claude once to create it"
+		return c
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("%s is not valid JSON: %v", uc.claudeConfigPath, err)
+		c.Fix = fmt.Sprintf("Fix the JSON syntax in %s (keep a backup; it holds your Claude settings and MCP servers)", uc.claudeConfigPath)
+		return c
+	}
+	c.Status = StatusOK
+	c.Detail = "valid JSON"
+	return c
+}
+
+// checkPostCommitHook verifies the git hook that updates index.md files
+func (uc *UseCase) checkPostCommitHook() Check {
+	c := Check{Name: "post-commit hook"}
+	switch uc.hook.ShouldPrompt() {
+	case setuphookuc.ResultNotGitRepo:
+		c.Status = StatusOK
+		c.Detail = "not a git repository"
+	case setuphookuc.ResultAlreadyInstalled:
+		c.Status = StatusOK
+		c.Detail = "installed"
+	case setuphookuc.ResultUserDeclined:
+		c.Status = StatusOK
+		c.Detail = "declined; index.md files are not updated after commits"
+	default:
+		c.Status = StatusWarn
+		c.Detail = "not installed; index.md files are not updated after commits"
+		c.Fix = "Install the post-commit hook (appends to an existing hook)"
+		c.Fixable = true
+		c.repair = uc.hook.Install
+	}
+	return c
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,283 @@
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const projectDir = "/project"
+
+const settingsJSON = `{
+  "hooks": {
+    "PostToolUse": [
+      {"hooks": [{"type": "command", "command": "/project/.claude/hooks/post-tool-use.sh"}]}
+    ],
+    "SessionEnd": [
+      {"hooks": [{"type": "command", "command": ".claude/hooks/session-end.sh"}]}
+    ]
+  }
+}`
+
+const proxy = "#!/bin/bash\nHOOKS_BIN=\"${CLAUDEX_HOOKS_BIN:-claudex-hooks}\"\nexec \"$HOOKS_BIN\" post-tool-use\n"
+
+// newTestUseCase sets up a healthy project: binaries on PATH with matching
+// versions, executable hooks, the prompt template, a valid ~/.claude.json and
+// the post-commit hook
+func newTestUseCase(t *testing.T) (*UseCase, *testutil.TestHarness) {
+	t.Helper()
+	h := testutil.NewTestHarness()
+	h.WriteFile(filepath.Join(projectDir, ".claude", "settings.local.json"), settingsJSON)
+	writeExecutable(t, h, filepath.Join(projectDir, ".claude", "hooks", "post-tool-use.sh"), proxy)
+	writeExecutable(t, h, filepath.Join(projectDir, ".claude", "hooks", "session-end.sh"), proxy)
+	h.WriteFile(filepath.Join(projectDir, ".claude", "hooks", "prompts", promptTemplate), "Document the session")
+	h.WriteFile("/home/user/.claude.json", `{"mcpServers": {}}`)
+	h.WriteFile(filepath.Join(projectDir, ".git", "hooks", "post-commit"), "#!/bin/sh\n# claudex-docs-hook\nclaudex --update-docs &\n")
+
+	h.Commander.OnPattern("claude", "--version").Return([]byte("2.0.14 (Claude Code)\n"), nil)
+	h.Commander.OnPattern("/usr/local/bin/claudex-hooks", "version").Return([]byte("claudex-hooks v1.2.0\n"), nil)
+
+	uc := New(h.FS, h.Commander, h.Env, projectDir, "v1.2.0")
+	uc.claudeConfigPath = "/home/user/.claude.json"
+	uc.lookPath = func(file string) (string, error) {
+		switch file {
+		case "claude", "claudex-hooks":
+			return "/usr/local/bin/" + file, nil
+		}
+		return "", errors.New("executable file not found in $PATH")
+	}
+	uc.alive = func(pid int) bool { return pid == 4242 }
+	uc.setup = func() error { return errors.New("setup should not run") }
+	return uc, h
+}
+
+func writeExecutable(t *testing.T, h *testutil.TestHarness, path, content string) {
+	t.Helper()
+	require.NoError(t, h.FS.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, afero.WriteFile(h.FS, path, []byte(content), 0755))
+}
+
+func findCheck(t *testing.T, checks []Check, name string) Check {
+	t.Helper()
+	for _, c := range checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %q not reported", name)
+	return Check{}
+}
+
+func TestRun_HealthyProject(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+
+	checks := uc.Run()
+
+	for _, c := range checks {
+		assert.Equal(t, StatusOK, c.Status, "%s: %s", c.Name, c.Detail)
+	}
+	assert.Equal(t, 0, Failed(checks))
+	assert.Equal(t, "2.0.14 (Claude Code)", findCheck(t, checks, "claude").Detail)
+}
+
+func TestRun_ClaudeMissing(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+	uc.lookPath = func(file string) (string, error) {
+		if file == "claudex-hooks" {
+			return "/usr/local/bin/claudex-hooks", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	c := findCheck(t, uc.Run(), "claude")
+	assert.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Fix, "npm install -g @anthropic-ai/claude-code")
+	assert.False(t, c.Fixable)
+}
+
+func TestRun_HooksBinaryFromEnvMissing(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	h.Env.Set("CLAUDEX_HOOKS_BIN", "/opt/old/claudex-hooks")
+
+	checks := uc.Run()
+
+	c := findCheck(t, checks, "hooks binary")
+	assert.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "CLAUDEX_HOOKS_BIN=/opt/old/claudex-hooks")
+	assert.Equal(t, StatusWarn, findCheck(t, checks, "hooks version").Status)
+}
+
+func TestRun_HooksVersionMismatch(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+	uc.version = "v1.3.0"
+
+	c := findCheck(t, uc.Run(), "hooks version")
+	assert.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "claudex-hooks v1.2.0 does not match claudex v1.3.0")
+}
+
+func TestRun_HooksVersionDevBuildNotCompared(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+	uc.version = "dev"
+
+	c := findCheck(t, uc.Run(), "hooks version")
+	assert.Equal(t, StatusOK, c.Status)
+}
+
+func TestRun_HooksWithoutVersionCommand(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	h.Commander = testutil.NewMockCommander()
+	h.Commander.OnPattern("/usr/local/bin/claudex-hooks", "version").Return([]byte("Unknown command: version\n"), errors.New("exit status 1"))
+	uc.cmd = h.Commander
+
+	c := findCheck(t, uc.Run(), "hooks version")
+	assert.Equal(t, StatusWarn, c.Status)
+	assert.NotEmpty(t, c.Fix)
+}
+
+func TestRun_HookCommandNotExecutable_FixChmods(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	scriptPath := filepath.Join(projectDir, ".claude", "hooks", "session-end.sh")
+	require.NoError(t, h.FS.Chmod(scriptPath, 0644))
+
+	c := findCheck(t, uc.Run(), "hook commands")
+	require.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "not executable: "+scriptPath)
+	require.True(t, c.Fixable)
+
+	require.NoError(t, uc.Repair(c))
+
+	info, err := h.FS.Stat(scriptPath)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0111)
+	assert.Equal(t, StatusOK, findCheck(t, uc.Run(), "hook commands").Status)
+}
+
+func TestRun_HookCommandMissing_FixReinstalls(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	scriptPath := filepath.Join(projectDir, ".claude", "hooks", "session-end.sh")
+	require.NoError(t, h.FS.Remove(scriptPath))
+	setupRan := false
+	uc.setup = func() error {
+		setupRan = true
+		return nil
+	}
+
+	c := findCheck(t, uc.Run(), "hook commands")
+	require.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "missing: "+scriptPath)
+
+	require.NoError(t, uc.Repair(c))
+	assert.True(t, setupRan)
+}
+
+func TestRun_OutdatedProxy_FixReplacesIt(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	scriptPath := filepath.Join(projectDir, ".claude", "hooks", "post-tool-use.sh")
+	writeExecutable(t, h, scriptPath, "#!/bin/bash\nexec /usr/local/bin/claudex-hooks post-tool-use\n")
+
+	c := findCheck(t, uc.Run(), "hook proxies")
+	require.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "post-tool-use.sh")
+	require.True(t, c.Fixable)
+
+	require.NoError(t, uc.Repair(c))
+
+	testutil.AssertFileContains(t, h.FS, scriptPath, "CLAUDEX_HOOKS_BIN")
+	assert.Equal(t, StatusOK, findCheck(t, uc.Run(), "hook proxies").Status)
+}
+
+func TestRun_StaleLock_FixRemovesIt(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	sessionsDir := filepath.Join(projectDir, ".claudex", "sessions")
+	staleLock := filepath.Join(sessionsDir, "auth-refactor", docUpdateLock)
+	heldLock := filepath.Join(sessionsDir, "billing-ui", docUpdateLock)
+	h.WriteFile(staleLock, "999\n")
+	h.WriteFile(heldLock, "4242\n")
+
+	c := findCheck(t, uc.Run(), "doc update locks")
+	require.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, staleLock)
+	assert.NotContains(t, c.Detail, heldLock)
+
+	require.NoError(t, uc.Repair(c))
+
+	testutil.AssertNoFileExists(t, h.FS, staleLock)
+	testutil.AssertFileExists(t, h.FS, heldLock)
+	assert.Equal(t, StatusOK, findCheck(t, uc.Run(), "doc update locks").Status)
+}
+
+func TestRun_MissingPromptTemplateIsNotFixable(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	require.NoError(t, h.FS.Remove(filepath.Join(projectDir, ".claude", "hooks", "prompts", promptTemplate)))
+
+	c := findCheck(t, uc.Run(), "prompt template")
+	assert.Equal(t, StatusWarn, c.Status)
+	assert.Contains(t, c.Fix, promptTemplate)
+	assert.False(t, c.Fixable)
+	assert.Error(t, uc.Repair(c))
+}
+
+func TestRun_InvalidClaudeConfig(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	h.WriteFile("/home/user/.claude.json", `{"mcpServers": {`)
+
+	c := findCheck(t, uc.Run(), "~/.claude.json")
+	assert.Equal(t, StatusFail, c.Status)
+	assert.Contains(t, c.Detail, "not valid JSON")
+	assert.False(t, c.Fixable)
+}
+
+func TestRun_PostCommitHookMissing_FixInstalls(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	hookPath := filepath.Join(projectDir, ".git", "hooks", "post-commit")
+	h.WriteFile(hookPath, "#!/bin/sh\nmake lint\n")
+
+	c := findCheck(t, uc.Run(), "post-commit hook")
+	require.Equal(t, StatusWarn, c.Status)
+	require.True(t, c.Fixable)
+
+	require.NoError(t, uc.Repair(c))
+
+	testutil.AssertFileContains(t, h.FS, hookPath, "make lint")
+	testutil.AssertFileContains(t, h.FS, hookPath, "# claudex-docs-hook")
+}
+
+func TestWrite_ListsFixesAndSummary(t *testing.T) {
+	checks := []Check{
+		{Name: "claude", Status: StatusOK, Detail: "2.0.14"},
+		{Name: "doc update locks", Status: StatusFail, Detail: "1 stale lock(s)", Fix: "Remove the locks", Fixable: true},
+		{Name: "prompt template", Status: StatusWarn, Detail: "not found", Fix: "Create it"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, checks))
+
+	out := buf.String()
+	assert.Contains(t, out, "✓ claude")
+	assert.Contains(t, out, "✗ doc update locks")
+	assert.Contains(t, out, "→ Remove the locks")
+	assert.Contains(t, out, "⚠ prompt template")
+	assert.Contains(t, out, "2 problem(s) found; 1 can be repaired with: claudex doctor --fix")
+}
+
+func TestWriteJSON(t *testing.T) {
+	checks := []Check{{Name: "claude", Status: StatusFail, Detail: "not found", Fix: "Install it"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, checks))
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 1)
+	assert.Equal(t, "fail", decoded[0]["status"])
+	assert.Equal(t, "Install it", decoded[0]["fix"])
+}
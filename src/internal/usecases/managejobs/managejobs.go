@@ -0,0 +1,376 @@
+// Package managejobs provides the usecase behind `claudex jobs`: listing,
+// inspecting, tailing, cancelling and retrying background Claude invocations
+// recorded in the project's job registry (.claudex/jobs/).
+package managejobs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/process"
+
+	"github.com/spf13/afero"
+)
+
+// followInterval is how often `jobs logs --follow` polls for new output
+const followInterval = 500 * time.Millisecond
+
+// UseCase inspects and controls jobs in a single job registry
+type UseCase struct {
+	fs      afero.Fs
+	queue   jobs.Service
+	spawner jobs.Spawner
+	clock   clock.Clock
+	kill    func(pid int) error
+	alive   func(pid int) bool
+	sleep   func(d time.Duration)
+}
+
+// New creates a new ManageJobs usecase
+func New(fs afero.Fs, queue jobs.Service, spawner jobs.Spawner, clk clock.Clock) *UseCase {
+	return &UseCase{
+		fs:      fs,
+		queue:   queue,
+		spawner: spawner,
+		clock:   clk,
+		kill:    process.Kill,
+		alive:   process.Alive,
+		sleep:   time.Sleep,
+	}
+}
+
+// Find resolves a job by its full ID or a unique prefix of either the ID or
+// its random suffix (e.g. "3f2a" for 20250115-103000-3f2a9c1e)
+func (uc *UseCase) Find(id string) (*jobs.Job, error) {
+	if id == "" {
+		return nil, fmt.Errorf("job ID is required")
+	}
+
+	all, err := uc.queue.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	var matches []*jobs.Job
+	for _, job := range all {
+		if job.ID == id {
+			return job, nil
+		}
+		suffix := job.ID[strings.LastIndex(job.ID, "-")+1:]
+		if strings.HasPrefix(job.ID, id) || strings.HasPrefix(suffix, id) {
+			matches = append(matches, job)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("job not found: %s", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("job ID %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+// List writes a table of the most recent jobs, newest first.
+// A limit of 0 lists every job.
+func (uc *UseCase) List(w io.Writer, limit int) error {
+	all, err := uc.queue.List()
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	if len(all) == 0 {
+		fmt.Fprintln(w, "No background jobs recorded.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tKIND\tSTATE\tEXIT\tMODEL\tSTARTED\tDURATION\tTARGET")
+	shown := 0
+	for i := len(all) - 1; i >= 0; i-- {
+		if limit > 0 && shown == limit {
+			break
+		}
+		job := all[i]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			job.ID,
+			job.Kind,
+			uc.displayState(job),
+			exitStatus(job),
+			orDash(job.Model),
+			orDash(formatTime(job.StartedAt)),
+			orDash(uc.duration(job)),
+			orDash(job.Target),
+		)
+		shown++
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if hidden := len(all) - shown; hidden > 0 {
+		fmt.Fprintf(w, "\n%d older job(s) not shown; use --all to list everything\n", hidden)
+	}
+	return nil
+}
+
+// Show writes the full record of a job
+func (uc *UseCase) Show(w io.Writer, id string) error {
+	job, err := uc.Find(id)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", job.ID)
+	fmt.Fprintf(tw, "Kind:\t%s\n", job.Kind)
+	fmt.Fprintf(tw, "State:\t%s\n", uc.displayState(job))
+	fmt.Fprintf(tw, "Target:\t%s\n", orDash(job.Target))
+	fmt.Fprintf(tw, "Model:\t%s\n", orDash(job.Model))
+	fmt.Fprintf(tw, "PID:\t%s\n", orDash(pidString(job.PID)))
+	fmt.Fprintf(tw, "Attempts:\t%d/%d\n", job.Attempts, job.MaxAttempts)
+	fmt.Fprintf(tw, "Exit status:\t%s\n", exitStatus(job))
+	fmt.Fprintf(tw, "Created:\t%s\n", orDash(formatTime(job.CreatedAt)))
+	fmt.Fprintf(tw, "Started:\t%s\n", orDash(formatTime(job.StartedAt)))
+	fmt.Fprintf(tw, "Finished:\t%s\n", orDash(formatTime(job.FinishedAt)))
+	if job.NotBefore != "" {
+		fmt.Fprintf(tw, "Next attempt:\t%s\n", formatTime(job.NotBefore))
+	}
+	if job.Error != "" {
+		fmt.Fprintf(tw, "Error:\t%s\n", job.Error)
+	}
+	fmt.Fprintf(tw, "Log:\t%s\n", uc.queue.LogPath(job.ID))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if job.Stderr != "" {
+		fmt.Fprintf(w, "\nLast output:\n%s\n", strings.TrimRight(job.Stderr, "\n"))
+	}
+	return nil
+}
+
+// Logs writes a job's log file. With follow, it keeps streaming new output
+// until the job reaches a terminal state.
+func (uc *UseCase) Logs(w io.Writer, id string, follow bool) error {
+	job, err := uc.Find(id)
+	if err != nil {
+		return err
+	}
+
+	logPath := uc.queue.LogPath(job.ID)
+	var offset int64
+	for {
+		n, err := uc.copyFrom(w, logPath, offset)
+		if err != nil {
+			return err
+		}
+		offset += n
+
+		if !follow {
+			return nil
+		}
+
+		job, err = uc.queue.Get(job.ID)
+		if err != nil {
+			return err
+		}
+		if job.State.Finished() || (job.State == jobs.StateRunning && !uc.alive(job.PID)) {
+			// Flush anything written between the last read and the state change
+			_, err := uc.copyFrom(w, logPath, offset)
+			return err
+		}
+		uc.sleep(followInterval)
+	}
+}
+
+// Cancel stops a job. Queued jobs are simply marked cancelled; a running job's
+// worker process tree is killed first. Other queued jobs are handed to a new
+// worker. Inline jobs still running are refused: their PID is the command
+// that started them, such as the user's interactive claudex.
+func (uc *UseCase) Cancel(id string) (*jobs.Job, error) {
+	job, err := uc.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.State.Finished() {
+		return nil, fmt.Errorf("job %s already %s", job.ID, job.State)
+	}
+
+	if job.State == jobs.StateRunning && job.Inline && uc.alive(job.PID) {
+		return nil, fmt.Errorf("job %s runs inside the claudex command with PID %d, not a background worker; stop that command (Ctrl+C) to cancel it", job.ID, job.PID)
+	}
+
+	killed := false
+	if job.State == jobs.StateRunning && uc.alive(job.PID) {
+		if err := uc.kill(job.PID); err != nil {
+			return nil, fmt.Errorf("failed to stop job %s: %w", job.ID, err)
+		}
+		killed = true
+	}
+
+	// Reload in case the job finished while we were stopping it
+	if current, err := uc.queue.Get(job.ID); err == nil {
+		job = current
+	}
+	if job.State.Finished() && !killed {
+		return nil, fmt.Errorf("job %s already %s", job.ID, job.State)
+	}
+
+	job.State = jobs.StateCancelled
+	job.NotBefore = ""
+	job.Error = "cancelled by user"
+	job.FinishedAt = uc.clock.Now().UTC().Format(time.RFC3339)
+	if err := uc.queue.Save(job); err != nil {
+		return nil, fmt.Errorf("failed to record cancellation: %w", err)
+	}
+
+	// The killed worker may have been holding other queued jobs
+	if killed {
+		if err := uc.resumeQueue(); err != nil {
+			return job, err
+		}
+	}
+	return job, nil
+}
+
+// Retry re-queues a finished job with a fresh retry budget and starts a worker
+func (uc *UseCase) Retry(id string) (*jobs.Job, error) {
+	job, err := uc.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !job.State.Finished() {
+		return nil, fmt.Errorf("job %s is still %s", job.ID, job.State)
+	}
+
+	job.State = jobs.StateQueued
+	job.Attempts = 0
+	job.MaxAttempts = jobs.DefaultMaxAttempts
+	job.PID = 0
+	job.Inline = false
+	job.ExitCode = nil
+	job.Error = ""
+	job.Stderr = ""
+	job.StartedAt = ""
+	job.FinishedAt = ""
+	job.NotBefore = ""
+	if err := uc.queue.Save(job); err != nil {
+		return nil, fmt.Errorf("failed to queue job %s: %w", job.ID, err)
+	}
+
+	if err := uc.spawner.Spawn(uc.queue.Dir()); err != nil {
+		return job, fmt.Errorf("job %s queued but the worker failed to start: %w", job.ID, err)
+	}
+	return job, nil
+}
+
+// resumeQueue starts a worker if any jobs are still queued
+func (uc *UseCase) resumeQueue() error {
+	all, err := uc.queue.List()
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for _, job := range all {
+		if job.State == jobs.StateQueued {
+			if err := uc.spawner.Spawn(uc.queue.Dir()); err != nil {
+				return fmt.Errorf("failed to restart worker for remaining jobs: %w", err)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// copyFrom writes the contents of path from offset onward, returning bytes copied
+func (uc *UseCase) copyFrom(w io.Writer, path string, offset int64) (int64, error) {
+	f, err := uc.fs.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open job log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to read job log: %w", err)
+	}
+	return io.Copy(w, f)
+}
+
+// displayState annotates running jobs whose process has disappeared
+func (uc *UseCase) displayState(job *jobs.Job) string {
+	if job.State == jobs.StateRunning && !uc.alive(job.PID) {
+		return "running (process gone)"
+	}
+	if job.State == jobs.StateQueued && job.Attempts > 0 {
+		return fmt.Sprintf("queued (retry %d/%d)", job.Attempts+1, job.MaxAttempts)
+	}
+	return string(job.State)
+}
+
+// duration returns how long the job ran, or has been running
+func (uc *UseCase) duration(job *jobs.Job) string {
+	start, err := time.Parse(time.RFC3339, job.StartedAt)
+	if err != nil {
+		return ""
+	}
+
+	end := uc.clock.Now()
+	if job.FinishedAt != "" {
+		if finished, err := time.Parse(time.RFC3339, job.FinishedAt); err == nil {
+			end = finished
+		}
+	} else if job.State != jobs.StateRunning {
+		return ""
+	}
+
+	d := end.Sub(start)
+	if d < 0 {
+		d = 0
+	}
+	return d.Round(time.Second).String()
+}
+
+// exitStatus formats the recorded exit code
+func exitStatus(job *jobs.Job) string {
+	if job.ExitCode == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *job.ExitCode)
+}
+
+// formatTime renders an RFC3339 timestamp in local time
+func formatTime(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// pidString formats a PID, leaving unset PIDs blank
+func pidString(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", pid)
+}
+
+// orDash substitutes a dash for empty table cells
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,266 @@
+package managejobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"claudex/internal/services/jobs"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSpawner struct {
+	dirs []string
+}
+
+func (s *fakeSpawner) Spawn(dir string) error {
+	s.dirs = append(s.dirs, dir)
+	return nil
+}
+
+// newTestUseCase wires the usecase to an in-memory registry where no PIDs are alive
+func newTestUseCase(h *testutil.TestHarness) (*UseCase, jobs.Service, *fakeSpawner, *[]int) {
+	h.FixedTime = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	queue := jobs.New(h.FS, "/project/.claudex/jobs", h, h)
+	spawner := &fakeSpawner{}
+	uc := New(h.FS, queue, spawner, h)
+
+	killed := &[]int{}
+	uc.kill = func(pid int) error {
+		*killed = append(*killed, pid)
+		return nil
+	}
+	uc.alive = func(pid int) bool { return false }
+	uc.sleep = func(d time.Duration) {}
+	return uc, queue, spawner, killed
+}
+
+func TestFind_ByUniquePrefix(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"aaaa1111-0", "bbbb2222-0"}
+	uc, queue, _, _ := newTestUseCase(h)
+
+	first, err := queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+	_, err = queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+
+	job, err := uc.Find("20250115-100000-aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, job.ID)
+
+	// The random suffix alone is enough
+	job, err = uc.Find("aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, job.ID)
+
+	// Shared prefix matches both jobs
+	_, err = uc.Find("20250115-100000")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ambiguous")
+
+	_, err = uc.Find("nope")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job not found")
+}
+
+func TestList_ShowsTargetModelAndExitStatus(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, _, _ := newTestUseCase(h)
+
+	job, err := queue.Begin(jobs.Request{Kind: jobs.KindCreateIndex, Target: "/project/pkg/index.md", Model: "haiku"})
+	require.NoError(t, err)
+	h.FixedTime = h.FixedTime.Add(90 * time.Second)
+	require.NoError(t, queue.Finish(job, nil))
+
+	var out bytes.Buffer
+	require.NoError(t, uc.List(&out, 0))
+
+	output := out.String()
+	assert.Contains(t, output, job.ID)
+	assert.Contains(t, output, "create-index")
+	assert.Contains(t, output, "done")
+	assert.Contains(t, output, "/project/pkg/index.md")
+	assert.Contains(t, output, "haiku")
+	assert.Contains(t, output, "1m30s")
+}
+
+func TestList_Limit(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"aaaaaaaa-0", "bbbbbbbb-0", "cccccccc-0"}
+	uc, queue, _, _ := newTestUseCase(h)
+
+	for i := 0; i < 3; i++ {
+		_, err := queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+		require.NoError(t, err)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, uc.List(&out, 2))
+
+	output := out.String()
+	assert.Contains(t, output, "cccccccc")
+	assert.Contains(t, output, "bbbbbbbb")
+	assert.NotContains(t, output, "aaaaaaaa")
+	assert.Contains(t, output, "1 older job(s) not shown")
+}
+
+func TestList_Empty(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, _, _, _ := newTestUseCase(h)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.List(&out, 0))
+	assert.Contains(t, out.String(), "No background jobs recorded")
+}
+
+func TestShow_IncludesErrorAndOutput(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, _, _ := newTestUseCase(h)
+
+	job, err := queue.Begin(jobs.Request{Kind: jobs.KindCreateIndex, Target: "/project/pkg/index.md"})
+	require.NoError(t, err)
+	job.Stderr = "rate limited"
+	require.NoError(t, queue.Finish(job, errors.New("claude invocation failed")))
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Show(&out, job.ID))
+
+	output := out.String()
+	assert.Contains(t, output, "failed")
+	assert.Contains(t, output, "claude invocation failed")
+	assert.Contains(t, output, "rate limited")
+	assert.Contains(t, output, queue.LogPath(job.ID))
+}
+
+func TestLogs_FollowStopsWhenJobFinishes(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, _, _ := newTestUseCase(h)
+
+	job, err := queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+	h.WriteFile(queue.LogPath(job.ID), "first\n")
+
+	// The job finishes and writes more output while we wait
+	uc.sleep = func(d time.Duration) {
+		h.WriteFile(queue.LogPath(job.ID), "first\nsecond\n")
+		job.State = jobs.StateDone
+		queue.Save(job)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Logs(&out, job.ID, true))
+	assert.Equal(t, "first\nsecond\n", out.String())
+}
+
+func TestCancel_QueuedJob(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, spawner, killed := newTestUseCase(h)
+
+	job, err := queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+
+	cancelled, err := uc.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StateCancelled, cancelled.State)
+	assert.Empty(t, *killed)
+	assert.Empty(t, spawner.dirs)
+
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StateCancelled, loaded.State)
+}
+
+func TestCancel_RunningJobKillsWorkerAndResumesQueue(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.UUIDs = []string{"aaaaaaaa-0", "bbbbbbbb-0"}
+	uc, queue, spawner, killed := newTestUseCase(h)
+	uc.alive = func(pid int) bool { return pid == 4242 }
+
+	running, err := queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+	running.State = jobs.StateRunning
+	running.PID = 4242
+	require.NoError(t, queue.Save(running))
+	_, err = queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+
+	_, err = uc.Cancel(running.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{4242}, *killed)
+	assert.Equal(t, []string{"/project/.claudex/jobs"}, spawner.dirs)
+}
+
+func TestCancel_RunningInlineJobRefused(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, spawner, killed := newTestUseCase(h)
+	uc.alive = func(pid int) bool { return true }
+
+	job, err := queue.Begin(jobs.Request{Kind: jobs.KindCreateIndex})
+	require.NoError(t, err)
+
+	_, err = uc.Cancel(job.ID)
+	assert.ErrorContains(t, err, "runs inside the claudex command")
+	assert.Empty(t, *killed, "the foreground claudex is never killed")
+	assert.Empty(t, spawner.dirs)
+
+	loaded, err := queue.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StateRunning, loaded.State)
+
+	// Once that command is gone the stale record can be cancelled
+	uc.alive = func(pid int) bool { return false }
+	cancelled, err := uc.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StateCancelled, cancelled.State)
+}
+
+func TestCancel_FinishedJobFails(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, _, _ := newTestUseCase(h)
+
+	job, err := queue.Begin(jobs.Request{Kind: jobs.KindCreateIndex})
+	require.NoError(t, err)
+	require.NoError(t, queue.Finish(job, nil))
+
+	_, err = uc.Cancel(job.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already done")
+}
+
+func TestRetry_RequeuesFailedJob(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, spawner, _ := newTestUseCase(h)
+
+	job, err := queue.Begin(jobs.Request{Kind: jobs.KindCreateIndex})
+	require.NoError(t, err)
+	require.NoError(t, queue.Finish(job, errors.New("boom")))
+
+	retried, err := uc.Retry(job.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, jobs.StateQueued, retried.State)
+	assert.Equal(t, 0, retried.Attempts)
+	assert.Equal(t, jobs.DefaultMaxAttempts, retried.MaxAttempts)
+	assert.Nil(t, retried.ExitCode)
+	assert.Empty(t, retried.Error)
+	assert.Equal(t, []string{"/project/.claudex/jobs"}, spawner.dirs)
+}
+
+func TestRetry_ActiveJobFails(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc, queue, spawner, _ := newTestUseCase(h)
+
+	job, err := queue.Enqueue(jobs.Request{Kind: jobs.KindIndexUpdate})
+	require.NoError(t, err)
+
+	_, err = uc.Retry(job.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "still queued")
+	assert.Empty(t, spawner.dirs)
+}
@@ -0,0 +1,252 @@
+package managesessions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// LogArchiveDir is the folder inside the logs directory that the GC moves
+// log files to
+const LogArchiveDir = "archive"
+
+// Retention limits what the GC keeps active. Pinned sessions are always kept.
+type Retention struct {
+	MaxAge     time.Duration // Archive sessions and logs unused for longer; 0 for no limit
+	MaxCount   int           // Keep this many sessions and loose logs, most recent first; 0 for no limit
+	KeepTagged bool          // Never archive tagged sessions
+}
+
+// Enabled reports whether the retention sets any limit
+func (r Retention) Enabled() bool {
+	return r.MaxAge > 0 || r.MaxCount > 0
+}
+
+// GCOptions adjusts a GC run
+type GCOptions struct {
+	DryRun  bool   // Report what would be archived without moving anything
+	KeepLog string // Log file in use by the running claudex, never moved
+}
+
+// Collected is a session or log file the GC archived, or would archive
+type Collected struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// GCReport lists what a GC run archived
+type GCReport struct {
+	DryRun   bool        `json:"dryRun"`
+	Sessions []Collected `json:"sessions"`
+	Logs     []Collected `json:"logs"`
+	Skipped  []Collected `json:"skipped,omitempty"` // Over a limit but kept: in use by a job or another process
+}
+
+// GC archives the sessions and log files beyond the retention limits. Nothing
+// is deleted: sessions move to the archive, where `session unarchive` restores
+// them and `session purge` deletes them, and log files move to the logs
+// directory's archive folder. Sessions are kept while they are pinned, tagged
+// (with KeepTagged), Claude is running in them or they have unfinished
+// background jobs; pinned and tagged
+// sessions don't count towards MaxCount. A session's log file is archived with
+// it; the other (loose) log files, such as those of ephemeral runs, get the
+// same age and count limits; one that can't be moved is kept.
+func (uc *UseCase) GC(retention Retention, opts GCOptions) (*GCReport, error) {
+	report := &GCReport{DryRun: opts.DryRun, Sessions: []Collected{}, Logs: []Collected{}}
+	now := uc.clock.Now()
+
+	summaries, err := uc.Sessions()
+	if err != nil {
+		return nil, err
+	}
+	kept := 0
+	for _, s := range summaries {
+		if s.Pinned || (retention.KeepTagged && len(s.Tags) > 0) {
+			continue
+		}
+		reason := expired(retention, now, s.lastActivity(), kept)
+		if reason == "" {
+			kept++
+			continue
+		}
+
+		if pid := session.ActivePID(uc.fs, s.Path); pid != 0 && uc.alive(pid) {
+			report.Skipped = append(report.Skipped, Collected{Name: "session " + s.Name, Reason: fmt.Sprintf("claude is running in it (claudex PID %d)", pid)})
+			continue
+		}
+		sessionJobs, err := uc.sessionJobs(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(sessionJobs) > 0 {
+			report.Skipped = append(report.Skipped, Collected{Name: "session " + s.Name, Reason: fmt.Sprintf("background job %s is pending", sessionJobs[0].ID)})
+			continue
+		}
+
+		if !opts.DryRun {
+			if err := session.Archive(uc.fs, uc.clock, uc.sessionsDir, uc.archiveDir, s.Name); err != nil {
+				return report, fmt.Errorf("failed to archive session %s: %w", s.Name, err)
+			}
+			if err := uc.archiveLog(s.Name + ".log"); err != nil {
+				return report, err
+			}
+		}
+		report.Sessions = append(report.Sessions, Collected{Name: s.Name, Reason: reason})
+	}
+
+	logs, err := uc.looseLogs(opts.KeepLog)
+	if err != nil {
+		return report, err
+	}
+	for i, log := range logs {
+		reason := expired(retention, now, log.ModTime(), i)
+		if reason == "" {
+			continue
+		}
+		if !opts.DryRun {
+			if err := uc.archiveLog(log.Name()); err != nil {
+				// Another claudex may still be writing it (Windows refuses to move open files)
+				report.Skipped = append(report.Skipped, Collected{Name: "log " + log.Name(), Reason: err.Error()})
+				continue
+			}
+		}
+		report.Logs = append(report.Logs, Collected{Name: log.Name(), Reason: reason})
+	}
+	return report, nil
+}
+
+// expired returns why an item last active at last is over the retention
+// limits, given the number of more recent items kept, or "" when it is within
+func expired(retention Retention, now, last time.Time, kept int) string {
+	if retention.MaxAge > 0 && !last.IsZero() && now.Sub(last) > retention.MaxAge {
+		return fmt.Sprintf("unused for %d days", int(now.Sub(last).Hours()/24))
+	}
+	if retention.MaxCount > 0 && kept >= retention.MaxCount {
+		return fmt.Sprintf("beyond the %d most recent", retention.MaxCount)
+	}
+	return ""
+}
+
+// looseLogs returns the log files that belong to no active session, most
+// recently modified first, leaving out keep
+func (uc *UseCase) looseLogs(keep string) ([]os.FileInfo, error) {
+	if uc.logsDir == "" {
+		return nil, nil
+	}
+	entries, err := afero.ReadDir(uc.fs, uc.logsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	var logs []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".log" || filepath.Join(uc.logsDir, name) == keep {
+			continue
+		}
+		if exists, _ := afero.DirExists(uc.fs, filepath.Join(uc.sessionsDir, strings.TrimSuffix(name, ".log"))); exists {
+			continue
+		}
+		logs = append(logs, entry)
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].ModTime().After(logs[j].ModTime())
+	})
+	return logs, nil
+}
+
+// archiveLog moves a log file into the log archive, numbering it when the
+// archive already holds one of that name. A missing log is not an error.
+func (uc *UseCase) archiveLog(name string) error {
+	if uc.logsDir == "" {
+		return nil
+	}
+	src := filepath.Join(uc.logsDir, name)
+	if _, err := uc.fs.Stat(src); err != nil {
+		return nil
+	}
+
+	archiveDir := filepath.Join(uc.logsDir, LogArchiveDir)
+	if err := uc.fs.MkdirAll(archiveDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", archiveDir, err)
+	}
+	dst := filepath.Join(archiveDir, name)
+	for i := 1; ; i++ {
+		if _, err := uc.fs.Stat(dst); err != nil {
+			break
+		}
+		dst = filepath.Join(archiveDir, fmt.Sprintf("%s.%d.log", strings.TrimSuffix(name, ".log"), i))
+	}
+	if err := uc.fs.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to archive log %s: %w", name, err)
+	}
+	return nil
+}
+
+// PurgeLogs permanently deletes the archived log files and returns how many
+// there were
+func (uc *UseCase) PurgeLogs() (int, error) {
+	if uc.logsDir == "" {
+		return 0, nil
+	}
+	archiveDir := filepath.Join(uc.logsDir, LogArchiveDir)
+	entries, err := afero.ReadDir(uc.fs, archiveDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read archived logs: %w", err)
+	}
+	if err := uc.fs.RemoveAll(archiveDir); err != nil {
+		return 0, fmt.Errorf("failed to purge archived logs: %w", err)
+	}
+	return len(entries), nil
+}
+
+// WriteGC writes what a GC run archived, or would archive on a dry run, or
+// the report as JSON when asJSON is set
+func WriteGC(w io.Writer, report *GCReport, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, report)
+	}
+
+	verb := "Archived"
+	if report.DryRun {
+		verb = "Would archive"
+	}
+	for _, s := range report.Sessions {
+		fmt.Fprintf(w, "%s session %s (%s)\n", verb, s.Name, s.Reason)
+	}
+	for _, l := range report.Logs {
+		fmt.Fprintf(w, "%s log %s (%s)\n", verb, l.Name, l.Reason)
+	}
+	for _, s := range report.Skipped {
+		fmt.Fprintf(w, "Kept %s: %s\n", s.Name, s.Reason)
+	}
+
+	if len(report.Sessions) == 0 && len(report.Logs) == 0 {
+		fmt.Fprintln(w, "Nothing is over the retention limits.")
+		return nil
+	}
+	fmt.Fprintf(w, "%s %s and %s.\n", verb, plural(len(report.Sessions), "session"), plural(len(report.Logs), "log file"))
+	return nil
+}
+
+// plural formats a count with its noun, adding an s unless it is one
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
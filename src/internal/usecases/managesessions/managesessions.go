@@ -0,0 +1,463 @@
+// Package managesessions provides the usecase behind the scriptable
+// `claudex session` commands that don't launch Claude: listing, inspecting,
+// renaming, archiving and removing sessions in the project's session store,
+// and the retention GC behind `claudex gc`.
+package managesessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/process"
+	"claudex/internal/services/profile"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Summary describes one session for `claudex session list|show`
+type Summary struct {
+	Name            string                 `json:"name"`
+	Path            string                 `json:"path"`
+	ClaudeSessionID string                 `json:"claudeSessionId,omitempty"`
+	Description     string                 `json:"description,omitempty"`
+	Tags            []string               `json:"tags,omitempty"`
+	Pinned          bool                   `json:"pinned,omitempty"`
+	Parent          string                 `json:"parent,omitempty"`
+	Lineage         []session.LineageEvent `json:"lineage,omitempty"`
+	Branch          string                 `json:"branch,omitempty"`
+	Agent           string                 `json:"agent,omitempty"`
+	ClaudeArgs      []string               `json:"claudeArgs,omitempty"`
+	Created         string                 `json:"created,omitempty"`
+	LastUsed        string                 `json:"lastUsed,omitempty"`
+	Archived        string                 `json:"archived,omitempty"`
+}
+
+// lastActivity returns when the session was last used, falling back to its
+// creation time
+func (s Summary) lastActivity() time.Time {
+	for _, ts := range []string{s.LastUsed, s.Created} {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// UseCase manages the sessions in a sessions directory and its archive
+type UseCase struct {
+	fs          afero.Fs
+	clock       clock.Clock
+	queue       jobs.Service
+	sessionsDir string
+	archiveDir  string
+	logsDir     string
+	alive       func(pid int) bool
+	warnings    io.Writer
+}
+
+// New creates a new ManageSessions usecase. queue holds the background jobs
+// that write into sessions and logsDir the per-session log files; renames
+// keep both in step. queue may be nil.
+func New(fs afero.Fs, clk clock.Clock, queue jobs.Service, sessionsDir, archiveDir, logsDir string) *UseCase {
+	return &UseCase{
+		fs:          fs,
+		clock:       clk,
+		queue:       queue,
+		sessionsDir: sessionsDir,
+		archiveDir:  archiveDir,
+		logsDir:     logsDir,
+		alive:       process.Alive,
+		warnings:    os.Stderr,
+	}
+}
+
+// Find resolves a session by its full name, a prefix or a fuzzy match
+func (uc *UseCase) Find(name string) (string, error) {
+	return session.Resolve(uc.fs, uc.sessionsDir, name)
+}
+
+// Sessions returns every session, most recently used first
+func (uc *UseCase) Sessions() ([]Summary, error) {
+	return uc.sessionsIn(uc.sessionsDir)
+}
+
+// Archived returns every archived session, most recently used first
+func (uc *UseCase) Archived() ([]Summary, error) {
+	return uc.sessionsIn(uc.archiveDir)
+}
+
+// sessionsIn summarizes the session folders in dir. A session whose
+// metadata can't be read (corrupt, or written by a newer claudex) is left
+// out with a warning rather than failing the whole listing.
+func (uc *UseCase) sessionsIn(dir string) ([]Summary, error) {
+	entries, err := afero.ReadDir(uc.fs, dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Summary{}, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	summaries := []Summary{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		summary, err := uc.summary(dir, entry.Name())
+		if err != nil {
+			fmt.Fprintf(uc.warnings, "Warning: skipping %v\n", err)
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].lastActivity().After(summaries[j].lastActivity())
+	})
+	return summaries, nil
+}
+
+// List writes a table of sessions, or a JSON array when asJSON is set
+func (uc *UseCase) List(w io.Writer, asJSON bool) error {
+	summaries, err := uc.Sessions()
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return writeJSON(w, summaries)
+	}
+
+	if len(summaries) == 0 {
+		fmt.Fprintln(w, "No sessions yet. Create one with: claudex session new \"<description>\"")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tLAST USED\tDESCRIPTION")
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, formatTime(s.lastActivity()), orDash(firstLine(s.Description)))
+	}
+	return tw.Flush()
+}
+
+// ListArchived writes a table of archived sessions, or a JSON array when
+// asJSON is set
+func (uc *UseCase) ListArchived(w io.Writer, asJSON bool) error {
+	summaries, err := uc.Archived()
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return writeJSON(w, summaries)
+	}
+
+	if len(summaries) == 0 {
+		fmt.Fprintln(w, "The archive is empty.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tARCHIVED\tDESCRIPTION")
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, formatTime(parseTime(s.Archived)), orDash(firstLine(s.Description)))
+	}
+	return tw.Flush()
+}
+
+// Show writes the details of one session
+func (uc *UseCase) Show(w io.Writer, name string, asJSON bool) error {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return err
+	}
+	summary, err := uc.summary(uc.sessionsDir, sessionName)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return writeJSON(w, summary)
+	}
+
+	fmt.Fprintf(w, "Name:        %s\n", summary.Name)
+	fmt.Fprintf(w, "Path:        %s\n", summary.Path)
+	fmt.Fprintf(w, "Session ID:  %s\n", orDash(summary.ClaudeSessionID))
+	agent := summary.Agent
+	if agent == "" {
+		agent = profile.DefaultAgent + " (default)"
+	}
+	fmt.Fprintf(w, "Agent:       %s\n", agent)
+	if len(summary.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:        %s\n", strings.Join(summary.Tags, ", "))
+	}
+	if summary.Pinned {
+		fmt.Fprintf(w, "Pinned:      yes\n")
+	}
+	if summary.Parent != "" {
+		fmt.Fprintf(w, "Parent:      %s\n", summary.Parent)
+	}
+	for i, event := range summary.Lineage {
+		label := "Lineage:"
+		if i > 0 {
+			label = ""
+		}
+		fmt.Fprintf(w, "%-12s %s from %s (%s)\n", label, event.Op, event.From, orDash(event.At))
+	}
+	if summary.Branch != "" {
+		fmt.Fprintf(w, "Branch:      %s\n", summary.Branch)
+	}
+	if len(summary.ClaudeArgs) > 0 {
+		fmt.Fprintf(w, "Claude args: %s\n", strings.Join(summary.ClaudeArgs, " "))
+	}
+	fmt.Fprintf(w, "Created:     %s\n", orDash(summary.Created))
+	fmt.Fprintf(w, "Last used:   %s\n", orDash(summary.LastUsed))
+	if summary.Description != "" {
+		fmt.Fprintf(w, "\nDescription:\n%s\n", summary.Description)
+	}
+
+	files, err := uc.files(summary.Path)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(w, "\nFiles:\n")
+		for _, file := range files {
+			fmt.Fprintf(w, "  %s\n", file)
+		}
+	}
+	return nil
+}
+
+// Remove deletes a session folder and returns the removed session's name.
+// A session Claude or a background job is using is refused.
+func (uc *UseCase) Remove(name string) (string, error) {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := uc.checkNotInUse(sessionName, filepath.Join(uc.sessionsDir, sessionName)); err != nil {
+		return "", err
+	}
+	if err := uc.fs.RemoveAll(filepath.Join(uc.sessionsDir, sessionName)); err != nil {
+		return "", fmt.Errorf("failed to remove session %s: %w", sessionName, err)
+	}
+	return sessionName, nil
+}
+
+// Archive moves a session to the archive and returns its name. A session
+// Claude or a background job is using is refused.
+func (uc *UseCase) Archive(name string) (string, error) {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := uc.checkNotInUse(sessionName, filepath.Join(uc.sessionsDir, sessionName)); err != nil {
+		return "", err
+	}
+	if err := session.Archive(uc.fs, uc.clock, uc.sessionsDir, uc.archiveDir, sessionName); err != nil {
+		return "", fmt.Errorf("failed to archive session %s: %w", sessionName, err)
+	}
+	return sessionName, nil
+}
+
+// Unarchive moves an archived session back and returns its name
+func (uc *UseCase) Unarchive(name string) (string, error) {
+	sessionName, err := uc.FindArchived(name)
+	if err != nil {
+		return "", err
+	}
+	if err := session.Unarchive(uc.fs, uc.sessionsDir, uc.archiveDir, sessionName); err != nil {
+		return "", fmt.Errorf("failed to restore session %s: %w", sessionName, err)
+	}
+	return sessionName, nil
+}
+
+// FindArchived resolves an archived session like Find does an active one
+func (uc *UseCase) FindArchived(name string) (string, error) {
+	sessionName, err := session.Resolve(uc.fs, uc.archiveDir, name)
+	if err != nil {
+		return "", fmt.Errorf("archive: %w", err)
+	}
+	return sessionName, nil
+}
+
+// Purge permanently deletes an archived session and returns its name
+func (uc *UseCase) Purge(name string) (string, error) {
+	sessionName, err := uc.FindArchived(name)
+	if err != nil {
+		return "", err
+	}
+	if err := uc.fs.RemoveAll(filepath.Join(uc.archiveDir, sessionName)); err != nil {
+		return "", fmt.Errorf("failed to purge session %s: %w", sessionName, err)
+	}
+	return sessionName, nil
+}
+
+// PurgeAll permanently deletes every archived session and returns their names
+func (uc *UseCase) PurgeAll() ([]string, error) {
+	summaries, err := uc.Archived()
+	if err != nil {
+		return nil, err
+	}
+	var purged []string
+	for _, s := range summaries {
+		if err := uc.fs.RemoveAll(s.Path); err != nil {
+			return purged, fmt.Errorf("failed to purge session %s: %w", s.Name, err)
+		}
+		purged = append(purged, s.Name)
+	}
+	return purged, nil
+}
+
+// Tag adds tags to a session and removes others, returning the session name
+// and its tags afterwards. Without tags to add or remove it only reads them.
+func (uc *UseCase) Tag(name string, add, remove []string) (string, []string, error) {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if add, err = session.NormalizeTags(add); err != nil {
+		return "", nil, err
+	}
+	if remove, err = session.NormalizeTags(remove); err != nil {
+		return "", nil, err
+	}
+
+	sessionPath := filepath.Join(uc.sessionsDir, sessionName)
+	if len(add) == 0 && len(remove) == 0 {
+		metadata, err := session.ReadMetadata(uc.fs, sessionPath)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read session %s: %w", sessionName, err)
+		}
+		return sessionName, metadata.Tags, nil
+	}
+
+	var tags []string
+	err = session.UpdateMetadata(uc.fs, sessionPath, func(m *session.SessionMetadata) {
+		tags = []string{}
+		for _, tag := range m.Tags {
+			if !session.HasTag(remove, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for _, tag := range add {
+			if !session.HasTag(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		m.Tags = tags
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to tag session %s: %w", sessionName, err)
+	}
+	return sessionName, tags, nil
+}
+
+// Pin marks a session as kept by the retention GC, or clears the mark, and
+// returns the session name
+func (uc *UseCase) Pin(name string, pinned bool) (string, error) {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return "", err
+	}
+	err = session.UpdateMetadata(uc.fs, filepath.Join(uc.sessionsDir, sessionName), func(m *session.SessionMetadata) {
+		m.Pinned = pinned
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to pin session %s: %w", sessionName, err)
+	}
+	return sessionName, nil
+}
+
+// summary reads the metadata of a session folder in dir
+func (uc *UseCase) summary(dir, sessionName string) (Summary, error) {
+	sessionPath := filepath.Join(dir, sessionName)
+	metadata, err := session.ReadMetadata(uc.fs, sessionPath)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to read session %s: %w", sessionName, err)
+	}
+
+	return Summary{
+		Name:            sessionName,
+		Path:            sessionPath,
+		ClaudeSessionID: metadata.ClaudeSessionID,
+		Description:     metadata.Description,
+		Tags:            metadata.Tags,
+		Pinned:          metadata.Pinned,
+		Parent:          metadata.Parent,
+		Lineage:         metadata.Lineage,
+		Branch:          metadata.Branch,
+		Agent:           metadata.Agent,
+		ClaudeArgs:      metadata.ClaudeArgs,
+		Created:         metadata.Created,
+		LastUsed:        metadata.LastUsed,
+		Archived:        metadata.Archived,
+	}, nil
+}
+
+// files lists the session's documents, skipping session.json and dotfiles
+func (uc *UseCase) files(sessionPath string) ([]string, error) {
+	var files []string
+	err := afero.Walk(uc.fs, sessionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") || info.Name() == session.MetadataFile {
+			return nil
+		}
+		rel, err := filepath.Rel(sessionPath, path)
+		if err != nil {
+			rel = info.Name()
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session files: %w", err)
+	}
+	return files, nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func parseTime(ts string) time.Time {
+	t, _ := time.Parse(time.RFC3339, ts)
+	return t
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,535 @@
+package managesessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/session"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sessionsDir = "/project/.claudex/sessions"
+	archiveDir  = "/project/.claudex/archive"
+	logsDir     = "/project/.claudex/logs"
+	jobsDir     = "/project/.claudex/jobs"
+)
+
+const authSession = "auth-refactor-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+
+func newTestUseCase() (*UseCase, *testutil.TestHarness) {
+	h := testutil.NewTestHarness()
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, authSession), map[string]string{
+		".description":        "Refactor the auth module",
+		".created":            "2024-01-10T10:00:00Z",
+		".last_used":          "2024-01-15T09:00:00Z",
+		".claude_args":        "--model\nopus\n",
+		"session-overview.md": "# Overview",
+	})
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, "billing-ui-11112222-3333-4444-5555-666666666666"), map[string]string{
+		".description": "Billing UI",
+		".created":     "2024-01-12T10:00:00Z",
+	})
+	return New(h.FS, h, jobs.New(h.FS, jobsDir, h, h), sessionsDir, archiveDir, logsDir), h
+}
+
+func TestSessions_MostRecentlyUsedFirst(t *testing.T) {
+	uc, _ := newTestUseCase()
+
+	summaries, err := uc.Sessions()
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, authSession, summaries[0].Name)
+	assert.Equal(t, "aaaabbbb-cccc-dddd-eeee-ffffffffffff", summaries[0].ClaudeSessionID)
+	assert.Equal(t, "Refactor the auth module", summaries[0].Description)
+	assert.Equal(t, "billing-ui-11112222-3333-4444-5555-666666666666", summaries[1].Name)
+}
+
+func TestSessions_SkipsUnreadableMetadata(t *testing.T) {
+	uc, h := newTestUseCase()
+	var warnings bytes.Buffer
+	uc.warnings = &warnings
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, "broken-99998888-7777-6666-5555-444444444444"), map[string]string{
+		session.MetadataFile: "{not json",
+	})
+
+	summaries, err := uc.Sessions()
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, authSession, summaries[0].Name)
+	assert.Contains(t, warnings.String(), "broken-99998888-7777-6666-5555-444444444444")
+
+	var out bytes.Buffer
+	require.NoError(t, uc.List(&out, false))
+	assert.Contains(t, out.String(), "auth-refactor")
+}
+
+func TestList_JSON(t *testing.T) {
+	uc, _ := newTestUseCase()
+
+	var out bytes.Buffer
+	require.NoError(t, uc.List(&out, true))
+
+	var decoded []Summary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	assert.Equal(t, "2024-01-15T09:00:00Z", decoded[0].LastUsed)
+}
+
+func TestList_Empty(t *testing.T) {
+	h := testutil.NewTestHarness()
+	uc := New(h.FS, h, nil, sessionsDir, archiveDir, logsDir)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.List(&out, false))
+	assert.Contains(t, out.String(), "No sessions yet")
+}
+
+func TestShow_ListsFilesWithoutMetadata(t *testing.T) {
+	uc, _ := newTestUseCase()
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Show(&out, "auth", false))
+	text := out.String()
+	assert.Contains(t, text, "Name:        "+authSession)
+	assert.Contains(t, text, "Refactor the auth module")
+	assert.Contains(t, text, "Claude args: --model opus")
+	assert.Contains(t, text, "session-overview.md")
+	assert.NotContains(t, text, ".description")
+}
+
+func TestRename_KeepsClaudeSessionID(t *testing.T) {
+	uc, h := newTestUseCase()
+
+	newName, err := uc.Rename("auth", "Login Rework")
+	require.NoError(t, err)
+	assert.Equal(t, "login-rework-aaaabbbb-cccc-dddd-eeee-ffffffffffff", newName)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, newName))
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(sessionsDir, authSession))
+}
+
+func TestRename_TargetExists(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.CreateDir(filepath.Join(sessionsDir, "billing-ui-aaaabbbb-cccc-dddd-eeee-ffffffffffff"))
+
+	_, err := uc.Rename("auth", "billing-ui")
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestRename_MovesLogReferencesAndQueuedJobs(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.UUIDs = []string{"11111111-0000", "22222222-0000"}
+	oldPath := filepath.Join(sessionsDir, authSession)
+	h.WriteFile(filepath.Join(logsDir, authSession+".log"), "log")
+	h.CreateDir(filepath.Join(archiveDir, "old-auth-00000000-0000-0000-0000-000000000000"))
+	require.NoError(t, session.WriteMetadata(h.FS, filepath.Join(sessionsDir, "billing-ui-11112222-3333-4444-5555-666666666666"), &session.SessionMetadata{
+		Description: "Billing UI",
+		Parent:      authSession,
+		Lineage:     []session.LineageEvent{{Op: session.OpFork, From: authSession}},
+	}))
+	require.NoError(t, session.WriteMetadata(h.FS, filepath.Join(archiveDir, "old-auth-00000000-0000-0000-0000-000000000000"), &session.SessionMetadata{
+		Parent: authSession,
+	}))
+	queued, err := uc.queue.Enqueue(jobs.Request{
+		Kind:    jobs.KindSessionOverview,
+		Target:  filepath.Join(oldPath, "session-overview.md"),
+		Payload: map[string]interface{}{"SessionPath": oldPath, "OutputFile": "session-overview.md", "StartLine": 12},
+	})
+	require.NoError(t, err)
+	done, err := uc.queue.Enqueue(jobs.Request{Kind: jobs.KindSessionOverview, Target: filepath.Join(oldPath, "session-overview.md")})
+	require.NoError(t, err)
+	done.State = jobs.StateDone
+	require.NoError(t, uc.queue.Save(done))
+
+	newName, err := uc.Rename("auth", "login")
+	require.NoError(t, err)
+	newPath := filepath.Join(sessionsDir, newName)
+
+	testutil.AssertFileContains(t, h.FS, filepath.Join(logsDir, newName+".log"), "log")
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(logsDir, authSession+".log"))
+
+	billing, err := session.ReadMetadata(h.FS, filepath.Join(sessionsDir, "billing-ui-11112222-3333-4444-5555-666666666666"))
+	require.NoError(t, err)
+	assert.Equal(t, newName, billing.Parent)
+	assert.Equal(t, newName, billing.Lineage[0].From)
+	archived, err := session.ReadMetadata(h.FS, filepath.Join(archiveDir, "old-auth-00000000-0000-0000-0000-000000000000"))
+	require.NoError(t, err)
+	assert.Equal(t, newName, archived.Parent)
+
+	job, err := uc.queue.Get(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(newPath, "session-overview.md"), job.Target)
+	assert.JSONEq(t, `{"SessionPath":"`+newPath+`","OutputFile":"session-overview.md","StartLine":12}`, string(job.Payload))
+
+	job, err = uc.queue.Get(done.ID)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(oldPath, "session-overview.md"), job.Target, "finished jobs keep their history")
+}
+
+func TestRename_RefusedWhileJobRuns(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.UUIDs = []string{"11111111-0000"}
+	job, err := uc.queue.Begin(jobs.Request{Kind: jobs.KindSessionOverview, Target: filepath.Join(sessionsDir, authSession, "session-overview.md")})
+	require.NoError(t, err)
+
+	_, err = uc.Rename("auth", "login")
+	assert.ErrorContains(t, err, "background job "+job.ID)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, authSession))
+}
+
+func TestRename_RefusedWhileClaudeRuns(t *testing.T) {
+	uc, h := newTestUseCase()
+	running := map[int]bool{4242: true}
+	uc.alive = func(pid int) bool { return running[pid] }
+	require.NoError(t, session.MarkActive(h.FS, filepath.Join(sessionsDir, authSession), 4242))
+
+	_, err := uc.Rename("auth", "login")
+	assert.ErrorContains(t, err, "claude is running in "+authSession)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, authSession))
+
+	// A marker left behind by a claudex that died is ignored
+	running[4242] = false
+	newName, err := uc.Rename("auth", "login")
+	require.NoError(t, err)
+	assert.Equal(t, "login-aaaabbbb-cccc-dddd-eeee-ffffffffffff", newName)
+}
+
+func TestRemove(t *testing.T) {
+	uc, h := newTestUseCase()
+
+	removed, err := uc.Remove("billing")
+	require.NoError(t, err)
+	assert.Equal(t, "billing-ui-11112222-3333-4444-5555-666666666666", removed)
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(sessionsDir, removed))
+
+	_, err = uc.Remove("missing")
+	assert.ErrorContains(t, err, "session not found")
+}
+
+func TestRemoveAndArchive_RefusedWhileInUse(t *testing.T) {
+	uc, h := newTestUseCase()
+	uc.alive = func(pid int) bool { return pid == 4242 }
+	require.NoError(t, session.MarkActive(h.FS, filepath.Join(sessionsDir, authSession), 4242))
+	h.UUIDs = []string{"11111111-0000"}
+	billingPath := filepath.Join(sessionsDir, "billing-ui-11112222-3333-4444-5555-666666666666")
+	job, err := uc.queue.Begin(jobs.Request{Kind: jobs.KindSessionOverview, Target: filepath.Join(billingPath, "session-overview.md")})
+	require.NoError(t, err)
+
+	_, err = uc.Remove("auth")
+	assert.ErrorContains(t, err, "claude is running in "+authSession)
+	_, err = uc.Archive("auth")
+	assert.ErrorContains(t, err, "claude is running in "+authSession)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, authSession))
+
+	_, err = uc.Remove("billing")
+	assert.ErrorContains(t, err, "background job "+job.ID)
+	_, err = uc.Archive("billing")
+	assert.ErrorContains(t, err, "background job "+job.ID)
+	testutil.AssertDirExists(t, h.FS, billingPath)
+}
+
+// writeLineageSessions creates login, a fork of it that was restarted fresh
+// (oauth), a fork of oauth made before the restart (google) and a fork whose
+// parent was removed (orphan)
+func writeLineageSessions(t *testing.T, h *testutil.TestHarness) {
+	t.Helper()
+	write := func(name string, metadata *session.SessionMetadata) {
+		path := filepath.Join(sessionsDir, name)
+		h.CreateDir(path)
+		require.NoError(t, session.WriteMetadata(h.FS, path, metadata))
+	}
+	write("login-aaaaaaaa-0000-0000-0000-000000000000", &session.SessionMetadata{
+		Description: "Login",
+		Created:     "2024-01-01T10:00:00Z",
+	})
+	write("oauth-bbbbbbbb-0000-0000-0000-000000000002", &session.SessionMetadata{
+		Description: "OAuth",
+		Created:     "2024-01-02T10:00:00Z",
+		Parent:      "login-aaaaaaaa-0000-0000-0000-000000000000",
+		Lineage: []session.LineageEvent{
+			{Op: session.OpFork, From: "login-aaaaaaaa-0000-0000-0000-000000000000", At: "2024-01-02T10:00:00Z"},
+			{Op: session.OpFresh, From: "oauth-bbbbbbbb-0000-0000-0000-000000000001", At: "2024-01-04T10:00:00Z"},
+		},
+	})
+	write("google-cccccccc-0000-0000-0000-000000000000", &session.SessionMetadata{
+		Description: "Google login",
+		Created:     "2024-01-03T10:00:00Z",
+		Parent:      "oauth-bbbbbbbb-0000-0000-0000-000000000001",
+	})
+	write("orphan-dddddddd-0000-0000-0000-000000000000", &session.SessionMetadata{
+		Description: "Orphan",
+		Created:     "2024-01-05T10:00:00Z",
+		Parent:      "gone-eeeeeeee-0000-0000-0000-000000000000",
+	})
+}
+
+func TestWriteTree(t *testing.T) {
+	h := testutil.NewTestHarness()
+	writeLineageSessions(t, h)
+	uc := New(h.FS, h, nil, sessionsDir, archiveDir, logsDir)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.WriteTree(&out, "", false))
+	assert.Equal(t, `login-aaaaaaaa-0000-0000-0000-000000000000  Login
+└── oauth-bbbbbbbb-0000-0000-0000-000000000002  OAuth  (fresh restart)
+    └── google-cccccccc-0000-0000-0000-000000000000  Google login
+orphan-dddddddd-0000-0000-0000-000000000000  Orphan  (forked from gone-eeeeeeee-0000-0000-0000-000000000000, removed)
+`, out.String())
+
+	// Naming a session shows only its tree
+	out.Reset()
+	require.NoError(t, uc.WriteTree(&out, "google", true))
+	var roots []*Node
+	require.NoError(t, json.Unmarshal(out.Bytes(), &roots))
+	require.Len(t, roots, 1)
+	assert.Equal(t, "login-aaaaaaaa-0000-0000-0000-000000000000", roots[0].Name)
+	require.Len(t, roots[0].Children, 1)
+	assert.Equal(t, "google-cccccccc-0000-0000-0000-000000000000", roots[0].Children[0].Children[0].Name)
+}
+
+// TestTree_BreaksCycles verifies sessions naming each other as parents still
+// show up
+func TestTree_BreaksCycles(t *testing.T) {
+	h := testutil.NewTestHarness()
+	for _, pair := range [][2]string{{"a-session", "b-session"}, {"b-session", "a-session"}, {"c-session", "a-session"}} {
+		path := filepath.Join(sessionsDir, pair[0])
+		h.CreateDir(path)
+		require.NoError(t, session.WriteMetadata(h.FS, path, &session.SessionMetadata{Parent: pair[1]}))
+	}
+	uc := New(h.FS, h, nil, sessionsDir, archiveDir, logsDir)
+
+	roots, err := uc.Tree()
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	assert.Equal(t, "a-session", roots[0].Name)
+	require.Len(t, roots[0].Children, 2)
+	assert.Equal(t, "b-session", roots[0].Children[0].Name)
+	assert.Equal(t, "c-session", roots[0].Children[1].Name)
+}
+
+func TestShow_Lineage(t *testing.T) {
+	h := testutil.NewTestHarness()
+	writeLineageSessions(t, h)
+	uc := New(h.FS, h, nil, sessionsDir, archiveDir, logsDir)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Show(&out, "oauth", false))
+	assert.Contains(t, out.String(), `Parent:      login-aaaaaaaa-0000-0000-0000-000000000000
+Lineage:     fork from login-aaaaaaaa-0000-0000-0000-000000000000 (2024-01-02T10:00:00Z)
+             fresh from oauth-bbbbbbbb-0000-0000-0000-000000000001 (2024-01-04T10:00:00Z)
+`)
+	assert.NotContains(t, out.String(), session.MetadataFile)
+}
+
+func TestArchive_UnarchiveAndPurge(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.FixedTime = time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+
+	name, err := uc.Archive("auth")
+	require.NoError(t, err)
+	assert.Equal(t, authSession, name)
+
+	summaries, err := uc.Sessions()
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+
+	archived, err := uc.Archived()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, authSession, archived[0].Name)
+	assert.Equal(t, "2024-01-20T09:00:00Z", archived[0].Archived)
+	assert.Equal(t, []string{"--model", "opus"}, archived[0].ClaudeArgs)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.ListArchived(&out, false))
+	assert.Contains(t, out.String(), "NAME")
+	assert.Contains(t, out.String(), authSession)
+	assert.Contains(t, out.String(), "Refactor the auth module")
+
+	// Active commands don't see archived sessions
+	_, err = uc.Find("auth")
+	require.Error(t, err)
+
+	name, err = uc.Unarchive("auth")
+	require.NoError(t, err)
+	assert.Equal(t, authSession, name)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, authSession))
+
+	// Purge only reaches archived sessions
+	_, err = uc.Purge("auth")
+	require.Error(t, err)
+	_, err = uc.Archive("auth")
+	require.NoError(t, err)
+	_, err = uc.Archive("billing")
+	require.NoError(t, err)
+
+	name, err = uc.Purge("auth")
+	require.NoError(t, err)
+	assert.Equal(t, authSession, name)
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(archiveDir, authSession))
+
+	purged, err := uc.PurgeAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing-ui-11112222-3333-4444-5555-666666666666"}, purged)
+
+	out.Reset()
+	require.NoError(t, uc.ListArchived(&out, false))
+	assert.Equal(t, "The archive is empty.\n", out.String())
+}
+
+func TestTag(t *testing.T) {
+	uc, h := newTestUseCase()
+
+	name, tags, err := uc.Tag("auth", []string{"Backend,auth"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, authSession, name)
+	assert.Equal(t, []string{"backend", "auth"}, tags)
+
+	_, tags, err = uc.Tag("auth", []string{"security"}, []string{"#backend"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "security"}, tags)
+
+	metadata, err := session.ReadMetadata(h.FS, filepath.Join(sessionsDir, authSession))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth", "security"}, metadata.Tags)
+
+	_, _, err = uc.Tag("auth", []string{"two words"}, nil)
+	assert.ErrorContains(t, err, "invalid tag")
+}
+
+func TestSearch_RanksAndQualifies(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.WriteFile(filepath.Join(sessionsDir, authSession, "research", "tokens.md"), "JWT refresh tokens\nRotate the refresh token on use\n")
+	h.WriteFile(filepath.Join(sessionsDir, "billing-ui-11112222-3333-4444-5555-666666666666", "notes.md"), "Invoices need an auth check\n")
+	_, _, err := uc.Tag("auth", []string{"security"}, nil)
+	require.NoError(t, err)
+
+	// A description match outranks a document match
+	results, err := uc.Search("auth")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, authSession, results[0].Name)
+	assert.Greater(t, results[0].Score, results[1].Score)
+	assert.Equal(t, []Match{{File: "notes.md", Line: 1, Text: "Invoices need an auth check"}}, results[1].Matches)
+
+	// Every word must appear
+	results, err = uc.Search("refresh invoices")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	results, err = uc.Search("REFRESH tag:security")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, session.StatusActive, results[0].Status)
+	assert.Equal(t, "research/tokens.md", results[0].Matches[0].File)
+
+	// Archived sessions are searched too, unless status: says otherwise
+	_, err = uc.Archive("billing")
+	require.NoError(t, err)
+	results, err = uc.Search("invoices")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, session.StatusArchived, results[0].Status)
+	results, err = uc.Search("invoices status:active")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = uc.Search("status:done")
+	assert.ErrorContains(t, err, "unknown status")
+
+	var out bytes.Buffer
+	require.NoError(t, uc.WriteSearch(&out, "nothing-here", false))
+	assert.Equal(t, "No sessions match \"nothing-here\".\n", out.String())
+}
+
+func TestGC(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.FixedTime = time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	billing := "billing-ui-11112222-3333-4444-5555-666666666666"
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, "recent-99990000-1111-2222-3333-444444444444"), map[string]string{
+		".created": "2024-02-19T10:00:00Z",
+	})
+	_, _, err := uc.Tag("auth", []string{"security"}, nil)
+	require.NoError(t, err)
+
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{billing + ".log", "claudex-20240101-000000.log", "claudex-20240220-120000.log"} {
+		h.WriteFile(filepath.Join(logsDir, name), "log")
+		require.NoError(t, h.FS.Chtimes(filepath.Join(logsDir, name), old, old))
+	}
+	current := filepath.Join(logsDir, "claudex-20240220-120000.log")
+	policy := Retention{MaxAge: 30 * 24 * time.Hour, KeepTagged: true}
+
+	// A dry run only reports
+	report, err := uc.GC(policy, GCOptions{DryRun: true, KeepLog: current})
+	require.NoError(t, err)
+	assert.Equal(t, []Collected{{Name: billing, Reason: "unused for 39 days"}}, report.Sessions)
+	assert.Equal(t, []Collected{{Name: "claudex-20240101-000000.log", Reason: "unused for 50 days"}}, report.Logs)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, billing))
+
+	var out bytes.Buffer
+	require.NoError(t, WriteGC(&out, report, false))
+	assert.Contains(t, out.String(), "Would archive session "+billing)
+	assert.Contains(t, out.String(), "Would archive 1 session and 1 log file.")
+
+	// The tagged session is kept, the others go to the archive with their logs
+	report, err = uc.GC(policy, GCOptions{KeepLog: current})
+	require.NoError(t, err)
+	assert.Len(t, report.Sessions, 1)
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(sessionsDir, billing))
+	testutil.AssertDirExists(t, h.FS, filepath.Join(archiveDir, billing))
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, authSession))
+	testutil.AssertFileContains(t, h.FS, filepath.Join(logsDir, LogArchiveDir, billing+".log"), "log")
+	testutil.AssertFileContains(t, h.FS, filepath.Join(logsDir, LogArchiveDir, "claudex-20240101-000000.log"), "log")
+	testutil.AssertFileContains(t, h.FS, current, "log")
+
+	// Beyond the count limit, a session with a pending job or a pin is kept
+	h.UUIDs = []string{"11111111-0000"}
+	job, err := uc.queue.Begin(jobs.Request{Kind: jobs.KindSessionOverview, Target: filepath.Join(sessionsDir, authSession, "session-overview.md")})
+	require.NoError(t, err)
+	report, err = uc.GC(Retention{MaxCount: 1}, GCOptions{KeepLog: current})
+	require.NoError(t, err)
+	assert.Empty(t, report.Sessions)
+	assert.Equal(t, []Collected{{Name: "session " + authSession, Reason: "background job " + job.ID + " is pending"}}, report.Skipped)
+
+	_, err = uc.Pin("auth", true)
+	require.NoError(t, err)
+	require.NoError(t, uc.queue.Finish(job, nil))
+	report, err = uc.GC(Retention{MaxCount: 1}, GCOptions{KeepLog: current})
+	require.NoError(t, err)
+	assert.Empty(t, report.Sessions)
+	assert.Empty(t, report.Skipped)
+
+	_, err = uc.Pin("auth", false)
+	require.NoError(t, err)
+	report, err = uc.GC(Retention{MaxCount: 1}, GCOptions{KeepLog: current})
+	require.NoError(t, err)
+	assert.Equal(t, []Collected{{Name: authSession, Reason: "beyond the 1 most recent"}}, report.Sessions)
+
+	purged, err := uc.PurgeLogs()
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(logsDir, LogArchiveDir))
+}
+
+func TestGC_KeepsSessionClaudeRunsIn(t *testing.T) {
+	uc, h := newTestUseCase()
+	h.FixedTime = time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	billing := "billing-ui-11112222-3333-4444-5555-666666666666"
+	uc.alive = func(pid int) bool { return pid == 4242 }
+	require.NoError(t, session.MarkActive(h.FS, filepath.Join(sessionsDir, billing), 4242))
+
+	// billing is over both the age and the count limit
+	report, err := uc.GC(Retention{MaxAge: 30 * 24 * time.Hour, MaxCount: 1}, GCOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []Collected{{Name: authSession, Reason: "unused for 36 days"}}, report.Sessions)
+	assert.Equal(t, []Collected{{Name: "session " + billing, Reason: "claude is running in it (claudex PID 4242)"}}, report.Skipped)
+	testutil.AssertDirExists(t, h.FS, filepath.Join(sessionsDir, billing))
+}
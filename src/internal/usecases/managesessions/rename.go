@@ -0,0 +1,196 @@
+package managesessions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"claudex/internal/services/jobs"
+	"claudex/internal/services/session"
+)
+
+// Rename gives a session a new slug, keeping its Claude session ID suffix so
+// `claudex session resume` still finds the conversation. The session's log
+// file moves with it, the parent and lineage references of other sessions
+// (active and archived) follow the new name, and queued background jobs write
+// into the renamed folder. A session Claude is running in is refused, since
+// its hooks would keep writing to the old folder. Returns the new name.
+func (uc *UseCase) Rename(name, newSlug string) (string, error) {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return "", err
+	}
+
+	slug := session.CreateManualSlug(session.StripClaudeSessionID(newSlug))
+	if slug == "" {
+		return "", fmt.Errorf("new name %q contains no usable characters", newSlug)
+	}
+
+	oldPath := filepath.Join(uc.sessionsDir, sessionName)
+	newName := slug
+	if claudeID := session.ClaudeSessionID(uc.fs, oldPath); claudeID != "" {
+		newName = fmt.Sprintf("%s-%s", slug, claudeID)
+	}
+	if newName == sessionName {
+		return sessionName, nil
+	}
+
+	newPath := filepath.Join(uc.sessionsDir, newName)
+	if _, err := uc.fs.Stat(newPath); err == nil {
+		return "", fmt.Errorf("session %s already exists", newName)
+	}
+	oldLog, newLog := uc.logPath(sessionName), uc.logPath(newName)
+	if _, err := uc.fs.Stat(newLog); err == nil && uc.logsDir != "" {
+		return "", fmt.Errorf("log file %s already exists", newLog)
+	}
+	sessionJobs, err := uc.checkNotInUse(sessionName, oldPath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := uc.fs.Rename(oldPath, newPath); err != nil {
+		return "", fmt.Errorf("failed to rename session: %w", err)
+	}
+
+	// The session is renamed; what follows only keeps the references in step
+	if _, err := uc.fs.Stat(oldLog); err == nil && uc.logsDir != "" {
+		if err := uc.fs.Rename(oldLog, newLog); err != nil {
+			return newName, fmt.Errorf("renamed to %s, but not its log file: %w", newName, err)
+		}
+	}
+	if err := uc.renameReferences(sessionName, newName); err != nil {
+		return newName, fmt.Errorf("renamed to %s, but not every reference to it: %w", newName, err)
+	}
+	for _, job := range sessionJobs {
+		if err := uc.moveJob(job, oldPath, newPath); err != nil {
+			return newName, fmt.Errorf("renamed to %s, but not queued job %s: %w", newName, job.ID, err)
+		}
+	}
+	return newName, nil
+}
+
+// logPath returns the log file claudex writes for a session
+func (uc *UseCase) logPath(sessionName string) string {
+	return filepath.Join(uc.logsDir, sessionName+".log")
+}
+
+// renameReferences points the parent and lineage entries of every active and
+// archived session naming oldName at newName
+func (uc *UseCase) renameReferences(oldName, newName string) error {
+	for _, dir := range []string{uc.sessionsDir, uc.archiveDir} {
+		summaries, err := uc.sessionsIn(dir)
+		if err != nil {
+			return err
+		}
+		for _, s := range summaries {
+			if !s.references(oldName) {
+				continue
+			}
+			err := session.UpdateMetadata(uc.fs, s.Path, func(m *session.SessionMetadata) {
+				if m.Parent == oldName {
+					m.Parent = newName
+				}
+				for i := range m.Lineage {
+					if m.Lineage[i].From == oldName {
+						m.Lineage[i].From = newName
+					}
+				}
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update session %s: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// references reports whether the session's parent or lineage names a session
+func (s Summary) references(name string) bool {
+	if s.Parent == name {
+		return true
+	}
+	for _, event := range s.Lineage {
+		if event.From == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotInUse refuses a session that Claude is running in or that a
+// background job is writing to, since both keep using the folder they were
+// started with. Returns the session's unfinished (queued) jobs.
+func (uc *UseCase) checkNotInUse(sessionName, sessionPath string) ([]*jobs.Job, error) {
+	if pid := session.ActivePID(uc.fs, sessionPath); pid != 0 && uc.alive(pid) {
+		return nil, fmt.Errorf("claude is running in %s (claudex PID %d); try again once that conversation ends", sessionName, pid)
+	}
+	sessionJobs, err := uc.sessionJobs(sessionPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range sessionJobs {
+		if job.State == jobs.StateRunning {
+			return nil, fmt.Errorf("background job %s is writing to %s; try again once the job finishes (see claudex jobs list)", job.ID, sessionName)
+		}
+	}
+	return sessionJobs, nil
+}
+
+// sessionJobs returns the unfinished background jobs writing into a session folder
+func (uc *UseCase) sessionJobs(sessionPath string) ([]*jobs.Job, error) {
+	if uc.queue == nil {
+		return nil, nil
+	}
+	all, err := uc.queue.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list background jobs: %w", err)
+	}
+	var matching []*jobs.Job
+	for _, job := range all {
+		if _, ok := rebase(job.Target, sessionPath, ""); ok && !job.State.Finished() {
+			matching = append(matching, job)
+		}
+	}
+	return matching, nil
+}
+
+// moveJob rewrites a queued job's target and the paths in its payload from
+// the old session folder to the new one
+func (uc *UseCase) moveJob(job *jobs.Job, oldPath, newPath string) error {
+	job.Target, _ = rebase(job.Target, oldPath, newPath)
+
+	if len(job.Payload) > 0 {
+		var payload map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(job.Payload))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err == nil {
+			for key, value := range payload {
+				if text, ok := value.(string); ok {
+					if rebased, ok := rebase(text, oldPath, newPath); ok {
+						payload[key] = rebased
+					}
+				}
+			}
+			data, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+			job.Payload = data
+		}
+	}
+	return uc.queue.Save(job)
+}
+
+// rebase replaces the dir prefix of p with newDir. ok is false when p is
+// not dir or inside it.
+func rebase(p, dir, newDir string) (string, bool) {
+	if p == dir {
+		return newDir, true
+	}
+	if rest, found := strings.CutPrefix(p, dir+string(filepath.Separator)); found {
+		return filepath.Join(newDir, rest), true
+	}
+	return p, false
+}
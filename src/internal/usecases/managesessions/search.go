@@ -0,0 +1,220 @@
+package managesessions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Score weights of the places a search word is found in
+const (
+	nameWeight        = 3
+	descriptionWeight = 5
+	tagWeight         = 4
+	docWeight         = 1
+
+	// maxDocHits caps the occurrences counted per document, so one long
+	// file can't outrank a matching description
+	maxDocHits = 10
+
+	// maxMatches is the number of matching lines shown per session
+	maxMatches = 3
+
+	// maxSnippet is the length a matching line is shortened to
+	maxSnippet = 100
+)
+
+// Match is a line of a session document containing a search word
+type Match struct {
+	File string `json:"file"` // Relative to the session folder
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Result is a session found by a search
+type Result struct {
+	Summary
+	Status  string  `json:"status"` // session.StatusActive or session.StatusArchived
+	Score   int     `json:"score"`
+	Matches []Match `json:"matches,omitempty"`
+}
+
+// document is a markdown file of a session
+type document struct {
+	path  string // Relative to the session folder
+	text  string
+	lower string
+}
+
+// Search finds the active and archived sessions matching a query. Every
+// free-text word must appear in the session's name, description, tags or
+// markdown documents, and the tag: and status: qualifiers must hold. Results
+// are ranked by where and how often the words appear, then by last use.
+func (uc *UseCase) Search(query string) ([]Result, error) {
+	q := session.ParseQuery(query)
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+	sources := []struct{ dir, status string }{
+		{uc.sessionsDir, session.StatusActive},
+		{uc.archiveDir, session.StatusArchived},
+	}
+	for _, source := range sources {
+		if q.Status != "" && q.Status != source.status {
+			continue
+		}
+		summaries, err := uc.sessionsIn(source.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range summaries {
+			if !q.Matches(s.Tags, source.status) {
+				continue
+			}
+			result, ok, err := uc.score(s, q.Words)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result.Status = source.status
+				results = append(results, result)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].lastActivity().After(results[j].lastActivity())
+	})
+	return results, nil
+}
+
+// score ranks a session against the search words. ok is false when a word
+// appears nowhere in the session.
+func (uc *UseCase) score(s Summary, words []string) (result Result, ok bool, err error) {
+	result = Result{Summary: s}
+	if len(words) == 0 {
+		return result, true, nil
+	}
+
+	docs, err := uc.documents(s.Path)
+	if err != nil {
+		return result, false, err
+	}
+
+	name, description := strings.ToLower(s.Name), strings.ToLower(s.Description)
+	for _, word := range words {
+		score := strings.Count(name, word)*nameWeight + strings.Count(description, word)*descriptionWeight
+		for _, tag := range s.Tags {
+			if strings.Contains(tag, word) {
+				score += tagWeight
+			}
+		}
+		for _, doc := range docs {
+			score += min(strings.Count(doc.lower, word), maxDocHits) * docWeight
+		}
+		if score == 0 {
+			return result, false, nil
+		}
+		result.Score += score
+	}
+
+	for _, doc := range docs {
+		for i, line := range strings.Split(doc.text, "\n") {
+			if len(result.Matches) == maxMatches {
+				return result, true, nil
+			}
+			lower := strings.ToLower(line)
+			for _, word := range words {
+				if strings.Contains(lower, word) {
+					result.Matches = append(result.Matches, Match{File: doc.path, Line: i + 1, Text: snippet(line)})
+					break
+				}
+			}
+		}
+	}
+	return result, true, nil
+}
+
+// documents reads the markdown files of a session, in path order
+func (uc *UseCase) documents(sessionPath string) ([]document, error) {
+	var docs []document
+	err := afero.Walk(uc.fs, sessionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		data, err := afero.ReadFile(uc.fs, path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(sessionPath, path)
+		text := string(data)
+		docs = append(docs, document{path: filepath.ToSlash(rel), text: text, lower: strings.ToLower(text)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documents of %s: %w", filepath.Base(sessionPath), err)
+	}
+	return docs, nil
+}
+
+// snippet trims a matching line and shortens it for display
+func snippet(line string) string {
+	line = strings.TrimSpace(line)
+	if runes := []rune(line); len(runes) > maxSnippet {
+		return string(runes[:maxSnippet-1]) + "…"
+	}
+	return line
+}
+
+// WriteSearch writes the sessions matching a query with their matching
+// lines, or a JSON array of results when asJSON is set
+func (uc *UseCase) WriteSearch(w io.Writer, query string, asJSON bool) error {
+	results, err := uc.Search(query)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return writeJSON(w, results)
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(w, "No sessions match %q.\n", query)
+		return nil
+	}
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		line := r.Name
+		if desc := firstLine(r.Description); desc != "" {
+			line += "  " + desc
+		}
+		if len(r.Tags) > 0 {
+			line += "  #" + strings.Join(r.Tags, " #")
+		}
+		if r.Status == session.StatusArchived {
+			line += "  (archived)"
+		}
+		fmt.Fprintln(w, line)
+		for _, m := range r.Matches {
+			fmt.Fprintf(w, "    %s:%d: %s\n", m.File, m.Line, m.Text)
+		}
+	}
+	return nil
+}
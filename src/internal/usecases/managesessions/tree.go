@@ -0,0 +1,201 @@
+package managesessions
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"claudex/internal/services/session"
+)
+
+// Node is a session in the fork genealogy with the sessions forked from it
+type Node struct {
+	Summary
+	Children []*Node `json:"children,omitempty"`
+
+	parentGone bool // The recorded parent no longer exists
+}
+
+// Tree returns the fork genealogy of every session: sessions without a
+// living parent are roots, oldest first. A parent replaced by a fresh
+// restart resolves to the session that replaced it.
+func (uc *UseCase) Tree() ([]*Node, error) {
+	summaries, err := uc.Sessions()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*Node, len(summaries))
+	successors := map[string]string{}
+	for _, s := range summaries {
+		nodes[s.Name] = &Node{Summary: s}
+		for _, event := range s.Lineage {
+			if event.Op == session.OpFresh {
+				successors[event.From] = s.Name
+			}
+		}
+	}
+
+	parents := map[string]string{}
+	for name, node := range nodes {
+		parent := resolveParent(node.Parent, nodes, successors)
+		node.parentGone = node.Parent != "" && parent == ""
+		if parent != "" && parent != name {
+			parents[name] = parent
+		}
+	}
+	breakCycles(parents)
+
+	var roots []*Node
+	for _, s := range summaries {
+		node := nodes[s.Name]
+		if parent, ok := parents[s.Name]; ok {
+			nodes[parent].Children = append(nodes[parent].Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	sortNodes(roots)
+	return roots, nil
+}
+
+// resolveParent follows fresh restarts from a recorded parent name to the
+// session that exists now. Returns "" when the parent is gone.
+func resolveParent(name string, nodes map[string]*Node, successors map[string]string) string {
+	for seen := map[string]bool{}; name != "" && !seen[name]; {
+		if _, ok := nodes[name]; ok {
+			return name
+		}
+		seen[name] = true
+		name = successors[name]
+	}
+	return ""
+}
+
+// breakCycles drops the parent link of one session in every cycle, which only
+// hand-edited or renamed sessions can produce
+func breakCycles(parents map[string]string) {
+	names := make([]string, 0, len(parents))
+	for name := range parents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		seen := map[string]bool{name: true}
+		for current, ok := parents[name]; ok && !seen[current]; current, ok = parents[current] {
+			seen[current] = true
+			if parents[current] == name {
+				delete(parents, name) // name is in a cycle; cut it here
+				break
+			}
+		}
+	}
+}
+
+// sortNodes orders sessions by creation, oldest first, at every level
+func sortNodes(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Created != nodes[j].Created {
+			return nodes[i].Created < nodes[j].Created
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, node := range nodes {
+		sortNodes(node.Children)
+	}
+}
+
+// WriteTree writes the genealogy, or a JSON array of nested nodes when asJSON
+// is set. With a name, only the tree holding that session is written.
+func (uc *UseCase) WriteTree(w io.Writer, name string, asJSON bool) error {
+	roots, err := uc.Tree()
+	if err != nil {
+		return err
+	}
+
+	if name != "" {
+		sessionName, err := uc.Find(name)
+		if err != nil {
+			return err
+		}
+		for _, root := range roots {
+			if root.contains(sessionName) {
+				roots = []*Node{root}
+				break
+			}
+		}
+	}
+
+	if asJSON {
+		if roots == nil {
+			roots = []*Node{}
+		}
+		return writeJSON(w, roots)
+	}
+
+	if len(roots) == 0 {
+		fmt.Fprintln(w, "No sessions yet. Create one with: claudex session new \"<description>\"")
+		return nil
+	}
+	for _, root := range roots {
+		writeNode(w, root, "", "")
+	}
+	return nil
+}
+
+// contains reports whether the named session is this node or below it
+func (n *Node) contains(name string) bool {
+	if n.Name == name {
+		return true
+	}
+	for _, child := range n.Children {
+		if child.contains(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNode writes a session line and its children with box-drawing
+// connectors. prefix is written before the session, indent before its children.
+func writeNode(w io.Writer, n *Node, prefix, indent string) {
+	line := n.Name
+	if desc := firstLine(n.Description); desc != "" {
+		line += "  " + desc
+	}
+	if notes := n.lineageNotes(); len(notes) > 0 {
+		line += "  (" + strings.Join(notes, ", ") + ")"
+	}
+	fmt.Fprintln(w, prefix+line)
+
+	for i, child := range n.Children {
+		if i == len(n.Children)-1 {
+			writeNode(w, child, indent+"└── ", indent+"    ")
+		} else {
+			writeNode(w, child, indent+"├── ", indent+"│   ")
+		}
+	}
+}
+
+// lineageNotes describes what the tree layout doesn't show: fresh restarts
+// and a fork parent that no longer exists
+func (n *Node) lineageNotes() []string {
+	var notes []string
+	if n.parentGone {
+		notes = append(notes, "forked from "+n.Parent+", removed")
+	}
+	restarts := 0
+	for _, event := range n.Lineage {
+		if event.Op == session.OpFresh {
+			restarts++
+		}
+	}
+	if restarts == 1 {
+		notes = append(notes, "fresh restart")
+	} else if restarts > 1 {
+		notes = append(notes, fmt.Sprintf("%d fresh restarts", restarts))
+	}
+	return notes
+}
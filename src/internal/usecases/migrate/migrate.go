@@ -12,26 +12,38 @@ import (
 	"github.com/spf13/afero"
 
 	"claudex/internal/services/paths"
+	"claudex/internal/services/session"
 )
 
 const defaultConfigContent = `# Claudex Configuration
 # See documentation for all available options
-
-[features]
-autodoc_session_progress = true
-autodoc_session_end = true
-autodoc_frequency = 5
+#
+# Keys left unset here fall back to ~/.config/claudex/config.toml, then to
+# the built-in defaults shown below. Run "claudex config explain" to see
+# where each value comes from.
+
+# [features]
+# autodoc_session_progress = true
+# autodoc_session_end = true
+# autodoc_frequency = 5
 `
 
 // Migrator handles migration of legacy Claudex artifacts and initialization
 // of the .claudex/ directory structure.
 type Migrator struct {
-	fs afero.Fs
+	fs         afero.Fs
+	projectDir string
+}
+
+// New creates a new Migrator instance for the project rooted at projectDir
+// (see paths.FindProjectRoot).
+func New(fs afero.Fs, projectDir string) *Migrator {
+	return &Migrator{fs: fs, projectDir: projectDir}
 }
 
-// New creates a new Migrator instance with the provided filesystem.
-func New(fs afero.Fs) *Migrator {
-	return &Migrator{fs: fs}
+// path resolves a path constant against the project root
+func (m *Migrator) path(rel string) string {
+	return filepath.Join(m.projectDir, rel)
 }
 
 // Run executes the migration process:
@@ -40,6 +52,7 @@ func New(fs afero.Fs) *Migrator {
 // 3. Migrates legacy sessions/ directory if it exists
 // 4. Migrates legacy logs/ directory if it exists
 // 5. Migrates legacy .claudex.toml config if it exists (overwrites default)
+// 6. Converts each session's legacy metadata dotfiles to session.json
 //
 // Returns error only on critical failures. Non-critical issues are logged as warnings.
 // This operation is idempotent and safe to run multiple times.
@@ -54,27 +67,28 @@ func (m *Migrator) Run() error {
 		return fmt.Errorf("failed to create default config: %w", err)
 	}
 
-	// Step 3-5: Migrate legacy artifacts
+	// Step 3-6: Migrate legacy artifacts
 	// These are non-critical - we log warnings but don't fail the migration
 	m.migrateLegacySessions()
 	m.migrateLegacyLogs()
 	m.migrateLegacyConfig()
+	m.migrateSessionMetadata()
 
 	return nil
 }
 
 // ensureClaudexDir creates the .claudex/ directory if it doesn't exist.
 func (m *Migrator) ensureClaudexDir() error {
-	exists, err := afero.DirExists(m.fs, paths.ClaudexDir)
+	exists, err := afero.DirExists(m.fs, m.path(paths.ClaudexDir))
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		if err := m.fs.MkdirAll(paths.ClaudexDir, 0755); err != nil {
+		if err := m.fs.MkdirAll(m.path(paths.ClaudexDir), 0755); err != nil {
 			return err
 		}
-		log.Printf("Created %s directory", paths.ClaudexDir)
+		log.Printf("Created %s directory", m.path(paths.ClaudexDir))
 	}
 
 	return nil
@@ -83,16 +97,16 @@ func (m *Migrator) ensureClaudexDir() error {
 // ensureDefaultConfig creates config.toml with default values if it doesn't exist.
 // If the file already exists, it does nothing (preserves user configuration).
 func (m *Migrator) ensureDefaultConfig() error {
-	exists, err := afero.Exists(m.fs, paths.ConfigFile)
+	exists, err := afero.Exists(m.fs, m.path(paths.ConfigFile))
 	if err != nil {
 		return err
 	}
 
 	if !exists {
-		if err := afero.WriteFile(m.fs, paths.ConfigFile, []byte(defaultConfigContent), 0644); err != nil {
+		if err := afero.WriteFile(m.fs, m.path(paths.ConfigFile), []byte(defaultConfigContent), 0644); err != nil {
 			return err
 		}
-		log.Printf("Created default config at %s", paths.ConfigFile)
+		log.Printf("Created default config at %s", m.path(paths.ConfigFile))
 	}
 
 	return nil
@@ -100,14 +114,14 @@ func (m *Migrator) ensureDefaultConfig() error {
 
 // migrateLegacySessions migrates the legacy sessions/ directory to .claudex/sessions/
 func (m *Migrator) migrateLegacySessions() {
-	if err := m.migrateDirectory(paths.LegacySessionsDir, paths.SessionsDir); err != nil {
+	if err := m.migrateDirectory(m.path(paths.LegacySessionsDir), m.path(paths.SessionsDir)); err != nil {
 		log.Printf("Warning: Failed to migrate legacy sessions: %v", err)
 	}
 }
 
 // migrateLegacyLogs migrates the legacy logs/ directory to .claudex/logs/
 func (m *Migrator) migrateLegacyLogs() {
-	if err := m.migrateDirectory(paths.LegacyLogsDir, paths.LogsDir); err != nil {
+	if err := m.migrateDirectory(m.path(paths.LegacyLogsDir), m.path(paths.LogsDir)); err != nil {
 		log.Printf("Warning: Failed to migrate legacy logs: %v", err)
 	}
 }
@@ -115,7 +129,7 @@ func (m *Migrator) migrateLegacyLogs() {
 // migrateLegacyConfig migrates the legacy .claudex.toml to .claudex/config.toml
 // This overwrites the default config if a legacy config exists.
 func (m *Migrator) migrateLegacyConfig() {
-	exists, err := afero.Exists(m.fs, paths.LegacyConfigFile)
+	exists, err := afero.Exists(m.fs, m.path(paths.LegacyConfigFile))
 	if err != nil {
 		log.Printf("Warning: Failed to check for legacy config: %v", err)
 		return
@@ -126,25 +140,49 @@ func (m *Migrator) migrateLegacyConfig() {
 	}
 
 	// Read legacy config
-	content, err := afero.ReadFile(m.fs, paths.LegacyConfigFile)
+	content, err := afero.ReadFile(m.fs, m.path(paths.LegacyConfigFile))
 	if err != nil {
 		log.Printf("Warning: Failed to read legacy config: %v", err)
 		return
 	}
 
 	// Write to new location (overwrites default)
-	if err := afero.WriteFile(m.fs, paths.ConfigFile, content, 0644); err != nil {
+	if err := afero.WriteFile(m.fs, m.path(paths.ConfigFile), content, 0644); err != nil {
 		log.Printf("Warning: Failed to migrate legacy config: %v", err)
 		return
 	}
 
 	// Remove legacy config file
-	if err := m.fs.Remove(paths.LegacyConfigFile); err != nil {
+	if err := m.fs.Remove(m.path(paths.LegacyConfigFile)); err != nil {
 		log.Printf("Warning: Failed to remove legacy config file: %v", err)
 		return
 	}
 
-	log.Printf("Migrated legacy config from %s to %s", paths.LegacyConfigFile, paths.ConfigFile)
+	log.Printf("Migrated legacy config from %s to %s", m.path(paths.LegacyConfigFile), m.path(paths.ConfigFile))
+}
+
+// migrateSessionMetadata converts the per-value dotfiles of every session to
+// session.json. Sessions that already have one are left alone.
+func (m *Migrator) migrateSessionMetadata() {
+	entries, err := afero.ReadDir(m.fs, m.path(paths.SessionsDir))
+	if err != nil {
+		return // No sessions yet
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		sessionPath := filepath.Join(m.path(paths.SessionsDir), entry.Name())
+		migrated, err := session.MigrateMetadata(m.fs, sessionPath)
+		if err != nil {
+			log.Printf("Warning: Failed to migrate metadata of session %s: %v", entry.Name(), err)
+			continue
+		}
+		if migrated {
+			log.Printf("Migrated metadata of session %s to %s", entry.Name(), session.MetadataFile)
+		}
+	}
 }
 
 // migrateDirectory moves a directory from source to destination atomically.
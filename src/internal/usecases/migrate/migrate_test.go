@@ -8,11 +8,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"claudex/internal/services/paths"
+	"claudex/internal/services/session"
 )
 
 func TestMigrator_Run_FreshInstallation(t *testing.T) {
 	fs := afero.NewMemMapFs()
-	migrator := New(fs)
+	migrator := New(fs, ".")
 
 	err := migrator.Run()
 	require.NoError(t, err)
@@ -37,7 +38,7 @@ func TestMigrator_Run_FreshInstallation(t *testing.T) {
 
 func TestMigrator_Run_IdempotentOperation(t *testing.T) {
 	fs := afero.NewMemMapFs()
-	migrator := New(fs)
+	migrator := New(fs, ".")
 
 	// Run migration twice
 	err := migrator.Run()
@@ -68,7 +69,7 @@ func TestMigrator_Run_MigrateLegacySessions(t *testing.T) {
 	err = afero.WriteFile(fs, sessionFile, []byte(`{"id": "session-1"}`), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -99,7 +100,7 @@ func TestMigrator_Run_MigrateLegacyLogs(t *testing.T) {
 	err = afero.WriteFile(fs, logFile, []byte("log entry"), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -131,7 +132,7 @@ autodoc_frequency = 10
 	err := afero.WriteFile(fs, paths.LegacyConfigFile, []byte(legacyConfigContent), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -169,7 +170,7 @@ func TestMigrator_Run_CompleteSetup(t *testing.T) {
 	err = afero.WriteFile(fs, paths.LegacyConfigFile, []byte("legacy config"), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -213,7 +214,7 @@ func TestMigrator_Run_NestedDirectoryMigration(t *testing.T) {
 	err = afero.WriteFile(fs, paths.LegacySessionsDir+"/2024/01/session.json", []byte("nested"), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -232,7 +233,7 @@ func TestMigrator_Run_PreserveFilePermissions(t *testing.T) {
 	err = afero.WriteFile(fs, paths.LegacySessionsDir+"/session.json", []byte("content"), 0600)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -256,7 +257,7 @@ func TestMigrator_Run_SkipIfDestinationExists(t *testing.T) {
 	err = afero.WriteFile(fs, paths.SessionsDir+"/existing.json", []byte("existing"), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -289,7 +290,7 @@ autodoc_session_progress = false
 	err = afero.WriteFile(fs, paths.ConfigFile, []byte(customConfig), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -331,7 +332,7 @@ func TestRun_MigrateLegacySessions_WithNestedContent(t *testing.T) {
 	}
 
 	// Run migration
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err := migrator.Run()
 	require.NoError(t, err)
 
@@ -375,7 +376,7 @@ func TestRun_MigrateLegacyLogs_WithMultipleFiles(t *testing.T) {
 	}
 
 	// Run migration
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -430,7 +431,7 @@ custom_key = "custom_value"
 	require.NoError(t, err)
 
 	// Run migration
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -460,7 +461,7 @@ func TestRun_PartialLegacyArtifacts(t *testing.T) {
 	require.NoError(t, err)
 
 	// Run migration
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -511,7 +512,7 @@ func TestRun_DestinationAlreadyExists_Sessions(t *testing.T) {
 	require.NoError(t, err)
 
 	// Run migration
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -564,7 +565,7 @@ autodoc_frequency = 10
 	require.NoError(t, err)
 
 	// Run migration
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -605,7 +606,7 @@ func TestCopyAndRemoveDirectory(t *testing.T) {
 	}
 
 	// Test copyAndRemoveDirectory directly
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.copyAndRemoveDirectory(sourceDir, destDir)
 	require.NoError(t, err)
 
@@ -634,7 +635,7 @@ func TestCopyAndRemoveDirectory_EmptyDirectory(t *testing.T) {
 	err := fs.MkdirAll(sourceDir, 0755)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.copyAndRemoveDirectory(sourceDir, destDir)
 	require.NoError(t, err)
 
@@ -683,7 +684,7 @@ func TestCopyAndRemoveDirectory_PreservesDirectoryStructure(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err := migrator.copyAndRemoveDirectory(sourceDir, destDir)
 	require.NoError(t, err)
 
@@ -713,7 +714,7 @@ func TestRun_MigrateLegacySessions_ErrorLogging(t *testing.T) {
 	err = fs.MkdirAll(paths.SessionsDir, 0755)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err) // Should not fail even if migration is skipped
 
@@ -738,7 +739,7 @@ func TestRun_MigrateLegacyLogs_ErrorLogging(t *testing.T) {
 	err = fs.MkdirAll(paths.LogsDir, 0755)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err) // Should not fail even if migration is skipped
 
@@ -757,7 +758,7 @@ func TestRun_MigrateLegacyLogs_ErrorLogging(t *testing.T) {
 func TestRun_MigrateLegacyConfig_NoLegacyConfig(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err := migrator.Run()
 	require.NoError(t, err)
 
@@ -776,7 +777,7 @@ func TestRun_MigrateLegacyConfig_NoLegacyConfig(t *testing.T) {
 func TestMigrateDirectory_SourceDoesNotExist(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err := migrator.migrateDirectory("nonexistent_source", "dest")
 	require.NoError(t, err) // Should succeed (no-op)
 
@@ -807,7 +808,7 @@ func TestRun_AllLegacyArtifactsWithLogs(t *testing.T) {
 	err = afero.WriteFile(fs, paths.LegacyConfigFile, []byte("legacy config"), 0644)
 	require.NoError(t, err)
 
-	migrator := New(fs)
+	migrator := New(fs, ".")
 	err = migrator.Run()
 	require.NoError(t, err)
 
@@ -838,3 +839,65 @@ func TestRun_AllLegacyArtifactsWithLogs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "legacy config", string(configContent))
 }
+
+func TestMigrator_Run_UsesProjectRoot(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/repo/sessions/old/session.json", []byte("session"), 0644))
+	migrator := New(fs, "/repo")
+
+	err := migrator.Run()
+	require.NoError(t, err)
+
+	// Everything lands under the project root, not the working directory
+	exists, _ := afero.Exists(fs, "/repo/.claudex/config.toml")
+	assert.True(t, exists, "config.toml should be created under the project root")
+	exists, _ = afero.Exists(fs, "/repo/.claudex/sessions/old/session.json")
+	assert.True(t, exists, "legacy sessions should move under the project root")
+	exists, _ = afero.DirExists(fs, paths.ClaudexDir)
+	assert.False(t, exists, "no .claudex should be created relative to the working directory")
+}
+
+// TestRun_MigratesSessionMetadata tests legacy session dotfiles, including
+// those of sessions moved from the legacy sessions/ directory, end up in
+// session.json
+func TestRun_MigratesSessionMetadata(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	files := map[string]string{
+		paths.LegacySessionsDir + "/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/.description": "Add auth",
+		paths.LegacySessionsDir + "/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/.created":     "2024-01-15T10:30:00Z",
+		paths.LegacySessionsDir + "/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee/.agent":       "architect",
+		paths.LegacySessionsDir + "/notes/todo.md":                                          "- nothing",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+
+	migrator := New(fs, ".")
+	require.NoError(t, migrator.Run())
+
+	sessionPath := paths.SessionsDir + "/auth-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	metadata, err := session.ReadMetadata(fs, sessionPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Add auth", metadata.Description)
+	assert.Equal(t, "2024-01-15T10:30:00Z", metadata.Created)
+	assert.Equal(t, "architect", metadata.Agent)
+	assert.Equal(t, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", metadata.ClaudeSessionID)
+
+	descExists, err := afero.Exists(fs, sessionPath+"/.description")
+	require.NoError(t, err)
+	assert.False(t, descExists, "legacy dotfiles should be removed")
+
+	// Folders without dotfiles are left alone
+	notesMetadata, err := afero.Exists(fs, paths.SessionsDir+"/notes/"+session.MetadataFile)
+	require.NoError(t, err)
+	assert.False(t, notesMetadata)
+
+	// A second run changes nothing
+	before, err := afero.ReadFile(fs, sessionPath+"/"+session.MetadataFile)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Run())
+	after, err := afero.ReadFile(fs, sessionPath+"/"+session.MetadataFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
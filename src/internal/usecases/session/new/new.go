@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"claudex/internal/services/clock"
-	"claudex/internal/services/commander"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/session"
 	"claudex/internal/services/uuid"
 
@@ -20,29 +20,42 @@ import (
 // UseCase handles the creation of new sessions
 type UseCase struct {
 	fs          afero.Fs
-	cmd         commander.Commander
+	backend     llm.Backend
+	model       string
 	uuidGen     uuid.UUIDGenerator
 	clock       clock.Clock
 	sessionsDir string
 }
 
-// New creates a new session creation use case
-func New(fs afero.Fs, cmd commander.Commander, uuidGen uuid.UUIDGenerator, clk clock.Clock, sessionsDir string) *UseCase {
+// New creates a new session creation use case.
+// model selects the model used for session naming; empty uses the backend default.
+func New(fs afero.Fs, backend llm.Backend, model string, uuidGen uuid.UUIDGenerator, clk clock.Clock, sessionsDir string) *UseCase {
 	return &UseCase{
 		fs:          fs,
-		cmd:         cmd,
+		backend:     backend,
+		model:       model,
 		uuidGen:     uuidGen,
 		clock:       clk,
 		sessionsDir: sessionsDir,
 	}
 }
 
+// Options are the choices recorded with a new session. Empty fields keep the
+// defaults and write no file.
+type Options struct {
+	Agent    string   // Entry agent activated on launch
+	Template string   // Activation prompt template
+	Branch   string   // Git branch the session starts on
+	Tags     []string // Normalized session tags
+}
+
 // Execute creates a new session by:
 // 1. Generating a UUID for the session
-// 2. Generating session name from description (via Claude CLI or manual slug)
-// 3. Creating session directory with metadata files
+// 2. Generating session name from description (via the LLM backend or manual slug)
+// 3. Creating session directory with its session.json, including the
+// options that were set
 // 4. Returning session info for launching Claude
-func (uc *UseCase) Execute(description string) (sessionName, sessionPath, claudeSessionID string, err error) {
+func (uc *UseCase) Execute(description string, opts Options) (sessionName, sessionPath, claudeSessionID string, err error) {
 	description = strings.TrimSpace(description)
 	if description == "" {
 		return "", "", "", fmt.Errorf("description cannot be empty")
@@ -51,8 +64,8 @@ func (uc *UseCase) Execute(description string) (sessionName, sessionPath, claude
 	// Generate UUID for the session upfront
 	claudeSessionID = uc.uuidGen.New()
 
-	// Generate session name using Claude CLI or fallback to manual slug
-	baseSessionName, err := session.GenerateNameWithCmd(uc.cmd, description)
+	// Generate session name using the LLM backend or fallback to manual slug
+	baseSessionName, err := session.GenerateName(uc.backend, uc.model, description)
 	if err != nil {
 		baseSessionName = session.CreateManualSlug(description)
 	}
@@ -78,14 +91,18 @@ func (uc *UseCase) Execute(description string) (sessionName, sessionPath, claude
 		return "", "", "", err
 	}
 
-	// Write description file
-	if err := afero.WriteFile(uc.fs, filepath.Join(sessionPath, ".description"), []byte(description), 0644); err != nil {
-		return "", "", "", err
-	}
-
-	// Write created timestamp
+	// Write session.json
 	created := uc.clock.Now().UTC().Format(time.RFC3339)
-	if err := afero.WriteFile(uc.fs, filepath.Join(sessionPath, ".created"), []byte(created), 0644); err != nil {
+	metadata := &session.SessionMetadata{
+		ClaudeSessionID: claudeSessionID,
+		Description:     description,
+		Created:         created,
+		Agent:           opts.Agent,
+		Template:        opts.Template,
+		Branch:          opts.Branch,
+		Tags:            opts.Tags,
+	}
+	if err := session.WriteMetadata(uc.fs, sessionPath, metadata); err != nil {
 		return "", "", "", err
 	}
 
@@ -6,13 +6,15 @@ import (
 	"testing"
 	"time"
 
+	"claudex/internal/services/llm"
+	"claudex/internal/services/session"
 	"claudex/internal/testutil"
 
 	"github.com/stretchr/testify/require"
 )
 
 // Test_Execute_CreatesSessionWithMetadata tests basic session creation workflow
-// Creates session directory with .description and .created files
+// Creates session directory with a session.json holding its metadata
 func Test_Execute_CreatesSessionWithMetadata(t *testing.T) {
 	// Setup
 	h := testutil.NewTestHarness()
@@ -25,8 +27,8 @@ func Test_Execute_CreatesSessionWithMetadata(t *testing.T) {
 	h.UUIDs = []string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	sessionName, sessionPath, claudeSessionID, err := uc.Execute("Add user authentication")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	sessionName, sessionPath, claudeSessionID, err := uc.Execute("Add user authentication", Options{Tags: []string{"auth"}})
 
 	// Verify success
 	require.NoError(t, err)
@@ -37,13 +39,19 @@ func Test_Execute_CreatesSessionWithMetadata(t *testing.T) {
 	// Verify directory created
 	testutil.AssertDirExists(t, h.FS, sessionPath)
 
-	// Verify .description file
-	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, ".description"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, ".description"), "Add user authentication")
-
-	// Verify .created file with timestamp
-	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, ".created"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(sessionPath, ".created"), "2024-01-15T10:30:00Z")
+	// Verify session.json
+	testutil.AssertFileExists(t, h.FS, filepath.Join(sessionPath, session.MetadataFile))
+	metadata, err := session.ReadMetadata(h.FS, sessionPath)
+	require.NoError(t, err)
+	require.Equal(t, session.MetadataVersion, metadata.Version)
+	require.Equal(t, "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee", metadata.ClaudeSessionID)
+	require.Equal(t, "Add user authentication", metadata.Description)
+	require.Equal(t, "2024-01-15T10:30:00Z", metadata.Created)
+	require.Equal(t, []string{"auth"}, metadata.Tags)
+
+	// No legacy dotfiles
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(sessionPath, ".description"))
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(sessionPath, ".created"))
 }
 
 // Test_Execute_FallsBackToManualSlug tests fallback when Claude CLI fails
@@ -59,8 +67,8 @@ func Test_Execute_FallsBackToManualSlug(t *testing.T) {
 	h.UUIDs = []string{"11111111-2222-3333-4444-555555555555"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	sessionName, sessionPath, _, err := uc.Execute("Fix login bug in dashboard")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	sessionName, sessionPath, _, err := uc.Execute("Fix login bug in dashboard", Options{})
 
 	// Verify success with manual slug fallback
 	require.NoError(t, err)
@@ -88,8 +96,8 @@ func Test_Execute_HandlesCollision(t *testing.T) {
 	h.UUIDs = []string{"aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	sessionName, sessionPath, _, err := uc.Execute("My task description")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	sessionName, sessionPath, _, err := uc.Execute("My task description", Options{})
 
 	// Verify collision handling - should append counter
 	require.NoError(t, err)
@@ -108,15 +116,15 @@ func Test_Execute_RejectsEmptyDescription(t *testing.T) {
 	sessionsDir := "/project/sessions"
 	h.CreateDir(sessionsDir)
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
 
 	// Test empty string
-	_, _, _, err := uc.Execute("")
+	_, _, _, err := uc.Execute("", Options{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "description cannot be empty")
 
 	// Test whitespace only
-	_, _, _, err = uc.Execute("   ")
+	_, _, _, err = uc.Execute("   ", Options{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "description cannot be empty")
 }
@@ -136,15 +144,15 @@ func Test_Execute_GeneratesUniqueUUID(t *testing.T) {
 		"uuid-2222-2222-2222-222222222222",
 	}
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
 
 	// Create first session
-	_, _, uuid1, err := uc.Execute("First task")
+	_, _, uuid1, err := uc.Execute("First task", Options{})
 	require.NoError(t, err)
 	require.Equal(t, "uuid-1111-1111-1111-111111111111", uuid1)
 
 	// Create second session
-	_, _, uuid2, err := uc.Execute("Second task")
+	_, _, uuid2, err := uc.Execute("Second task", Options{})
 	require.NoError(t, err)
 	require.Equal(t, "uuid-2222-2222-2222-222222222222", uuid2)
 
@@ -164,8 +172,8 @@ func Test_Execute_InvokesClaudeCLI(t *testing.T) {
 	h.UUIDs = []string{"test-uuid"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	_, _, _, err := uc.Execute("My description for testing")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	_, _, _, err := uc.Execute("My description for testing", Options{})
 
 	// Verify Claude CLI was invoked
 	require.NoError(t, err)
@@ -187,8 +195,8 @@ func Test_Execute_CreatesSessionsDirectory(t *testing.T) {
 	h.UUIDs = []string{"test-uuid"}
 
 	// Create usecase and execute
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	_, sessionPath, _, err := uc.Execute("New feature description")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	_, sessionPath, _, err := uc.Execute("New feature description", Options{})
 
 	// Should succeed and create the directory structure
 	require.NoError(t, err)
@@ -207,8 +215,8 @@ func Test_Execute_HandlesSpecialCharactersInDescription(t *testing.T) {
 	h.Commander.OnPattern("claude").Return(nil, fmt.Errorf("unavailable"))
 	h.UUIDs = []string{"test-uuid"}
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	sessionName, _, _, err := uc.Execute("Fix bug #123 (urgent!)")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	sessionName, _, _, err := uc.Execute("Fix bug #123 (urgent!)", Options{})
 
 	// Verify slug is sanitized (manual fallback)
 	require.NoError(t, err)
@@ -220,7 +228,7 @@ func Test_Execute_HandlesSpecialCharactersInDescription(t *testing.T) {
 }
 
 // Test_Execute_SetsCorrectFilePermissions tests metadata file permissions
-// .description and .created should have 0644 permissions
+// session.json should have 0644 permissions
 func Test_Execute_SetsCorrectFilePermissions(t *testing.T) {
 	// Setup
 	h := testutil.NewTestHarness()
@@ -230,18 +238,43 @@ func Test_Execute_SetsCorrectFilePermissions(t *testing.T) {
 	h.Commander.OnPattern("claude", "-p").Return([]byte("test-task"), nil)
 	h.UUIDs = []string{"test-uuid"}
 
-	uc := New(h.FS, h.Commander, h, h, sessionsDir)
-	_, sessionPath, _, err := uc.Execute("Test task")
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	_, sessionPath, _, err := uc.Execute("Test task", Options{})
+
+	require.NoError(t, err)
+
+	// Check session.json permissions
+	info, err := h.FS.Stat(filepath.Join(sessionPath, session.MetadataFile))
+	require.NoError(t, err)
+	require.Equal(t, "-rw-r--r--", info.Mode().String())
+}
+
+// Test_Execute_RecordsEntryAgent tests that a chosen entry agent and template
+// are persisted and that the defaults leave them empty
+func Test_Execute_RecordsEntryAgent(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	sessionsDir := "/project/sessions"
+	h.CreateDir(sessionsDir)
+
+	h.Commander.OnPattern("claude", "-p").Return([]byte("design-api"), nil)
+	h.UUIDs = []string{"agent-uuid", "default-uuid"}
 
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
+	_, sessionPath, _, err := uc.Execute("Design the public API", Options{Agent: "architect", Template: "feature", Branch: "feat/api"})
 	require.NoError(t, err)
 
-	// Check .description permissions
-	descInfo, err := h.FS.Stat(filepath.Join(sessionPath, ".description"))
+	metadata, err := session.ReadMetadata(h.FS, sessionPath)
 	require.NoError(t, err)
-	require.Equal(t, "-rw-r--r--", descInfo.Mode().String())
+	require.Equal(t, "architect", metadata.Agent)
+	require.Equal(t, "feature", metadata.Template)
+	require.Equal(t, "feat/api", metadata.Branch)
 
-	// Check .created permissions
-	createdInfo, err := h.FS.Stat(filepath.Join(sessionPath, ".created"))
+	_, defaultPath, _, err := uc.Execute("Another task", Options{})
+	require.NoError(t, err)
+	metadata, err = session.ReadMetadata(h.FS, defaultPath)
 	require.NoError(t, err)
-	require.Equal(t, "-rw-r--r--", createdInfo.Mode().String())
+	require.Empty(t, metadata.Agent)
+	require.Empty(t, metadata.Template)
+	require.Empty(t, metadata.Branch)
 }
@@ -6,9 +6,11 @@ package fork
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
-	"claudex/internal/services/commander"
+	"claudex/internal/services/clock"
 	"claudex/internal/services/filesystem"
+	"claudex/internal/services/llm"
 	"claudex/internal/services/session"
 	"claudex/internal/services/uuid"
 
@@ -18,35 +20,41 @@ import (
 // UseCase handles forking of existing sessions
 type UseCase struct {
 	fs          afero.Fs
-	cmd         commander.Commander
+	backend     llm.Backend
+	model       string
 	uuidGen     uuid.UUIDGenerator
+	clock       clock.Clock
 	sessionsDir string
 }
 
-// New creates a new fork use case
-func New(fs afero.Fs, cmd commander.Commander, uuidGen uuid.UUIDGenerator, sessionsDir string) *UseCase {
+// New creates a new fork use case.
+// model selects the model used for session naming; empty uses the backend default.
+func New(fs afero.Fs, backend llm.Backend, model string, uuidGen uuid.UUIDGenerator, clk clock.Clock, sessionsDir string) *UseCase {
 	return &UseCase{
 		fs:          fs,
-		cmd:         cmd,
+		backend:     backend,
+		model:       model,
 		uuidGen:     uuidGen,
+		clock:       clk,
 		sessionsDir: sessionsDir,
 	}
 }
 
 // Execute forks a session with a new description by:
 // 1. Generating a new UUID for the forked session
-// 2. Generating a new session name from the description (via Claude CLI or manual slug)
+// 2. Generating a new session name from the description (via the LLM backend or manual slug)
 // 3. Copying the session directory
-// 4. Updating the .description file with the new description
+// 4. Updating session.json with the new description, ID and creation time,
+// and recording the original as parent in a new lineage
 // 5. Returning the new session info
 func (uc *UseCase) Execute(originalSessionName, description string) (sessionName, sessionPath, claudeSessionID string, err error) {
 	// Generate new UUID for the forked session
 	claudeSessionID = uc.uuidGen.New()
 
 	// Generate new session name from description (like new session creation)
-	baseSessionName, err := session.GenerateNameWithCmd(uc.cmd, description)
+	baseSessionName, err := session.GenerateName(uc.backend, uc.model, description)
 	if err != nil {
-		// Fallback to manual slug if Claude API fails
+		// Fallback to manual slug if the LLM backend fails
 		baseSessionName = session.CreateManualSlug(description)
 	}
 
@@ -60,9 +68,16 @@ func (uc *UseCase) Execute(originalSessionName, description string) (sessionName
 		return "", "", "", fmt.Errorf("failed to copy session directory: %w", err)
 	}
 
-	// Update .description file with new description
-	descPath := filepath.Join(sessionPath, ".description")
-	if err := afero.WriteFile(uc.fs, descPath, []byte(description), 0644); err != nil {
+	// Record the new description and ID, and where the fork came from
+	now := uc.clock.Now().UTC().Format(time.RFC3339)
+	err = session.UpdateMetadata(uc.fs, sessionPath, func(m *session.SessionMetadata) {
+		m.ClaudeSessionID = claudeSessionID
+		m.Description = description
+		m.Created = now
+		m.Parent = originalSessionName
+		m.Lineage = []session.LineageEvent{{Op: session.OpFork, From: originalSessionName, At: now}}
+	})
+	if err != nil {
 		return "", "", "", fmt.Errorf("failed to write Description: %w", err)
 	}
 
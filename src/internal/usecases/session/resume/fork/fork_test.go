@@ -3,7 +3,10 @@ package fork
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
+	"claudex/internal/services/llm"
+	"claudex/internal/services/session"
 	"claudex/internal/testutil"
 
 	"github.com/stretchr/testify/require"
@@ -13,6 +16,7 @@ import (
 func Test_Execute_CopiesDirectoryAndCreatesNewSession(t *testing.T) {
 	// Setup
 	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
 	originalSessionName := "login-feature-12345678-abcd-ef12-3456-7890abcdef12"
 	sessionsDir := "/project/sessions"
 
@@ -21,6 +25,7 @@ func Test_Execute_CopiesDirectoryAndCreatesNewSession(t *testing.T) {
 	h.CreateSessionWithFiles(originalSessionPath, map[string]string{
 		".description":       "Original login",
 		".created":           "2024-01-10T10:00:00Z",
+		".agent":             "researcher",
 		"session-history.md": "# History\n...",
 		"execution-plan.md":  "# Plan\n...",
 	})
@@ -30,7 +35,7 @@ func Test_Execute_CopiesDirectoryAndCreatesNewSession(t *testing.T) {
 	h.UUIDs = []string{"new-uuid-aaaa-bbbb-cccc-dddd-eeeeeeeeeeee"}
 
 	// Create usecase and exercise
-	uc := New(h.FS, h.Commander, h, sessionsDir)
+	uc := New(h.FS, llm.NewClaudeCLI(h.Commander, h.Env, ""), "", h, h, sessionsDir)
 	newSessionName, newSessionPath, claudeSessionID, err := uc.Execute(
 		originalSessionName, "Refactor to OAuth",
 	)
@@ -51,9 +56,21 @@ func Test_Execute_CopiesDirectoryAndCreatesNewSession(t *testing.T) {
 	testutil.AssertFileExists(t, h.FS, filepath.Join(newSessionPath, "execution-plan.md"))
 	testutil.AssertFileContains(t, h.FS, filepath.Join(newSessionPath, "session-history.md"), "# History")
 
-	// Description updated to new description
-	testutil.AssertFileExists(t, h.FS, filepath.Join(newSessionPath, ".description"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(newSessionPath, ".description"), "Refactor to OAuth")
+	// Metadata moved to session.json with the new description, ID and parent
+	testutil.AssertFileExists(t, h.FS, filepath.Join(newSessionPath, session.MetadataFile))
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".description"))
+	metadata, err := session.ReadMetadata(h.FS, newSessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "Refactor to OAuth", metadata.Description)
+	require.Equal(t, "new-uuid-aaaa-bbbb-cccc-dddd-eeeeeeeeeeee", metadata.ClaudeSessionID)
+	require.Equal(t, originalSessionName, metadata.Parent)
+	require.Equal(t, []session.LineageEvent{
+		{Op: session.OpFork, From: originalSessionName, At: "2024-01-15T10:30:00Z"},
+	}, metadata.Lineage)
+	require.Equal(t, "2024-01-15T10:30:00Z", metadata.Created)
+
+	// Entry agent kept
+	require.Equal(t, "researcher", metadata.Agent)
 
 	// Original still exists
 	testutil.AssertDirExists(t, h.FS, originalSessionPath)
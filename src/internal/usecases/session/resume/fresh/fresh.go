@@ -1,12 +1,14 @@
 // Package fresh provides the use case for creating fresh memory sessions.
 // It orchestrates copying session directories, clearing memory-related files,
-// and deleting the original session to create a clean slate.
+// and archiving (or deleting) the original session to create a clean slate.
 package fresh
 
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
+	"claudex/internal/services/clock"
 	"claudex/internal/services/filesystem"
 	"claudex/internal/services/session"
 	"claudex/internal/services/uuid"
@@ -18,15 +20,20 @@ import (
 type UseCase struct {
 	fs          afero.Fs
 	uuidGen     uuid.UUIDGenerator
+	clock       clock.Clock
 	sessionsDir string
+	archiveDir  string
 }
 
-// New creates a new fresh memory use case
-func New(fs afero.Fs, uuidGen uuid.UUIDGenerator, sessionsDir string) *UseCase {
+// New creates a new fresh memory use case. The original session is moved to
+// archiveDir; an empty archiveDir deletes it instead.
+func New(fs afero.Fs, uuidGen uuid.UUIDGenerator, clk clock.Clock, sessionsDir, archiveDir string) *UseCase {
 	return &UseCase{
 		fs:          fs,
 		uuidGen:     uuidGen,
+		clock:       clk,
 		sessionsDir: sessionsDir,
+		archiveDir:  archiveDir,
 	}
 }
 
@@ -34,10 +41,15 @@ func New(fs afero.Fs, uuidGen uuid.UUIDGenerator, sessionsDir string) *UseCase {
 // 1. Generating a new UUID for the fresh session
 // 2. Stripping the Claude session ID from the original session name to get the base name
 // 3. Copying the session directory
-// 4. Removing tracking files (.last-processed-line, etc.)
-// 5. Resetting the doc update counter
-// 6. Deleting the original session directory
+// 4. Resetting the transcript line tracker and doc update counter, and
+// recording the new ID and the replaced session in the lineage
+// 5. Removing the legacy tracking files of unmigrated sessions
+// 6. Archiving the original session directory, or deleting it without an
+// archive
 // 7. Returning the new session info
+//
+// When the tracking reset or the archive fails, the copy is removed again so
+// only the original remains.
 func (uc *UseCase) Execute(originalSessionName string) (sessionName, sessionPath, claudeSessionID string, err error) {
 	// Generate new UUID for the fresh session
 	claudeSessionID = uc.uuidGen.New()
@@ -55,21 +67,28 @@ func (uc *UseCase) Execute(originalSessionName string) (sessionName, sessionPath
 		return "", "", "", fmt.Errorf("failed to copy session directory: %w", err)
 	}
 
-	// Reset tracking files for fresh session (new transcript starts at line 1)
-	trackingFiles := []string{
-		filepath.Join(sessionPath, ".last-processed-line-overview"),
-		filepath.Join(sessionPath, ".last-processed-line"),
+	// Reset tracking for the fresh session (new transcript starts at line 1)
+	err = session.UpdateMetadata(uc.fs, sessionPath, func(m *session.SessionMetadata) {
+		m.ClaudeSessionID = claudeSessionID
+		m.Counters = session.Counters{}
+		m.Lineage = append(m.Lineage, session.LineageEvent{
+			Op:   session.OpFresh,
+			From: originalSessionName,
+			At:   uc.clock.Now().UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		uc.fs.RemoveAll(sessionPath) // Best effort: the original is untouched
+		return "", "", "", fmt.Errorf("failed to reset session tracking: %w", err)
 	}
-	for _, f := range trackingFiles {
-		uc.fs.Remove(f) // Ignore errors - file may not exist
-	}
-
-	// Reset doc update counter
-	counterFile := filepath.Join(sessionPath, ".doc-update-counter")
-	afero.WriteFile(uc.fs, counterFile, []byte("0"), 0644)
 
-	// DELETE the original folder (key difference from fork)
-	if err := uc.fs.RemoveAll(originalSessionPath); err != nil {
+	// Retire the original folder (key difference from fork)
+	if uc.archiveDir != "" {
+		if err := session.Archive(uc.fs, uc.clock, uc.sessionsDir, uc.archiveDir, originalSessionName); err != nil {
+			uc.fs.RemoveAll(sessionPath) // Best effort: the original stays active
+			return "", "", "", fmt.Errorf("failed to archive original session: %w", err)
+		}
+	} else if err := uc.fs.RemoveAll(originalSessionPath); err != nil {
 		return "", "", "", fmt.Errorf("failed to delete original session: %w", err)
 	}
 
@@ -1,11 +1,17 @@
 package fresh
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"claudex/internal/services/session"
 	"claudex/internal/testutil"
 
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,6 +19,7 @@ import (
 func Test_Execute_CopiesAndDeletesOriginal(t *testing.T) {
 	// Setup
 	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
 	// Session name must match the pattern with dashes separating UUID segments
 	// Format: slug-XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX
 	originalSessionName := "login-feature-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
@@ -32,7 +39,7 @@ func Test_Execute_CopiesAndDeletesOriginal(t *testing.T) {
 	h.UUIDs = []string{"11112222-3333-4444-5555-666666666666"}
 
 	// Create usecase and exercise
-	uc := New(h.FS, h, sessionsDir)
+	uc := New(h.FS, h, h, sessionsDir, "")
 	newSessionName, newSessionPath, claudeSessionID, err := uc.Execute(originalSessionName)
 
 	// Verify
@@ -53,10 +60,127 @@ func Test_Execute_CopiesAndDeletesOriginal(t *testing.T) {
 	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".last-processed-line-overview"))
 	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".last-processed-line"))
 
-	// Counter reset
-	testutil.AssertFileExists(t, h.FS, filepath.Join(newSessionPath, ".doc-update-counter"))
-	testutil.AssertFileContains(t, h.FS, filepath.Join(newSessionPath, ".doc-update-counter"), "0")
+	testutil.AssertNoFileExists(t, h.FS, filepath.Join(newSessionPath, ".doc-update-counter"))
+
+	// Counters reset in session.json, replaced session recorded
+	metadata, err := session.ReadMetadata(h.FS, newSessionPath)
+	require.NoError(t, err)
+	require.Equal(t, session.Counters{}, metadata.Counters)
+	require.Equal(t, "Login feature", metadata.Description)
+	require.Equal(t, "11112222-3333-4444-5555-666666666666", metadata.ClaudeSessionID)
+	require.Equal(t, []session.LineageEvent{
+		{Op: session.OpFresh, From: originalSessionName, At: "2024-01-15T10:30:00Z"},
+	}, metadata.Lineage)
 
 	// Original DELETED
 	testutil.AssertNoDirExists(t, h.FS, originalSessionPath)
 }
+
+// Test_Execute_KeepsForkParent tests a fresh restart of a fork keeps the fork
+// parent and extends the lineage
+func Test_Execute_KeepsForkParent(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+	originalSessionName := "oauth-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+	sessionsDir := "/project/sessions"
+
+	originalSessionPath := filepath.Join(sessionsDir, originalSessionName)
+	h.CreateDir(originalSessionPath)
+	require.NoError(t, session.WriteMetadata(h.FS, originalSessionPath, &session.SessionMetadata{
+		Description: "OAuth",
+		Parent:      "login-12345678-abcd-ef12-3456-7890abcdef12",
+		Lineage: []session.LineageEvent{
+			{Op: session.OpFork, From: "login-12345678-abcd-ef12-3456-7890abcdef12", At: "2024-01-15T10:30:00Z"},
+		},
+	}))
+
+	h.UUIDs = []string{"11112222-3333-4444-5555-666666666666"}
+
+	uc := New(h.FS, h, h, sessionsDir, "")
+	_, newSessionPath, _, err := uc.Execute(originalSessionName)
+	require.NoError(t, err)
+
+	metadata, err := session.ReadMetadata(h.FS, newSessionPath)
+	require.NoError(t, err)
+	require.Equal(t, "login-12345678-abcd-ef12-3456-7890abcdef12", metadata.Parent)
+	require.Equal(t, []session.LineageEvent{
+		{Op: session.OpFork, From: "login-12345678-abcd-ef12-3456-7890abcdef12", At: "2024-01-15T10:30:00Z"},
+		{Op: session.OpFresh, From: originalSessionName, At: "2024-01-20T09:00:00Z"},
+	}, metadata.Lineage)
+	require.Equal(t, []string{originalSessionName}, metadata.PreviousNames())
+}
+
+// Test_Execute_ArchivesOriginal tests the original moves to the archive with
+// its Claude session ID intact instead of being deleted
+func Test_Execute_ArchivesOriginal(t *testing.T) {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	originalSessionName := "login-feature-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+	sessionsDir := "/project/.claudex/sessions"
+	archiveDir := "/project/.claudex/archive"
+
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, originalSessionName), map[string]string{
+		".description":       "Login feature",
+		"session-history.md": "# History",
+	})
+	h.UUIDs = []string{"11112222-3333-4444-5555-666666666666"}
+
+	uc := New(h.FS, h, h, sessionsDir, archiveDir)
+	_, newSessionPath, _, err := uc.Execute(originalSessionName)
+	require.NoError(t, err)
+
+	testutil.AssertDirExists(t, h.FS, newSessionPath)
+	testutil.AssertNoDirExists(t, h.FS, filepath.Join(sessionsDir, originalSessionName))
+
+	archivedPath := filepath.Join(archiveDir, originalSessionName)
+	testutil.AssertFileContains(t, h.FS, filepath.Join(archivedPath, "session-history.md"), "# History")
+	metadata, err := session.ReadMetadata(h.FS, archivedPath)
+	require.NoError(t, err)
+	require.Equal(t, "aaaabbbb-cccc-dddd-eeee-ffffffffffff", metadata.ClaudeSessionID)
+	require.Equal(t, "2024-01-15T10:30:00Z", metadata.Archived)
+
+	// The fresh session is not marked archived
+	metadata, err = session.ReadMetadata(h.FS, newSessionPath)
+	require.NoError(t, err)
+	require.Empty(t, metadata.Archived)
+}
+
+// noArchiveFs fails to create anything under the archive directory
+type noArchiveFs struct {
+	afero.Fs
+	archiveDir string
+}
+
+func (f noArchiveFs) MkdirAll(path string, perm os.FileMode) error {
+	if strings.HasPrefix(path, f.archiveDir) {
+		return errors.New("read-only file system")
+	}
+	return f.Fs.MkdirAll(path, perm)
+}
+
+// Test_Execute_ArchiveFailureRemovesCopy tests a failed archive leaves only
+// the original session behind
+func Test_Execute_ArchiveFailureRemovesCopy(t *testing.T) {
+	h := testutil.NewTestHarness()
+	originalSessionName := "login-feature-aaaabbbb-cccc-dddd-eeee-ffffffffffff"
+	sessionsDir := "/project/.claudex/sessions"
+	archiveDir := "/project/.claudex/archive"
+
+	h.CreateSessionWithFiles(filepath.Join(sessionsDir, originalSessionName), map[string]string{
+		".description":       "Login feature",
+		"session-history.md": "# History",
+	})
+	h.UUIDs = []string{"11112222-3333-4444-5555-666666666666"}
+
+	uc := New(noArchiveFs{Fs: h.FS, archiveDir: archiveDir}, h, h, sessionsDir, archiveDir)
+	_, _, _, err := uc.Execute(originalSessionName)
+	require.ErrorContains(t, err, "failed to archive original session")
+
+	entries, err := afero.ReadDir(h.FS, sessionsDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, originalSessionName, entries[0].Name())
+	metadata, err := session.ReadMetadata(h.FS, filepath.Join(sessionsDir, originalSessionName))
+	require.NoError(t, err)
+	require.Empty(t, metadata.Archived)
+}
@@ -0,0 +1,185 @@
+package sessionbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// ExportOptions select what goes into a bundle
+type ExportOptions struct {
+	// Transcript includes the Claude transcripts of the session and its subagents
+	Transcript bool
+
+	// RedactHome replaces the exporter's home directory in every text file
+	// with HomePlaceholder
+	RedactHome bool
+}
+
+// entry is a file to be written to a bundle
+type entry struct {
+	path string
+	data []byte
+}
+
+// Export writes the named session to a tar.gz bundle at out and returns its
+// manifest
+func (uc *UseCase) Export(sessionName, out string, opts ExportOptions) (*Manifest, error) {
+	sessionPath := filepath.Join(uc.sessionsDir, sessionName)
+	metadata, err := session.ReadMetadata(uc.fs, sessionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:         BundleVersion,
+		Exported:        uc.clock.Now().UTC().Format(time.RFC3339),
+		Session:         sessionName,
+		ClaudeSessionID: metadata.ClaudeSessionID,
+		Description:     metadata.Description,
+		ProjectDir:      uc.projectDir,
+		Transcript:      opts.Transcript,
+	}
+
+	entries, err := uc.sessionEntries(sessionPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Transcript {
+		transcripts, err := uc.transcriptEntries(metadata.ClaudeSessionID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, transcripts...)
+	}
+
+	if opts.RedactHome {
+		home, err := session.HomeDir(uc.env)
+		if err != nil {
+			return nil, fmt.Errorf("cannot redact paths: %w", err)
+		}
+		redact := pathReplacer(home, HomePlaceholder)
+		for i := range entries {
+			entries[i].data = rewrite(redact, entries[i].data)
+		}
+		manifest.ProjectDir = redact.Replace(manifest.ProjectDir)
+		manifest.RedactedHome = true
+	}
+
+	for _, e := range entries {
+		manifest.Files = append(manifest.Files, File{Path: e.path, Size: int64(len(e.data)), SHA256: checksum(e.data)})
+	}
+
+	if err := uc.writeBundle(out, manifest, entries); err != nil {
+		uc.fs.Remove(out)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// sessionEntries reads every file of the session folder
+func (uc *UseCase) sessionEntries(sessionPath string) ([]entry, error) {
+	var entries []entry
+	err := afero.Walk(uc.fs, sessionPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || filepath.Ext(p) == ".lock" {
+			// Locks belong to the processes working on this copy
+			return nil
+		}
+		rel, err := filepath.Rel(sessionPath, p)
+		if err != nil {
+			return err
+		}
+		data, err := afero.ReadFile(uc.fs, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{path: sessionPrefix + filepath.ToSlash(rel), data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	return entries, nil
+}
+
+// transcriptEntries reads the Claude transcript of the session and those of
+// its subagents
+func (uc *UseCase) transcriptEntries(claudeSessionID string) ([]entry, error) {
+	transcripts, err := session.FindTranscripts(uc.fs, uc.env, uc.projectDir, claudeSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot include the transcript: %w", err)
+	}
+
+	data, err := afero.ReadFile(uc.fs, transcripts.Main)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	entries := []entry{{path: transcriptPrefix + claudeSessionID + ".jsonl", data: data}}
+
+	for _, p := range transcripts.Subagents {
+		data, err := afero.ReadFile(uc.fs, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subagent transcript: %w", err)
+		}
+		entries = append(entries, entry{path: subagentPrefix + filepath.Base(p), data: data})
+	}
+	return entries, nil
+}
+
+// writeBundle writes the manifest followed by the entries as a tar.gz
+func (uc *UseCase) writeBundle(out string, manifest *Manifest, entries []entry) error {
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+
+	if dir := filepath.Dir(out); dir != "." {
+		if err := uc.fs.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	file, err := uc.fs.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	modTime, _ := time.Parse(time.RFC3339, manifest.Exported)
+	for _, e := range append([]entry{{path: ManifestFile, data: append(manifestData, '\n')}}, entries...) {
+		header := &tar.Header{Name: e.path, Mode: 0644, Size: int64(len(e.data)), ModTime: modTime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return file.Close()
+}
+
+// DefaultOutput returns the bundle file name used when none is given: the
+// session name without its Claude session ID
+func DefaultOutput(sessionName string) string {
+	return session.StripClaudeSessionID(sessionName) + ".tar.gz"
+}
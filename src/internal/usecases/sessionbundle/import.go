@@ -0,0 +1,235 @@
+package sessionbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// Imported is a session added to the project from a bundle
+type Imported struct {
+	Name            string
+	Path            string
+	ClaudeSessionID string
+	Transcript      string // Path of the imported transcript, empty when the bundle has none
+	Manifest        *Manifest
+}
+
+// Import validates a bundle and adds its session to the project under a new
+// Claude session ID. The exporter's project root, session name and Claude
+// session ID are remapped in every text file, and the transcripts, when
+// included, are placed where Claude looks for this project's sessions.
+func (uc *UseCase) Import(bundle string) (result *Imported, err error) {
+	manifest, files, err := uc.readBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	claudeSessionID := uc.uuidGen.New()
+	name := session.StripClaudeSessionID(manifest.Session) + "-" + claudeSessionID
+	sessionPath := filepath.Join(uc.sessionsDir, name)
+	if _, err := uc.fs.Stat(sessionPath); err == nil {
+		return nil, fmt.Errorf("session %s already exists", name)
+	}
+	result = &Imported{Name: name, Path: sessionPath, ClaudeSessionID: claudeSessionID, Manifest: manifest}
+
+	// Nothing is left behind when a later step fails
+	var written []string
+	defer func() {
+		if err != nil {
+			for _, p := range written {
+				uc.fs.RemoveAll(p)
+			}
+		}
+	}()
+
+	remap := pathReplacer(
+		manifest.ProjectDir, uc.projectDir,
+		manifest.Session, name,
+		manifest.ClaudeSessionID, claudeSessionID,
+	)
+
+	written = append(written, sessionPath)
+	for _, f := range manifest.Files {
+		rel, ok := strings.CutPrefix(f.Path, sessionPrefix)
+		if !ok {
+			continue
+		}
+		if err := uc.writeFile(filepath.Join(sessionPath, filepath.FromSlash(rel)), rewrite(remap, files[f.Path])); err != nil {
+			return nil, err
+		}
+	}
+
+	if manifest.Transcript {
+		projectsDir, err := session.ClaudeProjectsDir(uc.env)
+		if err != nil {
+			return nil, err
+		}
+		transcriptDir := filepath.Join(projectsDir, session.EncodeProjectPath(uc.projectDir))
+		result.Transcript = filepath.Join(transcriptDir, claudeSessionID+".jsonl")
+		written = append(written, result.Transcript, filepath.Join(transcriptDir, claudeSessionID))
+
+		for _, f := range manifest.Files {
+			dst := result.Transcript
+			if base, ok := strings.CutPrefix(f.Path, subagentPrefix); ok {
+				dst = filepath.Join(transcriptDir, claudeSessionID, "subagents", remap.Replace(base))
+			} else if !strings.HasPrefix(f.Path, transcriptPrefix) {
+				continue
+			}
+			if err := uc.writeFile(dst, rewrite(remap, files[f.Path])); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = session.UpdateMetadata(uc.fs, sessionPath, func(m *session.SessionMetadata) {
+		m.ClaudeSessionID = claudeSessionID
+		m.Archived = ""
+		if !manifest.Transcript {
+			m.Counters = session.Counters{} // No transcript lines were processed here
+		}
+		m.Lineage = append(m.Lineage, session.LineageEvent{
+			Op:   session.OpImport,
+			From: manifest.Session,
+			At:   uc.clock.Now().UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record the import: %w", err)
+	}
+	return result, nil
+}
+
+// writeFile writes an imported file, never replacing an existing one
+func (uc *UseCase) writeFile(p string, data []byte) error {
+	if _, err := uc.fs.Stat(p); err == nil {
+		return fmt.Errorf("%s already exists", p)
+	}
+	if err := uc.fs.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(p), err)
+	}
+	if err := afero.WriteFile(uc.fs, p, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p, err)
+	}
+	return nil
+}
+
+// readBundle reads a bundle and checks it against its manifest: every listed
+// file present with the recorded size and checksum, and nothing else
+func (uc *UseCase) readBundle(bundle string) (*Manifest, map[string][]byte, error) {
+	file, err := uc.fs.Open(bundle)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s is not a session bundle: %w", bundle, err)
+	}
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != ManifestFile {
+		return nil, nil, fmt.Errorf("%s is not a session bundle: %s must come first", bundle, ManifestFile)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+
+	expected := make(map[string]File, len(manifest.Files))
+	for _, f := range manifest.Files {
+		expected[f.Path] = f
+	}
+
+	files := make(map[string][]byte, len(manifest.Files))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		f, ok := expected[header.Name]
+		switch {
+		case header.Typeflag != tar.TypeReg:
+			return nil, nil, fmt.Errorf("bundle entry %s is not a regular file", header.Name)
+		case !ok:
+			return nil, nil, fmt.Errorf("bundle entry %s is not listed in the manifest", header.Name)
+		case files[header.Name] != nil:
+			return nil, nil, fmt.Errorf("bundle entry %s appears twice", header.Name)
+		case header.Size != f.Size:
+			return nil, nil, fmt.Errorf("bundle entry %s has %d bytes, the manifest says %d", header.Name, header.Size, f.Size)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if checksum(data) != f.SHA256 {
+			return nil, nil, fmt.Errorf("checksum mismatch for %s: the bundle is damaged or was modified", header.Name)
+		}
+		files[header.Name] = data
+	}
+
+	for _, f := range manifest.Files {
+		if files[f.Path] == nil {
+			return nil, nil, fmt.Errorf("%s is listed in the manifest but missing from the bundle", f.Path)
+		}
+	}
+	return &manifest, files, nil
+}
+
+// validate checks the manifest's version and that its paths stay inside the
+// places an import writes to
+func (m *Manifest) validate() error {
+	if m.Version < 1 || m.Version > BundleVersion {
+		return fmt.Errorf("unsupported bundle version %d (this version reads up to %d)", m.Version, BundleVersion)
+	}
+	base := session.StripClaudeSessionID(m.Session)
+	if base == "" || base != path.Base(base) || strings.ContainsAny(base, `/\:`) || base == "." || base == ".." {
+		return fmt.Errorf("invalid session name %q", m.Session)
+	}
+	if m.ProjectDir == "" {
+		return fmt.Errorf("missing projectDir")
+	}
+
+	mainTranscript := transcriptPrefix + m.ClaudeSessionID + ".jsonl"
+	for _, f := range m.Files {
+		if !safePath(f.Path) {
+			return fmt.Errorf("unsafe path %q", f.Path)
+		}
+		switch {
+		case strings.HasPrefix(f.Path, sessionPrefix):
+		case m.Transcript && strings.HasPrefix(f.Path, subagentPrefix) && path.Dir(f.Path)+"/" == subagentPrefix:
+		case m.Transcript && f.Path == mainTranscript && m.ClaudeSessionID != "":
+		default:
+			return fmt.Errorf("unexpected file %q", f.Path)
+		}
+	}
+	return nil
+}
+
+// safePath reports whether a bundle path is relative and stays inside the
+// directory it is extracted to on every platform
+func safePath(p string) bool {
+	return p != "" && path.Clean(p) == p && !path.IsAbs(p) && p != ".." &&
+		!strings.HasPrefix(p, "../") && !strings.ContainsAny(p, `\:`)
+}
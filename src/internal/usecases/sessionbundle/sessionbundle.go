@@ -0,0 +1,114 @@
+// Package sessionbundle provides the usecases behind `claudex session export`
+// and `claudex session import`: a session folder, optionally with its Claude
+// transcripts, packed into a tar.gz bundle with a checksummed manifest so the
+// work can be handed to someone else and continued in their project.
+package sessionbundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/env"
+	"claudex/internal/services/uuid"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// ManifestFile is the first entry of every bundle
+	ManifestFile = "manifest.json"
+
+	// BundleVersion is the bundle format this version writes and reads
+	BundleVersion = 1
+
+	// HomePlaceholder replaces the exporter's home directory when paths are redacted
+	HomePlaceholder = "~"
+
+	// Directories inside a bundle
+	sessionPrefix    = "session/"
+	transcriptPrefix = "transcript/"
+	subagentPrefix   = "transcript/subagents/"
+)
+
+// File is a bundle entry with its checksum
+type File struct {
+	Path   string `json:"path"` // Slash-separated path inside the bundle
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes a bundle
+type Manifest struct {
+	Version         int    `json:"version"`
+	Exported        string `json:"exported"` // RFC3339 timestamp
+	Session         string `json:"session"`  // Session name in the exporter's project
+	ClaudeSessionID string `json:"claudeSessionId,omitempty"`
+	Description     string `json:"description"`
+	ProjectDir      string `json:"projectDir"` // Exporter's project root, under HomePlaceholder when redacted
+	RedactedHome    bool   `json:"redactedHome,omitempty"`
+	Transcript      bool   `json:"transcript"` // Whether the Claude transcripts are included
+	Files           []File `json:"files"`
+}
+
+// UseCase exports sessions to bundles and imports them
+type UseCase struct {
+	fs          afero.Fs
+	env         env.Environment
+	uuidGen     uuid.UUIDGenerator
+	clock       clock.Clock
+	projectDir  string
+	sessionsDir string
+}
+
+// New creates a new session bundle use case for the project at projectDir
+func New(fs afero.Fs, environment env.Environment, uuidGen uuid.UUIDGenerator, clk clock.Clock, projectDir, sessionsDir string) *UseCase {
+	return &UseCase{
+		fs:          fs,
+		env:         environment,
+		uuidGen:     uuidGen,
+		clock:       clk,
+		projectDir:  projectDir,
+		sessionsDir: sessionsDir,
+	}
+}
+
+// checksum returns the hex SHA-256 of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// pathReplacer replaces each old, new pair both as written and as escaped
+// inside a JSON string, where Windows paths have doubled backslashes
+func pathReplacer(pairs ...string) *strings.Replacer {
+	var args []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		old, new := pairs[i], pairs[i+1]
+		if old == "" || old == new {
+			continue
+		}
+		args = append(args, old, new)
+		if escapedOld, escapedNew := jsonEscape(old), jsonEscape(new); escapedOld != old {
+			args = append(args, escapedOld, escapedNew)
+		}
+	}
+	return strings.NewReplacer(args...)
+}
+
+// jsonEscape returns s as it appears between the quotes of a JSON string
+func jsonEscape(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data[1 : len(data)-1])
+}
+
+// rewrite applies r to text files; binary content is left untouched
+func rewrite(r *strings.Replacer, data []byte) []byte {
+	if !utf8.Valid(data) {
+		return data
+	}
+	return []byte(r.Replace(string(data)))
+}
@@ -0,0 +1,217 @@
+package sessionbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"claudex/internal/services/session"
+	"claudex/internal/testutil"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	claudeID    = "33342657-73dc-407d-9aa6-a28f2e619268"
+	newID       = "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+	sessionName = "auth-refactor-" + claudeID
+	exporterDir = "/home/me/work/app"
+	importerDir = "/home/you/src/app"
+)
+
+// newTestHarness creates the exporter's session and transcripts; the
+// importer's project lives on the same filesystem under another home
+func newTestHarness(t *testing.T) *testutil.TestHarness {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	h.UUIDs = []string{newID}
+	h.Env.Set("HOME", "/home/me")
+
+	sessionPath := exporterDir + "/.claudex/sessions/" + sessionName
+	require.NoError(t, session.WriteMetadata(h.FS, sessionPath, &session.SessionMetadata{
+		ClaudeSessionID: claudeID,
+		Description:     "Refactor auth",
+		Created:         "2024-01-10T09:00:00Z",
+		Counters:        session.Counters{LastProcessedLine: 2},
+	}))
+	h.WriteFile(sessionPath+"/session-overview.md", "# Auth\nSee "+sessionPath+"/notes.md and /home/me/notes.txt\n")
+	h.WriteFile(sessionPath+"/research/tokens.md", "JWT")
+
+	projectsDir := "/home/me/.claude/projects/" + session.EncodeProjectPath(exporterDir)
+	h.WriteFile(projectsDir+"/"+claudeID+".jsonl",
+		`{"type":"user","sessionId":"`+claudeID+`","cwd":"`+exporterDir+`"}`+"\n")
+	h.WriteFile(projectsDir+"/"+claudeID+"/subagents/agent-a1.jsonl",
+		`{"type":"assistant","sessionId":"`+claudeID+`","isSidechain":true}`+"\n")
+	return h
+}
+
+func exporter(h *testutil.TestHarness) *UseCase {
+	return New(h.FS, h.Env, h, h, exporterDir, exporterDir+"/.claudex/sessions")
+}
+
+func importer(h *testutil.TestHarness) *UseCase {
+	env := testutil.NewMockEnv()
+	env.Set("HOME", "/home/you")
+	return New(h.FS, env, h, h, importerDir, importerDir+"/.claudex/sessions")
+}
+
+func TestExportImport_RoundTripWithTranscript(t *testing.T) {
+	h := newTestHarness(t)
+
+	manifest, err := exporter(h).Export(sessionName, "/tmp/auth.tar.gz", ExportOptions{Transcript: true})
+	require.NoError(t, err)
+	assert.Equal(t, sessionName, manifest.Session)
+	assert.Equal(t, exporterDir, manifest.ProjectDir)
+	assert.Equal(t, "2024-01-15T10:30:00Z", manifest.Exported)
+	var paths []string
+	for _, f := range manifest.Files {
+		paths = append(paths, f.Path)
+		assert.Len(t, f.SHA256, 64)
+	}
+	assert.ElementsMatch(t, []string{
+		"session/session.json",
+		"session/session-overview.md",
+		"session/research/tokens.md",
+		"transcript/" + claudeID + ".jsonl",
+		"transcript/subagents/agent-a1.jsonl",
+	}, paths)
+
+	imported, err := importer(h).Import("/tmp/auth.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "auth-refactor-"+newID, imported.Name)
+	assert.Equal(t, newID, imported.ClaudeSessionID)
+
+	// Paths and IDs are remapped to the importing project
+	testutil.AssertFileContains(t, h.FS, imported.Path+"/session-overview.md", imported.Path+"/notes.md")
+	testutil.AssertFileContains(t, h.FS, imported.Path+"/research/tokens.md", "JWT")
+
+	metadata, err := session.ReadMetadata(h.FS, imported.Path)
+	require.NoError(t, err)
+	assert.Equal(t, newID, metadata.ClaudeSessionID)
+	assert.Equal(t, "Refactor auth", metadata.Description)
+	assert.Equal(t, 2, metadata.Counters.LastProcessedLine)
+	assert.Equal(t, []session.LineageEvent{{Op: session.OpImport, From: sessionName, At: "2024-01-15T10:30:00Z"}}, metadata.Lineage)
+
+	transcriptDir := "/home/you/.claude/projects/" + session.EncodeProjectPath(importerDir)
+	assert.Equal(t, transcriptDir+"/"+newID+".jsonl", imported.Transcript)
+	testutil.AssertFileContains(t, h.FS, imported.Transcript, `"sessionId":"`+newID+`","cwd":"`+importerDir+`"`)
+	testutil.AssertFileContains(t, h.FS, transcriptDir+"/"+newID+"/subagents/agent-a1.jsonl", `"sessionId":"`+newID+`"`)
+
+	transcripts, err := session.FindTranscripts(h.FS, importer(h).env, importerDir, newID)
+	require.NoError(t, err)
+	assert.Len(t, transcripts.Subagents, 1)
+}
+
+func TestExport_RedactsHome(t *testing.T) {
+	h := newTestHarness(t)
+
+	manifest, err := exporter(h).Export(sessionName, "/tmp/auth.tar.gz", ExportOptions{Transcript: true, RedactHome: true})
+	require.NoError(t, err)
+	assert.True(t, manifest.RedactedHome)
+	assert.Equal(t, "~/work/app", manifest.ProjectDir)
+
+	_, files, err := exporter(h).readBundle("/tmp/auth.tar.gz")
+	require.NoError(t, err)
+	for name, data := range files {
+		assert.NotContains(t, string(data), "/home/me", name)
+	}
+	assert.Contains(t, string(files["session/session-overview.md"]), "~/notes.txt")
+
+	// The redacted project root still maps to the importing project
+	imported, err := importer(h).Import("/tmp/auth.tar.gz")
+	require.NoError(t, err)
+	testutil.AssertFileContains(t, h.FS, imported.Transcript, `"cwd":"`+importerDir+`"`)
+}
+
+func TestImport_WithoutTranscriptResetsCounters(t *testing.T) {
+	h := newTestHarness(t)
+
+	_, err := exporter(h).Export(sessionName, "/tmp/auth.tar.gz", ExportOptions{})
+	require.NoError(t, err)
+
+	imported, err := importer(h).Import("/tmp/auth.tar.gz")
+	require.NoError(t, err)
+	assert.Empty(t, imported.Transcript)
+
+	metadata, err := session.ReadMetadata(h.FS, imported.Path)
+	require.NoError(t, err)
+	assert.Equal(t, session.Counters{}, metadata.Counters)
+	testutil.AssertNoDirExists(t, h.FS, "/home/you/.claude")
+}
+
+func TestExport_TranscriptMissing(t *testing.T) {
+	h := newTestHarness(t)
+	require.NoError(t, h.FS.RemoveAll("/home/me/.claude"))
+
+	_, err := exporter(h).Export(sessionName, "/tmp/auth.tar.gz", ExportOptions{Transcript: true})
+	assert.ErrorContains(t, err, "cannot include the transcript")
+	exists, _ := afero.Exists(h.FS, "/tmp/auth.tar.gz")
+	assert.False(t, exists)
+}
+
+// writeTestBundle writes a bundle with the given manifest and entries as-is
+func writeTestBundle(t *testing.T, fs afero.Fs, manifest Manifest, entries map[string]string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	write(ManifestFile, data)
+	for name, content := range entries {
+		write(name, []byte(content))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, afero.WriteFile(fs, "/tmp/bad.tar.gz", buf.Bytes(), 0644))
+}
+
+func TestImport_RejectsInvalidBundles(t *testing.T) {
+	valid := func() Manifest {
+		return Manifest{
+			Version:    BundleVersion,
+			Session:    sessionName,
+			ProjectDir: exporterDir,
+			Files:      []File{{Path: "session/notes.md", Size: 5, SHA256: checksum([]byte("notes"))}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(m *Manifest)
+		entries map[string]string
+		wantErr string
+	}{
+		{"modified file", nil, map[string]string{"session/notes.md": "NOTES"}, "checksum mismatch"},
+		{"missing file", nil, map[string]string{}, "missing from the bundle"},
+		{"unlisted file", nil, map[string]string{"session/notes.md": "notes", "session/extra.md": "x"}, "not listed in the manifest"},
+		{"newer version", func(m *Manifest) { m.Version = BundleVersion + 1 }, map[string]string{"session/notes.md": "notes"}, "unsupported bundle version"},
+		{"path escape", func(m *Manifest) { m.Files[0].Path = "session/../../evil" }, map[string]string{"session/../../evil": "notes"}, "unsafe path"},
+		{"outside session", func(m *Manifest) { m.Files[0].Path = "hooks/x.sh" }, map[string]string{"hooks/x.sh": "notes"}, "unexpected file"},
+		{"bad session name", func(m *Manifest) { m.Session = "../x" }, map[string]string{"session/notes.md": "notes"}, "invalid session name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t)
+			manifest := valid()
+			if tt.edit != nil {
+				tt.edit(&manifest)
+			}
+			writeTestBundle(t, h.FS, manifest, tt.entries)
+
+			_, err := importer(h).Import("/tmp/bad.tar.gz")
+			assert.ErrorContains(t, err, tt.wantErr)
+			testutil.AssertNoDirExists(t, h.FS, importerDir+"/.claudex/sessions/auth-refactor-"+newID)
+		})
+	}
+}
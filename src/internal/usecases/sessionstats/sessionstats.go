@@ -0,0 +1,346 @@
+// Package sessionstats provides the usecase behind `claudex session stats`:
+// token usage, tool calls and estimated cost of a session, read from the
+// Claude transcripts of the session and the subagents it started.
+package sessionstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/doc"
+	"claudex/internal/services/env"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/session"
+
+	"github.com/spf13/afero"
+)
+
+// mainAgent labels usage of the session's own thread
+const mainAgent = "main"
+
+// Tokens counts the tokens of a set of API calls
+type Tokens struct {
+	Input         int `json:"input"`
+	Output        int `json:"output"`
+	CacheCreation int `json:"cacheCreation"`
+	CacheRead     int `json:"cacheRead"`
+}
+
+// Totals aggregates a set of API calls
+type Totals struct {
+	Calls            int            `json:"calls"`
+	Tokens           Tokens         `json:"tokens"`
+	ToolUses         map[string]int `json:"toolUses"`
+	EstimatedCostUSD float64        `json:"estimatedCostUsd"`
+}
+
+func (t *Totals) add(m doc.MessageUsage) {
+	if t.ToolUses == nil {
+		t.ToolUses = map[string]int{}
+	}
+	t.Calls++
+	t.Tokens.Input += m.InputTokens
+	t.Tokens.Output += m.OutputTokens
+	t.Tokens.CacheCreation += m.CacheCreationTokens
+	t.Tokens.CacheRead += m.CacheReadTokens
+	for tool, n := range m.ToolUses {
+		t.ToolUses[tool] += n
+	}
+	t.EstimatedCostUSD += llm.EstimateCost(m.Model, llm.Usage{
+		InputTokens:         m.InputTokens,
+		OutputTokens:        m.OutputTokens,
+		CacheCreationTokens: m.CacheCreationTokens,
+		CacheReadTokens:     m.CacheReadTokens,
+	})
+}
+
+// ModelStats is the usage served by one model
+type ModelStats struct {
+	Model string `json:"model"`
+	Totals
+}
+
+// AgentStats is the usage of the main thread or one subagent
+type AgentStats struct {
+	AgentID string `json:"agentId"`
+	Type    string `json:"type,omitempty"`
+	Totals
+}
+
+// TimelineBucket is the usage within one hour
+type TimelineBucket struct {
+	Start string `json:"start"`
+	Totals
+}
+
+// Report is the usage of a session
+type Report struct {
+	Session         string           `json:"session"`
+	ClaudeSessionID string           `json:"claudeSessionId"`
+	Transcripts     []string         `json:"transcripts"`
+	FirstActivity   string           `json:"firstActivity,omitempty"`
+	LastActivity    string           `json:"lastActivity,omitempty"`
+	Totals          Totals           `json:"totals"`
+	Models          []ModelStats     `json:"models"`
+	Agents          []AgentStats     `json:"agents"`
+	Timeline        []TimelineBucket `json:"timeline"`
+}
+
+// UseCase computes session statistics
+type UseCase struct {
+	fs          afero.Fs
+	env         env.Environment
+	projectDir  string
+	sessionsDir string
+}
+
+// New creates a new SessionStats usecase for the sessions of a project
+func New(fs afero.Fs, environment env.Environment, projectDir, sessionsDir string) *UseCase {
+	return &UseCase{
+		fs:          fs,
+		env:         environment,
+		projectDir:  projectDir,
+		sessionsDir: sessionsDir,
+	}
+}
+
+// Find resolves a session by its full name, a prefix or a fuzzy match
+func (uc *UseCase) Find(name string) (string, error) {
+	return session.Resolve(uc.fs, uc.sessionsDir, name)
+}
+
+// Stats builds the usage report of a session
+func (uc *UseCase) Stats(name string) (*Report, error) {
+	sessionName, err := uc.Find(name)
+	if err != nil {
+		return nil, err
+	}
+
+	claudeID := session.ClaudeSessionID(uc.fs, filepath.Join(uc.sessionsDir, sessionName))
+	if claudeID == "" {
+		return nil, fmt.Errorf("session %s has no Claude session ID (it was never launched)", sessionName)
+	}
+
+	transcripts, err := session.FindTranscripts(uc.fs, uc.env, uc.projectDir, claudeID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Session:         sessionName,
+		ClaudeSessionID: claudeID,
+		Transcripts:     append([]string{transcripts.Main}, transcripts.Subagents...),
+		Totals:          Totals{ToolUses: map[string]int{}},
+	}
+
+	var messages []doc.MessageUsage
+	agentTypes := map[string]string{}
+	for i, path := range report.Transcripts {
+		usage, err := doc.ParseTranscriptUsage(uc.fs, path)
+		if err != nil {
+			return nil, err
+		}
+		for agentID, agentType := range usage.AgentTypes {
+			agentTypes[agentID] = agentType
+		}
+		for _, m := range usage.Messages {
+			// Subagent files predating agentId on every line are attributed by file name
+			if i > 0 && m.AgentID == "" {
+				m.AgentID = strings.TrimPrefix(strings.TrimSuffix(filepath.Base(path), ".jsonl"), "agent-")
+			}
+			messages = append(messages, m)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Timestamp < messages[j].Timestamp })
+	aggregate(report, messages, agentTypes)
+	return report, nil
+}
+
+// aggregate fills the report's totals, breakdowns and timeline
+func aggregate(report *Report, messages []doc.MessageUsage, agentTypes map[string]string) {
+	models := map[string]*ModelStats{}
+	agents := map[string]*AgentStats{}
+	buckets := map[string]*TimelineBucket{}
+
+	for _, m := range messages {
+		report.Totals.add(m)
+
+		if report.FirstActivity == "" {
+			report.FirstActivity = m.Timestamp
+		}
+		report.LastActivity = m.Timestamp
+
+		if _, ok := models[m.Model]; !ok {
+			models[m.Model] = &ModelStats{Model: m.Model}
+		}
+		models[m.Model].add(m)
+
+		agentID := m.AgentID
+		if agentID == "" {
+			agentID = mainAgent
+		}
+		if _, ok := agents[agentID]; !ok {
+			agents[agentID] = &AgentStats{AgentID: agentID, Type: agentTypes[m.AgentID]}
+		}
+		agents[agentID].add(m)
+
+		if start := hourOf(m.Timestamp); start != "" {
+			if _, ok := buckets[start]; !ok {
+				buckets[start] = &TimelineBucket{Start: start}
+			}
+			buckets[start].add(m)
+		}
+	}
+
+	report.Models = []ModelStats{}
+	for _, s := range models {
+		report.Models = append(report.Models, *s)
+	}
+	sort.Slice(report.Models, func(i, j int) bool {
+		return report.Models[i].EstimatedCostUSD > report.Models[j].EstimatedCostUSD
+	})
+
+	// Main thread first, then subagents by cost
+	report.Agents = []AgentStats{}
+	for _, s := range agents {
+		report.Agents = append(report.Agents, *s)
+	}
+	sort.Slice(report.Agents, func(i, j int) bool {
+		a, b := report.Agents[i], report.Agents[j]
+		if (a.AgentID == mainAgent) != (b.AgentID == mainAgent) {
+			return a.AgentID == mainAgent
+		}
+		return a.EstimatedCostUSD > b.EstimatedCostUSD
+	})
+
+	report.Timeline = []TimelineBucket{}
+	for _, b := range buckets {
+		report.Timeline = append(report.Timeline, *b)
+	}
+	sort.Slice(report.Timeline, func(i, j int) bool { return report.Timeline[i].Start < report.Timeline[j].Start })
+}
+
+// hourOf truncates an ISO 8601 timestamp to the start of its hour (RFC3339, UTC)
+func hourOf(timestamp string) string {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Truncate(time.Hour).Format(time.RFC3339)
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// Write writes the report as text: a summary followed by per-model,
+// per-agent and hourly tables
+func Write(w io.Writer, report *Report) error {
+	fmt.Fprintf(w, "Session:    %s\n", report.Session)
+	fmt.Fprintf(w, "Claude ID:  %s\n", report.ClaudeSessionID)
+
+	if report.Totals.Calls == 0 {
+		fmt.Fprintln(w, "\nNo API usage recorded in the transcript yet.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Activity:   %s -> %s\n", formatTime(report.FirstActivity), formatTime(report.LastActivity))
+	fmt.Fprintf(w, "Totals:     %d API call(s), ~%s estimated\n", report.Totals.Calls, llm.FormatCost(report.Totals.EstimatedCostUSD))
+	fmt.Fprintf(w, "Tokens:     %s in, %s out, %s cache write, %s cache read\n",
+		llm.FormatTokens(report.Totals.Tokens.Input),
+		llm.FormatTokens(report.Totals.Tokens.Output),
+		llm.FormatTokens(report.Totals.Tokens.CacheCreation),
+		llm.FormatTokens(report.Totals.Tokens.CacheRead),
+	)
+	fmt.Fprintf(w, "Tools:      %s\n", formatTools(report.Totals.ToolUses))
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tCALLS\tINPUT\tOUTPUT\tCACHE W\tCACHE R\tCOST")
+	for _, m := range report.Models {
+		fmt.Fprintf(tw, "%s\t%s\n", m.Model, totalsRow(m.Totals))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tTYPE\tCALLS\tINPUT\tOUTPUT\tCACHE W\tCACHE R\tCOST\tTOOLS")
+	for _, a := range report.Agents {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.AgentID, orDash(a.Type), totalsRow(a.Totals), formatTools(a.ToolUses))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOUR\tCALLS\tINPUT\tOUTPUT\tCACHE W\tCACHE R\tCOST\tTOOLS")
+	for _, b := range report.Timeline {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", formatTime(b.Start), totalsRow(b.Totals), formatTools(b.ToolUses))
+	}
+	return tw.Flush()
+}
+
+// totalsRow formats the shared CALLS..COST columns
+func totalsRow(t Totals) string {
+	return fmt.Sprintf("%d\t%s\t%s\t%s\t%s\t%s",
+		t.Calls,
+		llm.FormatTokens(t.Tokens.Input),
+		llm.FormatTokens(t.Tokens.Output),
+		llm.FormatTokens(t.Tokens.CacheCreation),
+		llm.FormatTokens(t.Tokens.CacheRead),
+		llm.FormatCost(t.EstimatedCostUSD),
+	)
+}
+
+// formatTools lists tool call counts, most used first (e.g. "Bash 12, Read 8")
+func formatTools(tools map[string]int) string {
+	if len(tools) == 0 {
+		return "-"
+	}
+
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if tools[names[i]] != tools[names[j]] {
+			return tools[names[i]] > tools[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, tools[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTime renders an ISO 8601 timestamp in local time
+func formatTime(timestamp string) string {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return orDash(timestamp)
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
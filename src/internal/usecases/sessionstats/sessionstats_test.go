@@ -0,0 +1,128 @@
+package sessionstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	claudeID    = "33342657-73dc-407d-9aa6-a28f2e619268"
+	sessionName = "auth-refactor-" + claudeID
+	projectsDir = "/home/me/.claude/projects/-work-app"
+)
+
+// newTestUseCase creates a project with one session and its transcripts
+func newTestUseCase(t *testing.T) (*UseCase, *testutil.TestHarness) {
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/me")
+	h.CreateDir("/work/app/.claudex/sessions/" + sessionName)
+	h.CreateDir("/work/app/.claudex/sessions/billing-ui-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+
+	h.WriteFile(projectsDir+"/"+claudeID+".jsonl",
+		`{"type":"assistant","timestamp":"2024-01-15T10:10:00Z","sessionId":"`+claudeID+`","message":{"id":"m1","model":"claude-sonnet-4-5-20250929","content":[{"type":"tool_use","id":"t1","name":"Task","input":{"subagent_type":"researcher"}}],"usage":{"input_tokens":1000,"output_tokens":100}}}
+{"type":"user","timestamp":"2024-01-15T10:20:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},"toolUseResult":{"status":"completed","agentId":"a1","content":[{"type":"text","text":"ok"}]}}
+{"type":"assistant","timestamp":"2024-01-15T11:05:00Z","sessionId":"`+claudeID+`","message":{"id":"m3","model":"claude-sonnet-4-5-20250929","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{}}],"usage":{"input_tokens":2000,"output_tokens":300}}}
+`)
+	h.WriteFile(projectsDir+"/agent-a1.jsonl",
+		`{"type":"assistant","timestamp":"2024-01-15T10:15:00Z","sessionId":"`+claudeID+`","isSidechain":true,"agentId":"a1","message":{"id":"m2","model":"claude-haiku-4-5-20251001","content":[{"type":"tool_use","id":"g1","name":"Grep","input":{}}],"usage":{"input_tokens":500,"output_tokens":50}}}
+`)
+
+	return New(h.FS, h.Env, "/work/app", "/work/app/.claudex/sessions"), h
+}
+
+func TestFind(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+
+	name, err := uc.Find("auth")
+	require.NoError(t, err)
+	assert.Equal(t, sessionName, name)
+
+	_, err = uc.Find("nope")
+	assert.ErrorContains(t, err, "session not found")
+
+	uc.fs.MkdirAll("/work/app/.claudex/sessions/auth-other", 0755)
+	_, err = uc.Find("auth")
+	assert.ErrorContains(t, err, "ambiguous")
+}
+
+func TestStats_TotalsAgentsAndTimeline(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+
+	report, err := uc.Stats("auth-refactor")
+	require.NoError(t, err)
+
+	assert.Equal(t, sessionName, report.Session)
+	assert.Equal(t, claudeID, report.ClaudeSessionID)
+	assert.Len(t, report.Transcripts, 2)
+	assert.Equal(t, "2024-01-15T10:10:00Z", report.FirstActivity)
+	assert.Equal(t, "2024-01-15T11:05:00Z", report.LastActivity)
+
+	// Totals span the main thread and the subagent
+	assert.Equal(t, 3, report.Totals.Calls)
+	assert.Equal(t, Tokens{Input: 3500, Output: 450}, report.Totals.Tokens)
+	assert.Equal(t, map[string]int{"Task": 1, "Grep": 1, "Edit": 1}, report.Totals.ToolUses)
+	// Sonnet: 3000 in at $3, 400 out at $15; Haiku: 500 in at $1, 50 out at $5
+	assert.InDelta(t, (3000*3+400*15+500*1+50*5)/1e6, report.Totals.EstimatedCostUSD, 1e-9)
+
+	require.Len(t, report.Agents, 2)
+	assert.Equal(t, "main", report.Agents[0].AgentID)
+	assert.Equal(t, 2, report.Agents[0].Calls)
+	assert.Equal(t, "a1", report.Agents[1].AgentID)
+	assert.Equal(t, "researcher", report.Agents[1].Type)
+	assert.Equal(t, map[string]int{"Grep": 1}, report.Agents[1].ToolUses)
+
+	require.Len(t, report.Models, 2)
+	assert.Equal(t, "claude-sonnet-4-5-20250929", report.Models[0].Model)
+
+	require.Len(t, report.Timeline, 2)
+	assert.Equal(t, "2024-01-15T10:00:00Z", report.Timeline[0].Start)
+	assert.Equal(t, 2, report.Timeline[0].Calls)
+	assert.Equal(t, "2024-01-15T11:00:00Z", report.Timeline[1].Start)
+}
+
+func TestStats_NeverLaunched(t *testing.T) {
+	uc, h := newTestUseCase(t)
+	h.CreateDir("/work/app/.claudex/sessions/draft")
+
+	_, err := uc.Stats("draft")
+	assert.ErrorContains(t, err, "no Claude session ID")
+}
+
+func TestWriteJSON(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+	report, err := uc.Stats(sessionName)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteJSON(&out, report))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, sessionName, decoded["session"])
+	totals := decoded["totals"].(map[string]interface{})
+	assert.Equal(t, float64(3), totals["calls"])
+	assert.Contains(t, totals, "estimatedCostUsd")
+	agents := decoded["agents"].([]interface{})
+	assert.Equal(t, "researcher", agents[1].(map[string]interface{})["type"])
+}
+
+func TestWrite_Text(t *testing.T) {
+	uc, _ := newTestUseCase(t)
+	report, err := uc.Stats(sessionName)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, report))
+	text := out.String()
+
+	assert.Contains(t, text, "Totals:     3 API call(s)")
+	assert.Contains(t, text, "Tools:      Edit 1, Grep 1, Task 1")
+	assert.Contains(t, text, "researcher")
+	assert.Contains(t, text, "HOUR")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"claudex"
@@ -11,6 +12,13 @@ import (
 	"github.com/spf13/afero"
 )
 
+// defaultEngineerModel is the model for principal-engineer agents unless
+// [models.agents] overrides it
+const defaultEngineerModel = "sonnet"
+
+// frontmatterModel matches the model line of an agent's frontmatter
+var frontmatterModel = regexp.MustCompile(`(?m)^model:.*$`)
+
 // AssembleEngineerAgent creates a principal-engineer-{stack} agent from role + skill templates.
 // It reads the engineer.md role template and the stack-specific skill file (e.g., typescript.md)
 // from the embedded profiles, combines them with frontmatter, and writes to both agents/ and
@@ -19,12 +27,13 @@ import (
 // Parameters:
 //   - afs: Filesystem abstraction for writing files
 //   - stack: Stack identifier (e.g., "typescript", "go", "python")
+//   - model: Model for the agent's frontmatter (e.g., "sonnet")
 //   - agentsDir: Target directory for agent profiles (.claude/agents)
 //   - commandsAgentsDir: Target directory for command agents (.claude/commands/agents)
 //   - noOverwrite: If true, existing files will not be overwritten
 //
 // Returns an error if assembly fails.
-func AssembleEngineerAgent(afs afero.Fs, stack, agentsDir, commandsAgentsDir string, noOverwrite bool) error {
+func AssembleEngineerAgent(afs afero.Fs, stack, model, agentsDir, commandsAgentsDir string, noOverwrite bool) error {
 	// Read role template from embedded FS
 	roleContent, err := fs.ReadFile(claudex.Profiles, "profiles/roles/engineer.md")
 	if err != nil {
@@ -38,11 +47,11 @@ func AssembleEngineerAgent(afs afero.Fs, stack, agentsDir, commandsAgentsDir str
 	frontmatter := fmt.Sprintf(`---
 name: principal-engineer-%s
 description: Use this agent when you need a Principal %s Engineer for code implementation, debugging, refactoring, and development best practices. This agent executes stories by reading execution plans and implementing tasks sequentially with comprehensive testing and documentation lookup.
-model: sonnet
+model: %s
 color: blue
 ---
 
-`, stack, stackDisplay)
+`, stack, stackDisplay, model)
 
 	// Replace {Stack} placeholder in role content
 	roleStr := strings.ReplaceAll(string(roleContent), "{Stack}", stackDisplay)
@@ -79,6 +88,31 @@ color: blue
 	return nil
 }
 
+// withAgentModel replaces the model in an agent profile's frontmatter.
+// An empty model leaves the profile unchanged.
+func withAgentModel(content []byte, model string) []byte {
+	if model == "" {
+		return content
+	}
+
+	// Only rewrite inside the leading frontmatter block
+	text := string(content)
+	if !strings.HasPrefix(text, "---\n") {
+		return content
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end < 0 {
+		return content
+	}
+	end += 4
+
+	loc := frontmatterModel.FindStringIndex(text[:end])
+	if loc == nil {
+		return content
+	}
+	return []byte(text[:loc[0]] + "model: " + model + text[loc[1]:])
+}
+
 // formatStackName returns the properly capitalized display name for a stack
 func formatStackName(stack string) string {
 	switch stack {
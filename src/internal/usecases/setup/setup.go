@@ -14,6 +14,7 @@ import (
 	"claudex"
 	"claudex/internal/services/env"
 	"claudex/internal/services/filesystem"
+	"claudex/internal/services/models"
 	"claudex/internal/services/settings"
 	"claudex/internal/services/stackdetect"
 
@@ -22,15 +23,18 @@ import (
 
 // SetupUseCase orchestrates the .claude directory setup workflow
 type SetupUseCase struct {
-	fs  afero.Fs
-	env env.Environment
+	fs     afero.Fs
+	env    env.Environment
+	models *models.Resolver
 }
 
-// New creates a new SetupUseCase instance with the given dependencies
+// New creates a new SetupUseCase instance with the given dependencies.
+// Agent models are resolved from the CLAUDEX_MODEL_AGENT_* environment.
 func New(fs afero.Fs, environment env.Environment) *SetupUseCase {
 	return &SetupUseCase{
-		fs:  fs,
-		env: environment,
+		fs:     fs,
+		env:    environment,
+		models: models.FromEnv(environment),
 	}
 }
 
@@ -123,7 +127,8 @@ func (uc *SetupUseCase) Execute(projectDir string, noOverwrite bool) error {
 
 	// Generate principal-engineer-{stack} agents from embedded profiles
 	for _, stack := range stacks {
-		if err := AssembleEngineerAgent(uc.fs, stack, agentsDir, commandsAgentsDir, noOverwrite); err != nil {
+		model := uc.models.Agent("principal-engineer-"+stack, defaultEngineerModel)
+		if err := AssembleEngineerAgent(uc.fs, stack, model, agentsDir, commandsAgentsDir, noOverwrite); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to assemble principal-engineer-%s: %v\n", stack, err)
 		}
 	}
@@ -187,6 +192,7 @@ func (uc *SetupUseCase) copyAgentProfiles(agentsDir, commandsAgentsDir string, n
 				fmt.Fprintf(os.Stderr, "Warning: Failed to read embedded agent %s: %v\n", entry.Name(), err)
 				continue
 			}
+			content = withAgentModel(content, uc.models.Agent(entry.Name(), ""))
 
 			// Copy to agents/
 			agentTarget := filepath.Join(agentsDir, entry.Name()+".md")
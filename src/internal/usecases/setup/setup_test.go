@@ -590,3 +590,31 @@ func Test_Execute_MergesWithAbsolutePaths(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotContains(t, string(content), `".claude/hooks/`)
 }
+
+// Test_Execute_AppliesAgentModelOverrides verifies CLAUDEX_MODEL_AGENT_* overrides
+// the model in generated engineer and copied agent frontmatter.
+func Test_Execute_AppliesAgentModelOverrides(t *testing.T) {
+	// Setup
+	h := testutil.NewTestHarness()
+	h.Env.Set("HOME", "/home/user")
+	h.Env.Set("CLAUDEX_MODEL_AGENT_PRINCIPAL_ENGINEER_GO", "opus")
+	h.Env.Set("CLAUDEX_MODEL_AGENT_ARCHITECT", "sonnet")
+	h.WriteFile("/project/go.mod", "module example")
+
+	// Create usecase and exercise
+	uc := New(h.FS, h.Env)
+	err := uc.Execute("/project", false)
+
+	// Verify - no errors
+	require.NoError(t, err)
+
+	// Verify - engineer frontmatter uses the override
+	engineer, err := afero.ReadFile(h.FS, "/project/.claude/agents/principal-engineer-go.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(engineer), "\nmodel: opus\n")
+
+	// Verify - copied profile is rewritten, others keep their own model
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/agents/architect.md", "\nmodel: sonnet\n")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/commands/agents/architect.md", "\nmodel: sonnet\n")
+	testutil.AssertFileContains(t, h.FS, "/project/.claude/agents/team-lead.md", "\nmodel: sonnet\n")
+}
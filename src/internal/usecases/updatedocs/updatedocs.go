@@ -10,12 +10,15 @@ import (
 	"path/filepath"
 
 	"claudex/internal/doc/rangeupdater"
+	"claudex/internal/services/clock"
 	"claudex/internal/services/commander"
 	"claudex/internal/services/doctracking"
 	"claudex/internal/services/env"
 	"claudex/internal/services/git"
+	"claudex/internal/services/jobs"
 	"claudex/internal/services/lock"
 	"claudex/internal/services/paths"
+	"claudex/internal/services/uuid"
 
 	"github.com/spf13/afero"
 )
@@ -52,6 +55,8 @@ func (uc *UpdateDocsUseCase) Execute(projectDir string) error {
 	gitSvc := git.New(uc.cmd)
 	lockSvc := lock.New(uc.fs)
 	trackingSvc := doctracking.New(uc.fs, sessionPath)
+	queue := jobs.New(uc.fs, filepath.Join(projectDir, paths.JobsDir), clock.New(), uuid.New())
+	spawner := jobs.NewSpawner(jobs.HooksBinary(uc.env))
 
 	// Configure updater
 	config := rangeupdater.RangeUpdaterConfig{
@@ -69,6 +74,8 @@ func (uc *UpdateDocsUseCase) Execute(projectDir string) error {
 		uc.cmd,
 		uc.fs,
 		uc.env,
+		queue,
+		spawner,
 	)
 
 	// Run update
@@ -94,7 +101,7 @@ func displayResult(result *rangeupdater.UpdateResult) {
 			}
 		} else {
 			fmt.Printf("✓ Documentation update completed (%s)\n", result.ProcessedRange)
-			fmt.Printf("  Updated %d index.md file(s):\n", len(result.AffectedIndexes))
+			fmt.Printf("  Queued %d index.md update(s):\n", len(result.AffectedIndexes))
 			for _, idx := range result.AffectedIndexes {
 				// Make path relative to current directory for cleaner output
 				rel, err := filepath.Rel(".", idx)
@@ -103,6 +110,7 @@ func displayResult(result *rangeupdater.UpdateResult) {
 				}
 				fmt.Printf("    - %s\n", rel)
 			}
+			fmt.Printf("  Track progress with: claudex jobs list\n")
 		}
 
 	case "skipped":
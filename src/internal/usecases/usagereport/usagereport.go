@@ -0,0 +1,191 @@
+// Package usagereport provides the usecase behind `claudex usage`: spend of
+// headless model calls recorded in the project's usage ledger
+// (.claudex/usage.jsonl), grouped per session, per day and per feature.
+package usagereport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claudex/internal/services/clock"
+	"claudex/internal/services/llm"
+	"claudex/internal/services/usage"
+)
+
+// Grouping names accepted by Report
+const (
+	BySession = "session"
+	ByDay     = "day"
+	ByFeature = "feature"
+)
+
+// Groupings lists every grouping in report order
+var Groupings = []string{ByFeature, ByDay, BySession}
+
+// Summary aggregates the entries sharing a grouping key
+type Summary struct {
+	Key          string
+	Calls        int
+	Failed       int
+	InputTokens  int
+	OutputTokens int
+	CacheTokens  int
+	CostUSD      float64
+}
+
+func (s *Summary) add(e usage.Entry) {
+	s.Calls++
+	if e.Error != "" {
+		s.Failed++
+	}
+	s.InputTokens += e.InputTokens
+	s.OutputTokens += e.OutputTokens
+	s.CacheTokens += e.CacheCreationTokens + e.CacheReadTokens
+	s.CostUSD += e.CostUSD
+}
+
+// UseCase reports spend from a single usage ledger
+type UseCase struct {
+	ledger usage.Ledger
+	clock  clock.Clock
+}
+
+// New creates a new UsageReport usecase
+func New(ledger usage.Ledger, clk clock.Clock) *UseCase {
+	return &UseCase{
+		ledger: ledger,
+		clock:  clk,
+	}
+}
+
+// Report writes a spend summary followed by one table per grouping.
+// groupings selects the tables (all when empty); days limits the report to
+// the last N calendar days (all history when 0).
+func (uc *UseCase) Report(w io.Writer, groupings []string, days int) error {
+	if len(groupings) == 0 {
+		groupings = Groupings
+	}
+	for _, g := range groupings {
+		if keyFunc(g) == nil {
+			return fmt.Errorf("unknown grouping %q (expected %s)", g, strings.Join(Groupings, ", "))
+		}
+	}
+
+	entries, err := uc.ledger.Entries()
+	if err != nil {
+		return err
+	}
+	entries = uc.since(entries, days)
+
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No usage recorded.")
+		return nil
+	}
+
+	var total Summary
+	for _, e := range entries {
+		total.add(e)
+	}
+	fmt.Fprintf(w, "Total: %s across %d call(s)", llm.FormatCost(total.CostUSD), total.Calls)
+	if total.Failed > 0 {
+		fmt.Fprintf(w, " (%d failed)", total.Failed)
+	}
+	fmt.Fprintf(w, " - %s in / %s out / %s cache tokens\n",
+		llm.FormatTokens(total.InputTokens), llm.FormatTokens(total.OutputTokens), llm.FormatTokens(total.CacheTokens))
+
+	for _, g := range groupings {
+		fmt.Fprintln(w)
+		if err := writeTable(w, g, Summarize(entries, keyFunc(g))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summarize groups entries by key in order of first appearance. Entries
+// with an empty key are grouped under "-".
+func Summarize(entries []usage.Entry, key func(usage.Entry) string) []Summary {
+	byKey := map[string]*Summary{}
+	var order []string
+	for _, e := range entries {
+		k := key(e)
+		if k == "" {
+			k = "-"
+		}
+		s, ok := byKey[k]
+		if !ok {
+			s = &Summary{Key: k}
+			byKey[k] = s
+			order = append(order, k)
+		}
+		s.add(e)
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, k := range order {
+		summaries = append(summaries, *byKey[k])
+	}
+	return summaries
+}
+
+// keyFunc returns the grouping key of an entry, or nil for an unknown grouping
+func keyFunc(grouping string) func(usage.Entry) string {
+	switch grouping {
+	case BySession:
+		return func(e usage.Entry) string { return e.Session }
+	case ByDay:
+		return usage.Entry.Day
+	case ByFeature:
+		return func(e usage.Entry) string { return e.Feature }
+	}
+	return nil
+}
+
+// since keeps entries from the last days calendar days
+func (uc *UseCase) since(entries []usage.Entry, days int) []usage.Entry {
+	if days <= 0 {
+		return entries
+	}
+
+	now := uc.clock.Now().Local()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(days - 1))
+
+	var kept []usage.Entry
+	for _, e := range entries {
+		t, err := time.Parse(time.RFC3339, e.Time)
+		if err != nil || t.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// writeTable writes one grouping's summaries. Days sort chronologically,
+// other groupings by cost, highest first.
+func writeTable(w io.Writer, grouping string, summaries []Summary) error {
+	if grouping == ByDay {
+		sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Key < summaries[j].Key })
+	} else {
+		sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].CostUSD > summaries[j].CostUSD })
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCALLS\tFAILED\tINPUT\tOUTPUT\tCACHE\tCOST\n", strings.ToUpper(grouping))
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			s.Key,
+			s.Calls,
+			s.Failed,
+			llm.FormatTokens(s.InputTokens),
+			llm.FormatTokens(s.OutputTokens),
+			llm.FormatTokens(s.CacheTokens),
+			llm.FormatCost(s.CostUSD),
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,102 @@
+package usagereport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"claudex/internal/services/usage"
+	"claudex/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUseCase wires the usecase to an in-memory ledger seeded with entries
+func newTestUseCase(t *testing.T, entries ...usage.Entry) *UseCase {
+	h := testutil.NewTestHarness()
+	h.FixedTime = time.Date(2025, 1, 15, 12, 0, 0, 0, time.Local)
+	ledger := usage.New(h.FS, "/project/.claudex/usage.jsonl")
+	for _, e := range entries {
+		require.NoError(t, ledger.Append(e))
+	}
+	return New(ledger, h)
+}
+
+// localTime formats a local wall-clock time as a ledger timestamp
+func localTime(day, hour int) string {
+	return time.Date(2025, 1, day, hour, 0, 0, 0, time.Local).UTC().Format(time.RFC3339)
+}
+
+func TestReport_Empty(t *testing.T) {
+	uc := newTestUseCase(t)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Report(&out, nil, 0))
+	assert.Equal(t, "No usage recorded.\n", out.String())
+}
+
+func TestReport_GroupsByFeatureDayAndSession(t *testing.T) {
+	uc := newTestUseCase(t,
+		usage.Entry{Time: localTime(14, 9), Feature: "session-overview", Session: "auth-1", InputTokens: 1000, OutputTokens: 200, CostUSD: 0.10},
+		usage.Entry{Time: localTime(15, 9), Feature: "session-overview", Session: "auth-1", InputTokens: 2000, OutputTokens: 100, CostUSD: 0.20},
+		usage.Entry{Time: localTime(15, 10), Feature: "index-update", InputTokens: 500, CostUSD: 0.05, Error: "exit status 1"},
+	)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Report(&out, nil, 0))
+	report := out.String()
+
+	assert.Contains(t, report, "Total: $0.35 across 3 call(s) (1 failed)")
+	assert.Contains(t, report, "3.5k in / 300 out")
+
+	lines := strings.Split(report, "\n")
+	assertRow(t, lines, "session-overview", "2", "$0.30")
+	assertRow(t, lines, "index-update", "1", "$0.05")
+	assertRow(t, lines, "2025-01-14", "1", "$0.10")
+	assertRow(t, lines, "2025-01-15", "2", "$0.25")
+	assertRow(t, lines, "auth-1", "2", "$0.30")
+	assertRow(t, lines, "-", "1", "$0.05")
+
+	// Feature table lists the most expensive feature first
+	assert.Less(t, strings.Index(report, "session-overview"), strings.Index(report, "index-update"))
+}
+
+func TestReport_ByAndDays(t *testing.T) {
+	uc := newTestUseCase(t,
+		usage.Entry{Time: localTime(10, 9), Feature: "index-update", CostUSD: 1.00},
+		usage.Entry{Time: localTime(15, 9), Feature: "session-name", CostUSD: 0.001},
+	)
+
+	var out bytes.Buffer
+	require.NoError(t, uc.Report(&out, []string{ByDay}, 1))
+	report := out.String()
+
+	assert.Contains(t, report, "Total: $0.0010 across 1 call(s)")
+	assert.Contains(t, report, "2025-01-15")
+	assert.NotContains(t, report, "2025-01-10")
+	assert.NotContains(t, report, "FEATURE")
+}
+
+func TestReport_UnknownGrouping(t *testing.T) {
+	uc := newTestUseCase(t)
+
+	err := uc.Report(&bytes.Buffer{}, []string{"model"}, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown grouping "model"`)
+}
+
+// assertRow checks a table row starting with key has the given calls and cost
+func assertRow(t *testing.T, lines []string, key, calls, cost string) {
+	t.Helper()
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == key {
+			assert.Equal(t, calls, fields[1], "calls for %s", key)
+			assert.Equal(t, cost, fields[len(fields)-1], "cost for %s", key)
+			return
+		}
+	}
+	t.Errorf("no row for %s in:\n%s", key, strings.Join(lines, "\n"))
+}